@@ -0,0 +1,41 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfAddCommand = "BF.ADD"
+
+func handleBfAdd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	added, err := bfAddAux(sa[1], sa[2:], ctx)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(added[0]), nil
+}
+
+// bfAddAux adds items to a Bloom filter for BF.ADD and BF.MADD. The command is propagated if any item was added, even if a later item failed,
+// in which case it fails at the same item on replicas.
+func bfAddAux(key string, items []string, ctx Context) ([]bool, error) {
+	var added []bool
+	var addErr error
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		added, addErr = state.BfAdd(key, items)
+		for _, a := range added {
+			if a {
+				return []resp.RESP{ctx.Com}, nil
+			}
+		}
+		return nil, nil
+	}); err != nil {
+		return nil, err
+	}
+	return added, addErr
+}
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfExistsCommand = "BF.EXISTS"
+
+func handleBfExists(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	exists, err := state.BfExists(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(exists[0]), nil
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfInfoCommand = "BF.INFO"
+
+func handleBfInfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	info, err := state.BfInfo(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	var expansion resp.RESP = resp.RESPInteger{Value: info.Expansion}
+	if info.Expansion == 0 {
+		expansion = resp.NullLit
+	}
+	fields := []struct {
+		opt, name string
+		value     resp.RESP
+	}{
+		{"CAPACITY", "Capacity", resp.RESPInteger{Value: info.Capacity}},
+		{"SIZE", "Size", resp.RESPInteger{Value: info.Size}},
+		{"FILTERS", "Number of filters", resp.RESPInteger{Value: info.Filters}},
+		{"ITEMS", "Number of items inserted", resp.RESPInteger{Value: info.Items}},
+		{"EXPANSION", "Expansion rate", expansion},
+	}
+	if len(sa) == 3 {
+		for _, f := range fields {
+			if strings.ToUpper(sa[2]) == f.opt {
+				return &resp.RESPArray{Value: []resp.RESP{f.value}}, nil
+			}
+		}
+		return &resp.RESPSimpleError{Value: "ERR Invalid information value"}, nil
+	}
+	av := make([]resp.RESP, 0, 2*len(fields))
+	for _, f := range fields {
+		av = append(av, &resp.RESPSimpleString{Value: f.name}, f.value)
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
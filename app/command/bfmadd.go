@@ -0,0 +1,32 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfMaddCommand = "BF.MADD"
+
+// handleBfMadd replies with an error for each item from the first that could not be added
+func handleBfMadd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	items := sa[2:]
+	added, err := bfAddAux(sa[1], items, ctx)
+	if err == state.ErrorWrongType {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(items))
+	for i := range av {
+		if i < len(added) {
+			av[i] = encodeBool(added[i])
+		} else {
+			av[i] = &resp.RESPSimpleError{Value: err.Error()}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfMexistsCommand = "BF.MEXISTS"
+
+func handleBfMexists(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	exists, err := state.BfExists(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(exists))
+	for i, e := range exists {
+		av[i] = encodeBool(e)
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,66 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bfReserveCommand = "BF.RESERVE"
+
+func handleBfReserve(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	errorRate, err := strconv.ParseFloat(sa[2], 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: "ERR bad error rate"}, nil
+	}
+	if !(errorRate > 0 && errorRate < 1) {
+		return &resp.RESPSimpleError{Value: "ERR (0 < error rate range < 1)"}, nil
+	}
+	capacity, err := strconv.ParseInt(sa[3], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: "ERR bad capacity"}, nil
+	}
+	if capacity <= 0 {
+		return &resp.RESPSimpleError{Value: "ERR (capacity should be larger than 0)"}, nil
+	}
+	expansion := int64(state.BloomDefaultExpansion)
+	hasExpansion, nonScaling := false, false
+	for i := 4; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "EXPANSION":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			expansion, err = strconv.ParseInt(sa[i+1], 10, 64)
+			if err != nil {
+				return &resp.RESPSimpleError{Value: "ERR bad expansion"}, nil
+			}
+			if expansion < 1 {
+				return &resp.RESPSimpleError{Value: "ERR expansion should be greater or equal to 1"}, nil
+			}
+			hasExpansion = true
+			i++
+		case "NONSCALING":
+			nonScaling = true
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if hasExpansion && nonScaling {
+		return &resp.RESPSimpleError{Value: "ERR Nonscaling filters cannot expand"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.BfReserve(sa[1], errorRate, capacity, expansion, nonScaling)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
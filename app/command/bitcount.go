@@ -0,0 +1,54 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bitcountCommand = "BITCOUNT"
+
+func handleBitcount(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 4 && len(sa) != 5 {
+		return ErrorSyntax, nil
+	}
+	var r *state.BitRange
+	if len(sa) > 2 {
+		br, errRes := parseBitRange(sa[2:])
+		if errRes != nil {
+			return errRes, nil
+		}
+		r = &br
+	}
+	n, err := state.Bitcount(sa[1], r)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
+
+// parseBitRange parses start end [BYTE | BIT], with end defaulting to -1 if absent
+func parseBitRange(args []string) (state.BitRange, resp.RESP) {
+	r := state.BitRange{End: -1}
+	var err error
+	if r.Start, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+		return r, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+	}
+	if len(args) > 1 {
+		if r.End, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return r, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+		}
+	}
+	if len(args) > 2 {
+		switch strings.ToUpper(args[2]) {
+		case "BYTE":
+		case "BIT":
+			r.Bit = true
+		default:
+			return r, ErrorSyntax
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bitfieldCommand = "BITFIELD"
+
+func handleBitfield(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	key := sa[1]
+	ops, errRes := parseBitfieldOps(sa[2:], false)
+	if errRes != nil {
+		return errRes, nil
+	}
+	readOnly := true
+	for _, op := range ops {
+		readOnly = readOnly && op.Kind == state.BitfieldGet
+	}
+	if !readOnly && ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var res []*int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var writes []state.BitfieldOp
+		res, writes, err = state.Bitfield(key, ops)
+		if len(writes) == 0 {
+			return nil, err
+		}
+		if ctx.IsReplConn {
+			// the master has already propagated the writes in their final form
+			return []resp.RESP{ctx.Com}, err
+		}
+		// only the writes are propagated, as SETs of the values written, so that replicas need not repeat reads or overflow handling
+		com := []string{bitfieldCommand, key}
+		for _, op := range writes {
+			com = append(com, "SET", encodeBitfieldType(op), strconv.FormatInt(op.Offset, 10), strconv.FormatInt(op.Value, 10))
+		}
+		return []resp.RESP{resp.EncodeStringSlice(com)}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(res))
+	for i, v := range res {
+		if v == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = resp.RESPInteger{Value: *v}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
+
+// parseBitfieldOps parses the subcommands of BITFIELD, only accepting GET if readOnly is set
+func parseBitfieldOps(args []string, readOnly bool) ([]state.BitfieldOp, resp.RESP) {
+	var ops []state.BitfieldOp
+	overflow := state.BitfieldWrap
+	for i := 0; i < len(args); i++ {
+		sub := strings.ToUpper(args[i])
+		if readOnly && sub != "GET" {
+			return nil, &resp.RESPSimpleError{Value: "ERR BITFIELD_RO only supports the GET subcommand"}
+		}
+		var op state.BitfieldOp
+		switch sub {
+		case "OVERFLOW":
+			if i+1 >= len(args) {
+				return nil, ErrorSyntax
+			}
+			switch strings.ToUpper(args[i+1]) {
+			case "WRAP":
+				overflow = state.BitfieldWrap
+			case "SAT":
+				overflow = state.BitfieldSat
+			case "FAIL":
+				overflow = state.BitfieldFail
+			default:
+				return nil, &resp.RESPSimpleError{Value: "ERR Invalid OVERFLOW type specified"}
+			}
+			i++
+			continue
+		case "GET":
+			op.Kind = state.BitfieldGet
+		case "SET":
+			op.Kind = state.BitfieldSet
+		case "INCRBY":
+			op.Kind = state.BitfieldIncrby
+		default:
+			return nil, ErrorSyntax
+		}
+		n := 3
+		if op.Kind == state.BitfieldGet {
+			n = 2
+		}
+		if i+n >= len(args) {
+			return nil, ErrorSyntax
+		}
+		if errRes := parseBitfieldField(&op, args[i+1], args[i+2]); errRes != nil {
+			return nil, errRes
+		}
+		if op.Kind != state.BitfieldGet {
+			v, err := strconv.ParseInt(args[i+3], 10, 64)
+			if err != nil {
+				return nil, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+			}
+			op.Value = v
+		}
+		op.Overflow = overflow
+		ops = append(ops, op)
+		i += n
+	}
+	return ops, nil
+}
+
+// parseBitfieldField parses the type (e.g. i8 or u16) and offset (in bits, or in multiples of the width if prefixed with #) of a field into op
+func parseBitfieldField(op *state.BitfieldOp, typ, offset string) resp.RESP {
+	width, err := strconv.Atoi(typ[min(1, len(typ)):])
+	op.Signed = strings.HasPrefix(typ, "i") || strings.HasPrefix(typ, "I")
+	unsigned := strings.HasPrefix(typ, "u") || strings.HasPrefix(typ, "U")
+	if err != nil || (!op.Signed && !unsigned) || width < 1 || (op.Signed && width > 64) || (unsigned && width > 63) {
+		return &resp.RESPSimpleError{Value: "ERR Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."}
+	}
+	op.Width = width
+	multiplied := strings.HasPrefix(offset, "#")
+	if multiplied {
+		offset = offset[1:]
+	}
+	o, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil || o < 0 || (multiplied && o > state.BitMaxOffset/int64(width)) {
+		return &resp.RESPSimpleError{Value: "ERR bit offset is not an integer or out of range"}
+	}
+	if multiplied {
+		o *= int64(width)
+	}
+	if o+int64(width)-1 > state.BitMaxOffset {
+		return &resp.RESPSimpleError{Value: "ERR bit offset is not an integer or out of range"}
+	}
+	op.Offset = o
+	return nil
+}
+
+func encodeBitfieldType(op state.BitfieldOp) string {
+	if op.Signed {
+		return "i" + strconv.Itoa(op.Width)
+	}
+	return "u" + strconv.Itoa(op.Width)
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bitfieldRoCommand = "BITFIELD_RO"
+
+func handleBitfieldRo(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	ops, errRes := parseBitfieldOps(sa[2:], true)
+	if errRes != nil {
+		return errRes, nil
+	}
+	res, _, err := state.Bitfield(sa[1], ops)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(res))
+	for i, v := range res {
+		av[i] = resp.RESPInteger{Value: *v}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
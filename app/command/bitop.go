@@ -0,0 +1,45 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bitopCommand = "BITOP"
+
+func handleBitop(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	var op state.BitOp
+	switch strings.ToUpper(sa[1]) {
+	case "AND":
+		op = state.BitOpAnd
+	case "OR":
+		op = state.BitOpOr
+	case "XOR":
+		op = state.BitOpXor
+	case "NOT":
+		op = state.BitOpNot
+	default:
+		return ErrorSyntax, nil
+	}
+	dst, keys := sa[2], sa[3:]
+	if op == state.BitOpNot && len(keys) != 1 {
+		return &resp.RESPSimpleError{Value: "ERR BITOP NOT must be called with a single source key."}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.Bitop(op, dst, keys)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bitposCommand = "BITPOS"
+
+func handleBitpos(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 || len(sa) > 6 {
+		return ErrorSyntax, nil
+	}
+	if sa[2] != "0" && sa[2] != "1" {
+		return &resp.RESPSimpleError{Value: "ERR The bit argument must be 1 or 0."}, nil
+	}
+	bit := sa[2][0] - '0'
+	r := state.BitRange{Start: 0, End: -1}
+	if len(sa) > 3 {
+		var errRes resp.RESP
+		if r, errRes = parseBitRange(sa[3:]); errRes != nil {
+			return errRes, nil
+		}
+	}
+	pos, err := state.Bitpos(sa[1], bit, r, len(sa) > 4)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: pos}, nil
+}
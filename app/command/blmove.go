@@ -0,0 +1,38 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var blmoveCommand = "BLMOVE"
+
+func handleBlmove(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 6 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 6-element array"}, nil
+	}
+	src, dst := sa[1], sa[2]
+	srcFront, ok := parseListEnd(sa[3])
+	if !ok {
+		return ErrorSyntax, nil
+	}
+	dstFront, ok := parseListEnd(sa[4])
+	if !ok {
+		return ErrorSyntax, nil
+	}
+	timeout, errRes := parseBlockTimeout(sa[5])
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	res, err := state.Blmove(src, dst, srcFront, dstFront, timeout, !ctx.InTransaction)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if res == nil {
+		return resp.NullLit, nil
+	}
+	return res, nil
+}
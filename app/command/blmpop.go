@@ -0,0 +1,18 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var blmpopCommand = "BLMPOP"
+
+func handleBlmpop(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	timeout, errRes := parseBlockTimeout(sa[1])
+	if errRes != nil {
+		return errRes, nil
+	}
+	return handleLmpopAux(sa[2:], ctx, timeout, true)
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var blpopCommand = "BLPOP"
+
+func handleBlpop(sa []string, ctx Context) (resp.RESP, error) {
+	return handleBpopAux(sa, ctx, true)
+}
+
+// handleBpopAux handles the BLPOP and BRPOP commands
+func handleBpopAux(sa []string, ctx Context, front bool) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	keys := sa[1 : len(sa)-1]
+	timeout, errRes := parseBlockTimeout(sa[len(sa)-1])
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	// blocking commands never block inside a transaction, since doing so would stall the entire server
+	res, err := state.Blpop(keys, front, timeout, !ctx.InTransaction)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if res == nil {
+		return resp.NullArrayLit, nil
+	}
+	return res, nil
+}
+
+// parseBlockTimeout parses a timeout of a blocking command given in (possibly fractional) seconds, with 0 meaning to block indefinitely
+func parseBlockTimeout(s string) (time.Duration, resp.RESP) {
+	secs, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(secs) || math.IsInf(secs, 0) {
+		return 0, &resp.RESPSimpleError{Value: "ERR timeout is not a float or out of range"}
+	}
+	if secs < 0 {
+		return 0, &resp.RESPSimpleError{Value: "ERR timeout is negative"}
+	}
+	timeout := time.Duration(secs * float64(time.Second))
+	if secs > 0 && timeout < time.Millisecond {
+		// a positive timeout too small to be represented would otherwise be taken to mean blocking indefinitely
+		timeout = time.Millisecond
+	}
+	return timeout, nil
+}
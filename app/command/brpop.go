@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var brpopCommand = "BRPOP"
+
+func handleBrpop(sa []string, ctx Context) (resp.RESP, error) {
+	return handleBpopAux(sa, ctx, false)
+}
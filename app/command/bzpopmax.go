@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var bzpopmaxCommand = "BZPOPMAX"
+
+func handleBzpopmax(sa []string, ctx Context) (resp.RESP, error) {
+	return handleBzpopAux(sa, ctx, true)
+}
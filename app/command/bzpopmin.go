@@ -0,0 +1,42 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var bzpopminCommand = "BZPOPMIN"
+
+func handleBzpopmin(sa []string, ctx Context) (resp.RESP, error) {
+	return handleBzpopAux(sa, ctx, false)
+}
+
+// handleBzpopAux handles the BZPOPMIN and BZPOPMAX commands
+func handleBzpopAux(sa []string, ctx Context, max bool) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	keys := sa[1 : len(sa)-1]
+	timeout, errRes := parseBlockTimeout(sa[len(sa)-1])
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	// blocking commands never block inside a transaction, since doing so would stall the entire server
+	res, err := state.Bzmpop(keys, max, 1, timeout, !ctx.InTransaction, func(key string, popped []state.ZMember) resp.RESP {
+		return &resp.RESPArray{Value: []resp.RESP{
+			&resp.RESPBulkString{Value: key},
+			&resp.RESPBulkString{Value: popped[0].Member},
+			encodeScore(popped[0].Score, ctx),
+		}}
+	})
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if res == nil {
+		return resp.NullArrayLit, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cfAddCommand = "CF.ADD"
+
+func handleCfAdd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.CfAdd(sa[1], sa[2])
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: 1}, nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cfDelCommand = "CF.DEL"
+
+func handleCfDel(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var deleted bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		deleted, err = state.CfDel(sa[1], sa[2])
+		if !deleted {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(deleted), nil
+}
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cfExistsCommand = "CF.EXISTS"
+
+func handleCfExists(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	n, err := state.CfCount(sa[1], sa[2])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(n > 0), nil
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cfReserveCommand = "CF.RESERVE"
+
+func handleCfReserve(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	capacity, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil || capacity <= 0 {
+		return &resp.RESPSimpleError{Value: "ERR Bad capacity"}, nil
+	}
+	bucketSize, maxIterations, expansion := state.CuckooDefaultBucketSize, state.CuckooDefaultMaxIterations, state.CuckooDefaultExpansion
+	for i := 3; i < len(sa); i++ {
+		if i+1 >= len(sa) {
+			return ErrorSyntax, nil
+		}
+		n, err := strconv.Atoi(sa[i+1])
+		switch strings.ToUpper(sa[i]) {
+		case "BUCKETSIZE":
+			if err != nil || n < 1 || n > 255 {
+				return &resp.RESPSimpleError{Value: "ERR Bad bucket size"}, nil
+			}
+			bucketSize = n
+		case "MAXITERATIONS":
+			if err != nil || n < 1 || n > 65535 {
+				return &resp.RESPSimpleError{Value: "ERR Bad max iterations"}, nil
+			}
+			maxIterations = n
+		case "EXPANSION":
+			if err != nil || n < 0 || n > 32768 {
+				return &resp.RESPSimpleError{Value: "ERR Bad expansion"}, nil
+			}
+			expansion = n
+		default:
+			return ErrorSyntax, nil
+		}
+		i++
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.CfReserve(sa[1], capacity, bucketSize, maxIterations, expansion)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
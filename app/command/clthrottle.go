@@ -0,0 +1,63 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var clThrottleCommand = "CL.THROTTLE"
+
+// handleClThrottle rate limits a request, as with CL.THROTTLE key max_burst count period [quantity], allowing count units
+// every period seconds with bursts of up to max_burst+1 units. It replies with whether the request was limited, the burst limit,
+// the remaining units, and the seconds until the request could be retried (-1 if allowed) and until the limiter resets.
+// An allowed request is replicated as a SET of the new state of the limiter with its absolute expiry.
+func handleClThrottle(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 5 && len(sa) != 6 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 5 or 6-element array"}, nil
+	}
+	args := []int64{0, 0, 0, 1}
+	for i, s := range sa[2:] {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || n < 0 || (i == 1 || i == 2) && n == 0 {
+			return &resp.RESPSimpleError{Value: "ERR invalid " + []string{"max_burst", "count", "period", "quantity"}[i]}, nil
+		}
+		args[i] = n
+	}
+	// the burst limit is max_burst+1, and the period is converted to nanoseconds
+	if args[0] == math.MaxInt64 || args[2] > math.MaxInt64/int64(time.Second) {
+		return ErrorOutOfRange, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var res state.ThrottleResult
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		if res, err = state.Throttle(sa[1], args[0], args[1], args[2], args[3]); err != nil || !res.Written {
+			return nil, err
+		}
+		pxat := strconv.FormatInt(res.ExpiresAt.UnixMilli(), 10)
+		return []resp.RESP{resp.EncodeStringSlice([]string{"SET", sa[1], res.Value, "PXAT", pxat})}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	retryAfter := int64(-1)
+	if res.RetryAfter >= 0 {
+		retryAfter = ceilSeconds(res.RetryAfter)
+	}
+	return &resp.RESPArray{Value: []resp.RESP{
+		encodeBool(res.Limited),
+		resp.RESPInteger{Value: res.Limit},
+		resp.RESPInteger{Value: res.Remaining},
+		resp.RESPInteger{Value: retryAfter},
+		resp.RESPInteger{Value: ceilSeconds(res.ResetAfter)},
+	}}, nil
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
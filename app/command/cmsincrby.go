@@ -0,0 +1,39 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cmsIncrbyCommand = "CMS.INCRBY"
+
+func handleCmsIncrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 || len(sa)%2 != 0 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected an even, at least 4-element array"}, nil
+	}
+	n := (len(sa) - 2) / 2
+	items := make([]string, n)
+	increments := make([]int64, n)
+	for i := range n {
+		items[i] = sa[2+2*i]
+		incr, err := strconv.ParseInt(sa[3+2*i], 10, 64)
+		if err != nil || incr < 0 {
+			return &resp.RESPSimpleError{Value: "CMS: Cannot parse number"}, nil
+		}
+		increments[i] = incr
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var counts []int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		counts, err = state.CmsIncrby(sa[1], items, increments)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeIntegerSlice(counts), nil
+}
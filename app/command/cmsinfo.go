@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cmsInfoCommand = "CMS.INFO"
+
+func handleCmsInfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	info, err := state.CmsInfo(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := []resp.RESP{
+		&resp.RESPSimpleString{Value: "width"}, resp.RESPInteger{Value: info.Width},
+		&resp.RESPSimpleString{Value: "depth"}, resp.RESPInteger{Value: info.Depth},
+		&resp.RESPSimpleString{Value: "count"}, resp.RESPInteger{Value: info.Count},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
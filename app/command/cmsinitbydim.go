@@ -0,0 +1,38 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cmsInitbydimCommand = "CMS.INITBYDIM"
+
+func handleCmsInitbydim(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	width, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil || width < 1 {
+		return &resp.RESPSimpleError{Value: "CMS: invalid width"}, nil
+	}
+	depth, err := strconv.ParseInt(sa[3], 10, 64)
+	if err != nil || depth < 1 {
+		return &resp.RESPSimpleError{Value: "CMS: invalid depth"}, nil
+	}
+	return cmsInitAux(sa[1], width, depth, ctx)
+}
+
+// cmsInitAux creates a sketch for CMS.INITBYDIM and CMS.INITBYPROB
+func cmsInitAux(key string, width, depth int64, ctx Context) (resp.RESP, error) {
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.CmsInit(key, width, depth)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
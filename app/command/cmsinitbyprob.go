@@ -0,0 +1,29 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var cmsInitbyprobCommand = "CMS.INITBYPROB"
+
+// handleCmsInitbyprob sizes the sketch so that estimates exceed counts by at most error times the total count
+// with at most the given probability
+func handleCmsInitbyprob(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	errorRate, err := strconv.ParseFloat(sa[2], 64)
+	if err != nil || !(errorRate > 0 && errorRate < 1) {
+		return &resp.RESPSimpleError{Value: "CMS: invalid overestimation value"}, nil
+	}
+	prob, err := strconv.ParseFloat(sa[3], 64)
+	if err != nil || !(prob > 0 && prob < 1) {
+		return &resp.RESPSimpleError{Value: "CMS: invalid prob value"}, nil
+	}
+	width := int64(math.Ceil(2 / errorRate))
+	depth := int64(math.Ceil(math.Log(prob) / math.Log(0.5)))
+	return cmsInitAux(sa[1], width, depth, ctx)
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cmsMergeCommand = "CMS.MERGE"
+
+func handleCmsMerge(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	numKeys, err := strconv.Atoi(sa[2])
+	if err != nil || numKeys <= 0 || numKeys > len(sa)-3 {
+		return &resp.RESPSimpleError{Value: "CMS: invalid numkeys"}, nil
+	}
+	sources := sa[3 : 3+numKeys]
+	weights := make([]int64, numKeys)
+	for i := range weights {
+		weights[i] = 1
+	}
+	if rest := sa[3+numKeys:]; len(rest) > 0 {
+		if strings.ToUpper(rest[0]) != "WEIGHTS" {
+			return ErrorSyntax, nil
+		}
+		if len(rest) != 1+numKeys {
+			return &resp.RESPSimpleError{Value: "CMS: wrong number of keys/weights"}, nil
+		}
+		for i, s := range rest[1:] {
+			// weights are bounded so that weighted counters cannot overflow
+			w, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || w < math.MinInt32 || w > math.MaxInt32 {
+				return &resp.RESPSimpleError{Value: "CMS: invalid weight value"}, nil
+			}
+			weights[i] = w
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.CmsMerge(sa[1], sources, weights)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
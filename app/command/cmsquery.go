@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var cmsQueryCommand = "CMS.QUERY"
+
+func handleCmsQuery(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	counts, err := state.CmsQuery(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeIntegerSlice(counts), nil
+}
@@ -35,8 +35,275 @@ var subhandlerMap = map[string]subhandler{
 	psyncCommand:    handlePsync,
 	replconfCommand: handleReplconf,
 	discardCommand:  handleDiscard,
+	helloCommand:    handleHello,
+	sortCommand:     handleSort,
+	sortRoCommand:   handleSortRo,
+
+	// keyspace commands
+	delCommand:         handleDel,
+	unlinkCommand:      handleDel,
+	existsCommand:      handleExists,
+	touchCommand:       handleTouch,
+	renameCommand:      handleRename,
+	renamenxCommand:    handleRenamenx,
+	copyCommand:        handleCopy,
+	randomkeyCommand:   handleRandomkey,
+	dbsizeCommand:      handleDbsize,
+	flushdbCommand:     handleFlushall,
+	flushallCommand:    handleFlushall,
+	expireCommand:      handleExpire,
+	pexpireCommand:     handlePexpire,
+	expireatCommand:    handleExpireat,
+	pexpireatCommand:   handlePexpireat,
+	ttlCommand:         handleTtl,
+	pttlCommand:        handlePttl,
+	expiretimeCommand:  handleExpiretime,
+	pexpiretimeCommand: handlePexpiretime,
+	persistCommand:     handlePersist,
+	scanCommand:        handleScan,
+
+	// string commands
+	appendCommand:      handleAppend,
+	strlenCommand:      handleStrlen,
+	getrangeCommand:    handleGetrange,
+	setrangeCommand:    handleSetrange,
+	mgetCommand:        handleMget,
+	msetCommand:        handleMset,
+	msetnxCommand:      handleMsetnx,
+	getdelCommand:      handleGetdel,
+	getexCommand:       handleGetex,
+	getsetCommand:      handleGetset,
+	incrbyCommand:      handleIncrby,
+	decrCommand:        handleDecr,
+	decrbyCommand:      handleDecrby,
+	incrbyfloatCommand: handleIncrbyfloat,
+	setnxCommand:       handleSetnx,
+	setexCommand:       handleSetex,
+	psetexCommand:      handlePsetex,
+	lcsCommand:         handleLcs,
+
+	// list commands
+	lpushCommand:   handleLpush,
+	lpushxCommand:  handleLpushx,
+	rpushCommand:   handleRpush,
+	rpushxCommand:  handleRpushx,
+	lpopCommand:    handleLpop,
+	rpopCommand:    handleRpop,
+	lrangeCommand:  handleLrange,
+	lindexCommand:  handleLindex,
+	lsetCommand:    handleLset,
+	linsertCommand: handleLinsert,
+	lremCommand:    handleLrem,
+	ltrimCommand:   handleLtrim,
+	llenCommand:    handleLlen,
+	lposCommand:    handleLpos,
+	lmoveCommand:   handleLmove,
+	lmpopCommand:   handleLmpop,
+	blpopCommand:   handleBlpop,
+	brpopCommand:   handleBrpop,
+	blmoveCommand:  handleBlmove,
+	blmpopCommand:  handleBlmpop,
+
+	// hash commands
+	hsetCommand:         handleHset,
+	hmsetCommand:        handleHmset,
+	hsetnxCommand:       handleHsetnx,
+	hgetCommand:         handleHget,
+	hmgetCommand:        handleHmget,
+	hdelCommand:         handleHdel,
+	hgetallCommand:      handleHgetall,
+	hkeysCommand:        handleHkeys,
+	hvalsCommand:        handleHvals,
+	hincrbyCommand:      handleHincrby,
+	hexistsCommand:      handleHexists,
+	hlenCommand:         handleHlen,
+	hstrlenCommand:      handleHstrlen,
+	hincrbyfloatCommand: handleHincrbyfloat,
+	hrandfieldCommand:   handleHrandfield,
+	hexpireCommand:      handleHexpire,
+	hpexpireCommand:     handleHpexpire,
+	hexpireatCommand:    handleHexpireat,
+	hpexpireatCommand:   handleHpexpireat,
+	httlCommand:         handleHttl,
+	hpttlCommand:        handleHpttl,
+	hexpiretimeCommand:  handleHexpiretime,
+	hpexpiretimeCommand: handleHpexpiretime,
+	hpersistCommand:     handleHpersist,
+	hgetexCommand:       handleHgetex,
+	hsetexCommand:       handleHsetex,
+	hscanCommand:        handleHscan,
+
+	// set commands
+	saddCommand:        handleSadd,
+	sremCommand:        handleSrem,
+	smembersCommand:    handleSmembers,
+	sismemberCommand:   handleSismember,
+	smismemberCommand:  handleSmismember,
+	scardCommand:       handleScard,
+	sinterCommand:      handleSinter,
+	sunionCommand:      handleSunion,
+	sdiffCommand:       handleSdiff,
+	sinterstoreCommand: handleSinterstore,
+	sunionstoreCommand: handleSunionstore,
+	sdiffstoreCommand:  handleSdiffstore,
+	sintercardCommand:  handleSintercard,
+	srandmemberCommand: handleSrandmember,
+	spopCommand:        handleSpop,
+	smoveCommand:       handleSmove,
+	sscanCommand:       handleSscan,
+
+	// sorted set commands
+	zaddCommand:             handleZadd,
+	zincrbyCommand:          handleZincrby,
+	zremCommand:             handleZrem,
+	zcardCommand:            handleZcard,
+	zscoreCommand:           handleZscore,
+	zmscoreCommand:          handleZmscore,
+	zrankCommand:            handleZrank,
+	zrevrankCommand:         handleZrevrank,
+	zrangeCommand:           handleZrange,
+	zrevrangeCommand:        handleZrevrange,
+	zrangebyscoreCommand:    handleZrangebyscore,
+	zrevrangebyscoreCommand: handleZrevrangebyscore,
+	zrangebylexCommand:      handleZrangebylex,
+	zrevrangebylexCommand:   handleZrevrangebylex,
+	zrangestoreCommand:      handleZrangestore,
+	zcountCommand:           handleZcount,
+	zlexcountCommand:        handleZlexcount,
+	zremrangebyrankCommand:  handleZremrangebyrank,
+	zremrangebyscoreCommand: handleZremrangebyscore,
+	zremrangebylexCommand:   handleZremrangebylex,
+	zrandmemberCommand:      handleZrandmember,
+	zunionCommand:           handleZunion,
+	zinterCommand:           handleZinter,
+	zdiffCommand:            handleZdiff,
+	zunionstoreCommand:      handleZunionstore,
+	zinterstoreCommand:      handleZinterstore,
+	zdiffstoreCommand:       handleZdiffstore,
+	zintercardCommand:       handleZintercard,
+	zpopminCommand:          handleZpopmin,
+	zpopmaxCommand:          handleZpopmax,
+	zmpopCommand:            handleZmpop,
+	bzpopminCommand:         handleBzpopmin,
+	bzpopmaxCommand:         handleBzpopmax,
+	bzmpopCommand:           handleBzmpop,
+	zscanCommand:            handleZscan,
+
+	// bitmap commands
+	setbitCommand:     handleSetbit,
+	getbitCommand:     handleGetbit,
+	bitcountCommand:   handleBitcount,
+	bitposCommand:     handleBitpos,
+	bitopCommand:      handleBitop,
+	bitfieldCommand:   handleBitfield,
+	bitfieldRoCommand: handleBitfieldRo,
+
+	// hyperloglog commands
+	pfaddCommand:   handlePfadd,
+	pfcountCommand: handlePfcount,
+	pfmergeCommand: handlePfmerge,
+	pfdebugCommand: handlePfdebugCommands,
+
+	// geospatial commands
+	geoaddCommand:         handleGeoadd,
+	geoposCommand:         handleGeopos,
+	geodistCommand:        handleGeodist,
+	geohashCommand:        handleGeohash,
+	geosearchCommand:      handleGeosearch,
+	geosearchstoreCommand: handleGeosearchstore,
+
+	// JSON commands
+	jsonSetCommand:       handleJsonSet,
+	jsonGetCommand:       handleJsonGet,
+	jsonDelCommand:       handleJsonDel,
+	jsonTypeCommand:      handleJsonType,
+	jsonArrappendCommand: handleJsonArrappend,
+	jsonNumincrbyCommand: handleJsonNumincrby,
+	jsonObjkeysCommand:   handleJsonObjkeys,
+	jsonMgetCommand:      handleJsonMget,
+
+	// probabilistic filter commands
+	bfReserveCommand: handleBfReserve,
+	bfAddCommand:     handleBfAdd,
+	bfMaddCommand:    handleBfMadd,
+	bfExistsCommand:  handleBfExists,
+	bfMexistsCommand: handleBfMexists,
+	bfInfoCommand:    handleBfInfo,
+	cfReserveCommand: handleCfReserve,
+	cfAddCommand:     handleCfAdd,
+	cfDelCommand:     handleCfDel,
+	cfExistsCommand:  handleCfExists,
+	cfCountCommand:   handleCfCount,
+
+	// sketch commands
+	cmsInitbydimCommand:       handleCmsInitbydim,
+	cmsInitbyprobCommand:      handleCmsInitbyprob,
+	cmsIncrbyCommand:          handleCmsIncrby,
+	cmsQueryCommand:           handleCmsQuery,
+	cmsMergeCommand:           handleCmsMerge,
+	cmsInfoCommand:            handleCmsInfo,
+	topkReserveCommand:        handleTopkReserve,
+	topkAddCommand:            handleTopkAdd,
+	topkIncrbyCommand:         handleTopkIncrby,
+	topkQueryCommand:          handleTopkQuery,
+	topkCountCommand:          handleTopkCount,
+	topkListCommand:           handleTopkList,
+	topkInfoCommand:           handleTopkInfo,
+	tdigestCreateCommand:      handleTdigestCreate,
+	tdigestResetCommand:       handleTdigestReset,
+	tdigestAddCommand:         handleTdigestAdd,
+	tdigestMergeCommand:       handleTdigestMerge,
+	tdigestMinCommand:         handleTdigestMin,
+	tdigestMaxCommand:         handleTdigestMax,
+	tdigestQuantileCommand:    handleTdigestQuantile,
+	tdigestCdfCommand:         handleTdigestCdf,
+	tdigestRankCommand:        handleTdigestRank,
+	tdigestRevrankCommand:     handleTdigestRevrank,
+	tdigestByrankCommand:      handleTdigestByrank,
+	tdigestByrevrankCommand:   handleTdigestByrevrank,
+	tdigestTrimmedMeanCommand: handleTdigestTrimmedMean,
+	tdigestInfoCommand:        handleTdigestInfo,
+
+	// time series commands
+	tsCreateCommand:     handleTsCreate,
+	tsAddCommand:        handleTsAdd,
+	tsMaddCommand:       handleTsMadd,
+	tsGetCommand:        handleTsGet,
+	tsRangeCommand:      handleTsRange,
+	tsRevrangeCommand:   handleTsRevrange,
+	tsMrangeCommand:     handleTsMrange,
+	tsMrevrangeCommand:  handleTsMrevrange,
+	tsInfoCommand:       handleTsInfo,
+	tsCreateruleCommand: handleTsCreaterule,
+	tsDeleteruleCommand: handleTsDeleterule,
+
+	// search commands
+	ftCreateCommand:    handleFtCreate,
+	ftDropindexCommand: handleFtDropindex,
+	ftListCommand:      handleFtList,
+	ftInfoCommand:      handleFtInfo,
+	ftSearchCommand:    handleFtSearch,
+	ftAggregateCommand: handleFtAggregate,
+
+	// vector set commands
+	vaddCommand:     handleVadd,
+	vsimCommand:     handleVsim,
+	vremCommand:     handleVrem,
+	vcardCommand:    handleVcard,
+	vdimCommand:     handleVdim,
+	vembCommand:     handleVemb,
+	vsetattrCommand: handleVsetattr,
+	vgetattrCommand: handleVgetattr,
+	vinfoCommand:    handleVinfo,
+
+	// rate limiting commands
+	clThrottleCommand: handleClThrottle,
 }
 
+var ErrorSyntax = &resp.RESPSimpleError{Value: "ERR syntax error"}
+
+var ErrorOutOfRange = &resp.RESPSimpleError{Value: "ERR value is out of range"}
+
 type Context struct {
 	Reader        *respreader.BufferedRESPConnReader
 	IsReplica     bool
@@ -45,12 +312,14 @@ type Context struct {
 	Com           resp.RESP
 	Queued        *resp.ComSlice
 	InTransaction bool
+	Protocol      *int
 	Handle        func(Context) (resp.RESP, error)
 }
 
 type HandlerOptions struct {
 	Queued        *resp.ComSlice
 	InTransaction bool
+	Protocol      *int
 }
 
 func Handle(ctx Context) (resp.RESP, error) {
@@ -104,6 +373,7 @@ func HandleNext(r *respreader.BufferedRESPConnReader, opts HandlerOptions) error
 		Com:           com,
 		Queued:        opts.Queued,
 		InTransaction: opts.InTransaction,
+		Protocol:      opts.Protocol,
 		Handle:        Handle,
 	}
 	res, err := ctx.Handle(ctx)
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var copyCommand = "COPY"
+
+// handleCopy copies the value at a key, as with COPY source destination [DB 0] [REPLACE], and replies with whether it did.
+// There is a single database, so DB only accepts 0.
+func handleCopy(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	replace := false
+	for i := 3; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "REPLACE":
+			replace = true
+		case "DB":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			if sa[i+1] != "0" {
+				return &resp.RESPSimpleError{Value: "ERR DB index is out of range"}, nil
+			}
+			i++
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var copied bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		if copied, served, err = state.Copy(sa[1], sa[2], replace); err != nil || !copied {
+			return nil, err
+		}
+		return append([]resp.RESP{ctx.Com}, served...), nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(copied), nil
+}
@@ -0,0 +1,15 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var dbsizeCommand = "DBSIZE"
+
+func handleDbsize(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 1 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 1-element array"}, nil
+	}
+	return resp.RESPInteger{Value: state.Dbsize()}, nil
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	delCommand    = "DEL"
+	unlinkCommand = "UNLINK"
+)
+
+// handleDel handles the DEL and UNLINK commands. UNLINK is deliberately synchronous, the same as DEL: deleting a key only drops the reference
+// held by the database, which takes constant time whatever the size of the value, and the garbage collector reclaims the memory concurrently,
+// so there is nothing left for a deferred release to take off the request path.
+func handleDel(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		if n = state.Del(sa[1:]); n == 0 {
+			return nil, nil
+		}
+		return []resp.RESP{ctx.Com}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
@@ -28,6 +28,7 @@ func handleExec(sa []string, ctx Context) (resp.RESP, error) {
 				Com:           com,
 				Queued:        ctx.Queued,
 				InTransaction: true,
+				Protocol:      ctx.Protocol,
 			})
 			if err != nil {
 				return nil, err
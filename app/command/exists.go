@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	existsCommand = "EXISTS"
+	touchCommand  = "TOUCH"
+)
+
+func handleExists(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	return resp.RESPInteger{Value: state.Exists(sa[1:])}, nil
+}
+
+// handleTouch replies with the number of keys that hold a value. As no access times are tracked, it is otherwise the same as EXISTS.
+func handleTouch(sa []string, ctx Context) (resp.RESP, error) {
+	return handleExists(sa, ctx)
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	expireCommand    = "EXPIRE"
+	pexpireCommand   = "PEXPIRE"
+	expireatCommand  = "EXPIREAT"
+	pexpireatCommand = "PEXPIREAT"
+)
+
+func handleExpire(sa []string, ctx Context) (resp.RESP, error) {
+	return handleExpireAux(sa, ctx, time.Second, false)
+}
+
+func handlePexpire(sa []string, ctx Context) (resp.RESP, error) {
+	return handleExpireAux(sa, ctx, time.Millisecond, false)
+}
+
+func handleExpireat(sa []string, ctx Context) (resp.RESP, error) {
+	return handleExpireAux(sa, ctx, time.Second, true)
+}
+
+func handlePexpireat(sa []string, ctx Context) (resp.RESP, error) {
+	return handleExpireAux(sa, ctx, time.Millisecond, true)
+}
+
+// handleExpireAux handles the EXPIRE, PEXPIRE, EXPIREAT and PEXPIREAT commands, where the expiry is given in units of unit,
+// and as a unix time if absolute is set. Any number of NX, XX, GT and LT flags may follow, as long as they are compatible.
+// The expiry is propagated as an absolute PEXPIREAT, or as a DEL if it is already past.
+func handleExpireAux(sa []string, ctx Context, unit time.Duration, absolute bool) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	key := sa[1]
+	n, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	at, ok := expiryTime(n, unit, absolute)
+	if !ok {
+		return &resp.RESPSimpleError{Value: "ERR invalid expire time in '" + strings.ToLower(sa[0]) + "' command"}, nil
+	}
+	var conds []string
+	flags := map[string]bool{}
+	for _, s := range sa[3:] {
+		switch c := strings.ToUpper(s); c {
+		case "NX", "XX", "GT", "LT":
+			conds = append(conds, c)
+			flags[c] = true
+		default:
+			return &resp.RESPSimpleError{Value: "ERR Unsupported option " + s}, nil
+		}
+	}
+	if flags["NX"] && (flags["XX"] || flags["GT"] || flags["LT"]) {
+		return &resp.RESPSimpleError{Value: "ERR NX and XX, GT or LT options at the same time are not compatible"}, nil
+	}
+	if flags["GT"] && flags["LT"] {
+		return &resp.RESPSimpleError{Value: "ERR GT and LT options at the same time are not compatible"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var set bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var deleted bool
+		set, deleted = state.Expire(key, at, conds...)
+		switch {
+		case deleted:
+			return []resp.RESP{resp.EncodeStringSlice([]string{"DEL", key})}, nil
+		case set:
+			return []resp.RESP{resp.EncodeStringSlice([]string{"PEXPIREAT", key, strconv.FormatInt(at.UnixMilli(), 10)})}, nil
+		}
+		return nil, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(set), nil
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	flushallCommand = "FLUSHALL"
+	flushdbCommand  = "FLUSHDB"
+)
+
+// handleFlushall deletes every key, as with FLUSHALL [ASYNC|SYNC] and FLUSHDB [ASYNC|SYNC], which are the same as there is a single database.
+// FLUSHALL ASYNC is deliberately synchronous, the same as SYNC: the database is swapped for an empty one in constant time, and the garbage
+// collector reclaims the old one concurrently, so there is nothing left for a deferred release to take off the request path.
+func handleFlushall(sa []string, ctx Context) (resp.RESP, error) {
+	switch len(sa) {
+	case 1:
+	case 2:
+		switch strings.ToUpper(sa[1]) {
+		case "ASYNC", "SYNC":
+		default:
+			return ErrorSyntax, nil
+		}
+	default:
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 1 or 2-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		state.Flushall()
+		return []resp.RESP{ctx.Com}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
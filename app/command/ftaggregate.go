@@ -0,0 +1,166 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var ftAggregateCommand = "FT.AGGREGATE"
+
+// handleFtAggregate runs the documents matching a query through a pipeline of GROUPBY, SORTBY and LIMIT steps in the given order, as with
+// FT.AGGREGATE index query [LOAD count property...] [GROUPBY count property... [REDUCE function count arg... [AS name]]...]
+// [SORTBY count (property [ASC|DESC])... [MAX num]] [LIMIT offset num]. Properties are prefixed with @.
+// It replies with the number of rows, followed by the properties and values of each row.
+func handleFtAggregate(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	var load []string
+	var steps []state.SearchStep
+	for i := 3; i < len(sa); i++ {
+		opt := strings.ToUpper(sa[i])
+		switch opt {
+		case "VERBATIM":
+			continue
+		case "DIALECT":
+			i++
+			continue
+		case "LIMIT":
+			offset, num, ok := parseFtLimit(sa[i+1:])
+			if !ok {
+				return &resp.RESPSimpleError{Value: "Bad arguments for LIMIT: Value is not an integer or out of range"}, nil
+			}
+			steps = append(steps, state.SearchStep{Kind: state.SearchStepLimit, Offset: offset, Num: num})
+			i += 2
+			continue
+		case "LOAD", "GROUPBY", "SORTBY":
+		default:
+			return &resp.RESPSimpleError{Value: "Unknown argument `" + sa[i] + "`"}, nil
+		}
+		if i+1 >= len(sa) {
+			return ErrorSyntax, nil
+		}
+		if opt == "LOAD" && sa[i+1] == "*" {
+			load = []string{"*"}
+			i++
+			continue
+		}
+		n, err := strconv.Atoi(sa[i+1])
+		if err != nil || n < 0 || i+1+n >= len(sa) {
+			return &resp.RESPSimpleError{Value: "Bad arguments for " + opt + ": Value is not an integer or out of range"}, nil
+		}
+		args := sa[i+2 : i+2+n]
+		i += 1 + n
+		switch opt {
+		case "LOAD":
+			for _, arg := range args {
+				load = append(load, strings.TrimPrefix(arg, "@"))
+			}
+		case "GROUPBY":
+			step := state.SearchStep{Kind: state.SearchStepGroupBy}
+			for _, arg := range args {
+				if !strings.HasPrefix(arg, "@") {
+					return &resp.RESPSimpleError{Value: "Bad arguments for GROUPBY: Unknown property `" + arg + "`. Did you mean `@" + arg + "`?"}, nil
+				}
+				step.GroupBy = append(step.GroupBy, arg[1:])
+			}
+			for i+1 < len(sa) && strings.ToUpper(sa[i+1]) == "REDUCE" {
+				r, next, errResp := parseFtReducer(sa, i+2)
+				if errResp != nil {
+					return errResp, nil
+				}
+				step.Reducers = append(step.Reducers, r)
+				i = next - 1
+			}
+			steps = append(steps, step)
+		case "SORTBY":
+			step := state.SearchStep{Kind: state.SearchStepSortBy}
+			for _, arg := range args {
+				switch strings.ToUpper(arg) {
+				case "ASC", "DESC":
+					if len(step.SortBy) == 0 {
+						return ErrorSyntax, nil
+					}
+					step.SortBy[len(step.SortBy)-1].Desc = strings.ToUpper(arg) == "DESC"
+				default:
+					step.SortBy = append(step.SortBy, state.SearchSortKey{Property: strings.TrimPrefix(arg, "@")})
+				}
+			}
+			if i+2 < len(sa) && strings.ToUpper(sa[i+1]) == "MAX" {
+				maxRows, err := strconv.ParseInt(sa[i+2], 10, 64)
+				if err != nil || maxRows < 0 {
+					return &resp.RESPSimpleError{Value: "Bad arguments for MAX: Value is not an integer or out of range"}, nil
+				}
+				step.Max = maxRows
+				i += 2
+			}
+			steps = append(steps, step)
+		}
+	}
+	total, rows, err := state.FtAggregate(sa[1], sa[2], load, steps)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(rows))
+	for i, row := range rows {
+		values := make([]resp.RESP, 0, 2*len(row.Properties))
+		for _, property := range row.Properties {
+			var value resp.RESP = resp.NullLit
+			if v, ok := row.Values[property]; ok && v.IsList {
+				value = resp.EncodeStringSlice(v.List)
+			} else if ok {
+				value = &resp.RESPBulkString{Value: v.Str}
+			}
+			values = append(values, &resp.RESPBulkString{Value: property}, value)
+		}
+		if isResp3(ctx) {
+			av[i] = &resp.RESPMap{Value: []resp.RESP{
+				&resp.RESPSimpleString{Value: "extra_attributes"}, &resp.RESPMap{Value: values},
+				&resp.RESPSimpleString{Value: "values"}, &resp.RESPArray{Value: []resp.RESP{}},
+			}}
+		} else {
+			av[i] = &resp.RESPArray{Value: values}
+		}
+	}
+	if isResp3(ctx) {
+		return encodeFtResults(total, av), nil
+	}
+	return &resp.RESPArray{Value: append([]resp.RESP{resp.RESPInteger{Value: total}}, av...)}, nil
+}
+
+// parseFtReducer parses a reducer following REDUCE at sa[i], returning the index following it
+func parseFtReducer(sa []string, i int) (state.SearchReducer, int, resp.RESP) {
+	var r state.SearchReducer
+	if i+1 >= len(sa) {
+		return r, 0, ErrorSyntax
+	}
+	r.Func = strings.ToUpper(sa[i])
+	n, err := strconv.Atoi(sa[i+1])
+	if err != nil || n < 0 || i+1+n >= len(sa) {
+		return r, 0, &resp.RESPSimpleError{Value: "Bad arguments for " + r.Func + ": Value is not an integer or out of range"}
+	}
+	args := sa[i+2 : i+2+n]
+	i += 2 + n
+	switch r.Func {
+	case "COUNT":
+		if n != 0 {
+			return r, 0, &resp.RESPSimpleError{Value: "Bad arguments for COUNT: Count accepts 0 values only"}
+		}
+	case "COUNT_DISTINCT", "SUM", "MIN", "MAX", "AVG", "TOLIST":
+		if n != 1 || !strings.HasPrefix(args[0], "@") {
+			return r, 0, &resp.RESPSimpleError{Value: "Bad arguments for " + r.Func + ": expected a single property"}
+		}
+		r.Property = args[0][1:]
+	default:
+		return r, 0, &resp.RESPSimpleError{Value: "No such reducer: " + r.Func}
+	}
+	r.As = "__generated_alias" + strings.ToLower(r.Func) + r.Property
+	if i+1 < len(sa) && strings.ToUpper(sa[i]) == "AS" {
+		r.As = sa[i+1]
+		i += 2
+	}
+	return r, i, nil
+}
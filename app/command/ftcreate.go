@@ -0,0 +1,117 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var ftCreateCommand = "FT.CREATE"
+
+// handleFtCreate creates an index of hashes, as with FT.CREATE index [ON HASH] [PREFIX count prefix...] SCHEMA field [AS attribute] type [options]...
+func handleFtCreate(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	var prefixes []string
+	i := 2
+	for ; i < len(sa) && strings.ToUpper(sa[i]) != "SCHEMA"; i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "ON":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			if strings.ToUpper(sa[i+1]) != "HASH" {
+				return &resp.RESPSimpleError{Value: "Only HASH indexes are supported"}, nil
+			}
+			i++
+		case "PREFIX":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			n, err := strconv.Atoi(sa[i+1])
+			if err != nil || n < 1 || i+1+n >= len(sa) {
+				return &resp.RESPSimpleError{Value: "Bad arguments for PREFIX: Value is not an integer or out of range"}, nil
+			}
+			prefixes = append(prefixes, sa[i+2:i+2+n]...)
+			i += 1 + n
+		default:
+			return &resp.RESPSimpleError{Value: "Unknown argument `" + sa[i] + "`"}, nil
+		}
+	}
+	if i+1 >= len(sa) {
+		return &resp.RESPSimpleError{Value: "Fields arguments are missing"}, nil
+	}
+	fields, errResp := parseFtSchema(sa[i+1:])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.FtCreate(sa[1], prefixes, fields)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
+
+// parseFtSchema parses the fields of a schema, each of which is a field name, optionally followed by AS and an attribute,
+// then its type and options: WEIGHT and NOSTEM for TEXT, SEPARATOR and CASESENSITIVE for TAG, and SORTABLE for any type
+func parseFtSchema(sa []string) ([]state.SearchField, resp.RESP) {
+	var fields []state.SearchField
+	for i := 0; i < len(sa); {
+		f := state.SearchField{Identifier: sa[i], Attribute: sa[i], Weight: 1, Separator: ','}
+		i++
+		if i+1 < len(sa) && strings.ToUpper(sa[i]) == "AS" {
+			f.Attribute = sa[i+1]
+			i += 2
+		}
+		if i >= len(sa) {
+			return nil, &resp.RESPSimpleError{Value: "Field `" + f.Identifier + "` does not have a type"}
+		}
+		f.Type = strings.ToUpper(sa[i])
+		switch f.Type {
+		case state.SearchFieldText, state.SearchFieldTag, state.SearchFieldNumeric:
+		default:
+			return nil, &resp.RESPSimpleError{Value: "Invalid field type for field `" + f.Identifier + "`"}
+		}
+		for _, other := range fields {
+			if other.Attribute == f.Attribute || other.Identifier == f.Identifier {
+				return nil, &resp.RESPSimpleError{Value: "Duplicate field in schema - " + f.Attribute}
+			}
+		}
+		i++
+	options:
+		for ; i < len(sa); i++ {
+			opt := strings.ToUpper(sa[i])
+			switch {
+			case opt == "SORTABLE":
+				f.Sortable = true
+			case opt == "NOSTEM" && f.Type == state.SearchFieldText:
+			case opt == "WEIGHT" && f.Type == state.SearchFieldText && i+1 < len(sa):
+				w, err := strconv.ParseFloat(sa[i+1], 64)
+				if err != nil || w < 0 {
+					return nil, &resp.RESPSimpleError{Value: "Bad arguments for WEIGHT: Could not convert argument to expected type"}
+				}
+				f.Weight = w
+				i++
+			case opt == "SEPARATOR" && f.Type == state.SearchFieldTag && i+1 < len(sa):
+				if len(sa[i+1]) != 1 {
+					return nil, &resp.RESPSimpleError{Value: "Tag separator must be a single character. Got `" + sa[i+1] + "`"}
+				}
+				f.Separator = sa[i+1][0]
+				i++
+			case opt == "CASESENSITIVE" && f.Type == state.SearchFieldTag:
+				f.CaseSensitive = true
+			default:
+				break options
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
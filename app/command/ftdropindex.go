@@ -0,0 +1,30 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var ftDropindexCommand = "FT.DROPINDEX"
+
+// handleFtDropindex drops an index, also deleting the indexed hashes with DD
+func handleFtDropindex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	deleteDocs := len(sa) == 3
+	if deleteDocs && strings.ToUpper(sa[2]) != "DD" {
+		return ErrorSyntax, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.FtDropindex(sa[1], deleteDocs)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
@@ -0,0 +1,59 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var ftInfoCommand = "FT.INFO"
+
+func handleFtInfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	info, err := state.FtInfo(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	attributes := make([]resp.RESP, len(info.Fields))
+	for i, f := range info.Fields {
+		av := []resp.RESP{
+			&resp.RESPSimpleString{Value: "identifier"}, &resp.RESPBulkString{Value: f.Identifier},
+			&resp.RESPSimpleString{Value: "attribute"}, &resp.RESPBulkString{Value: f.Attribute},
+			&resp.RESPSimpleString{Value: "type"}, &resp.RESPSimpleString{Value: f.Type},
+		}
+		switch f.Type {
+		case state.SearchFieldText:
+			av = append(av, &resp.RESPSimpleString{Value: "WEIGHT"}, &resp.RESPBulkString{Value: strconv.FormatFloat(f.Weight, 'f', -1, 64)})
+		case state.SearchFieldTag:
+			av = append(av, &resp.RESPSimpleString{Value: "SEPARATOR"}, &resp.RESPBulkString{Value: string(f.Separator)})
+			if f.CaseSensitive {
+				av = append(av, &resp.RESPSimpleString{Value: "CASESENSITIVE"})
+			}
+		}
+		if f.Sortable {
+			av = append(av, &resp.RESPSimpleString{Value: "SORTABLE"})
+		}
+		attributes[i] = &resp.RESPArray{Value: av}
+	}
+	definition := []resp.RESP{
+		&resp.RESPSimpleString{Value: "key_type"}, &resp.RESPSimpleString{Value: "HASH"},
+		&resp.RESPSimpleString{Value: "prefixes"}, resp.EncodeStringSlice(info.Prefixes),
+	}
+	av := []resp.RESP{
+		&resp.RESPSimpleString{Value: "index_name"}, &resp.RESPBulkString{Value: sa[1]},
+		&resp.RESPSimpleString{Value: "index_options"}, &resp.RESPArray{Value: []resp.RESP{}},
+		&resp.RESPSimpleString{Value: "index_definition"}, &resp.RESPArray{Value: definition},
+		&resp.RESPSimpleString{Value: "attributes"}, &resp.RESPArray{Value: attributes},
+		&resp.RESPSimpleString{Value: "num_docs"}, resp.RESPInteger{Value: info.NumDocs},
+		&resp.RESPSimpleString{Value: "max_doc_id"}, resp.RESPInteger{Value: info.MaxDocID},
+		&resp.RESPSimpleString{Value: "num_terms"}, resp.RESPInteger{Value: info.NumTerms},
+		&resp.RESPSimpleString{Value: "hash_indexing_failures"}, resp.RESPInteger{Value: info.NumFailed},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,130 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var ftSearchCommand = "FT.SEARCH"
+
+// ftDefaultLimit is the number of results returned without LIMIT
+const ftDefaultLimit = 10
+
+// handleFtSearch replies with the number of matching documents, followed by the key of each result, its score with WITHSCORES,
+// and its fields and values unless NOCONTENT is given. RESP3 connections get a map of the results instead.
+func handleFtSearch(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	opts := state.SearchOptions{Num: ftDefaultLimit}
+	withScores := false
+	for i := 3; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "NOCONTENT":
+			opts.NoContent = true
+		case "WITHSCORES":
+			withScores = true
+		case "VERBATIM":
+		case "RETURN":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			n, err := strconv.Atoi(sa[i+1])
+			if err != nil || n < 0 || i+1+n >= len(sa) {
+				return &resp.RESPSimpleError{Value: "Bad arguments for RETURN: Value is not an integer or out of range"}, nil
+			}
+			if n == 0 {
+				opts.NoContent = true
+			}
+			opts.Return = append([]string{}, sa[i+2:i+2+n]...)
+			i += 1 + n
+		case "SORTBY":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			opts.SortBy = strings.TrimPrefix(sa[i+1], "@")
+			i++
+			if i+1 < len(sa) {
+				switch strings.ToUpper(sa[i+1]) {
+				case "ASC":
+					i++
+				case "DESC":
+					opts.SortDesc = true
+					i++
+				}
+			}
+		case "LIMIT":
+			offset, num, ok := parseFtLimit(sa[i+1:])
+			if !ok {
+				return &resp.RESPSimpleError{Value: "Bad arguments for LIMIT: Value is not an integer or out of range"}, nil
+			}
+			opts.Offset, opts.Num = offset, num
+			i += 2
+		case "DIALECT":
+			i++
+		default:
+			return &resp.RESPSimpleError{Value: "Unknown argument `" + sa[i] + "`"}, nil
+		}
+	}
+	total, results, err := state.FtSearch(sa[1], sa[2], opts)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if isResp3(ctx) {
+		av := make([]resp.RESP, len(results))
+		for i, r := range results {
+			m := []resp.RESP{&resp.RESPSimpleString{Value: "id"}, &resp.RESPBulkString{Value: r.Key}}
+			if withScores {
+				m = append(m, &resp.RESPSimpleString{Value: "score"}, &resp.RESPDouble{Value: r.Score})
+			}
+			if !opts.NoContent {
+				m = append(m, &resp.RESPSimpleString{Value: "extra_attributes"}, &resp.RESPMap{Value: encodeFtStrings(r.Fields)})
+			}
+			av[i] = &resp.RESPMap{Value: append(m, &resp.RESPSimpleString{Value: "values"}, &resp.RESPArray{Value: []resp.RESP{}})}
+		}
+		return encodeFtResults(total, av), nil
+	}
+	av := []resp.RESP{resp.RESPInteger{Value: total}}
+	for _, r := range results {
+		av = append(av, &resp.RESPBulkString{Value: r.Key})
+		if withScores {
+			av = append(av, &resp.RESPBulkString{Value: formatScore(r.Score)})
+		}
+		if !opts.NoContent {
+			av = append(av, resp.EncodeStringSlice(r.Fields))
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
+
+// parseFtLimit parses the offset and number of results following LIMIT
+func parseFtLimit(sa []string) (int64, int64, bool) {
+	if len(sa) < 2 {
+		return 0, 0, false
+	}
+	offset, err1 := strconv.ParseInt(sa[0], 10, 64)
+	num, err2 := strconv.ParseInt(sa[1], 10, 64)
+	return offset, num, err1 == nil && err2 == nil && offset >= 0 && num >= 0
+}
+
+func encodeFtStrings(sa []string) []resp.RESP {
+	av := make([]resp.RESP, len(sa))
+	for i, s := range sa {
+		av[i] = &resp.RESPBulkString{Value: s}
+	}
+	return av
+}
+
+// encodeFtResults encodes the results of FT.SEARCH and FT.AGGREGATE for RESP3 connections
+func encodeFtResults(total int64, results []resp.RESP) resp.RESP {
+	return &resp.RESPMap{Value: []resp.RESP{
+		&resp.RESPSimpleString{Value: "attributes"}, &resp.RESPArray{Value: []resp.RESP{}},
+		&resp.RESPSimpleString{Value: "format"}, &resp.RESPSimpleString{Value: "STRING"},
+		&resp.RESPSimpleString{Value: "results"}, &resp.RESPArray{Value: results},
+		&resp.RESPSimpleString{Value: "total_results"}, resp.RESPInteger{Value: total},
+		&resp.RESPSimpleString{Value: "warning"}, &resp.RESPArray{Value: []resp.RESP{}},
+	}}
+}
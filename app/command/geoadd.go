@@ -0,0 +1,83 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geoaddCommand = "GEOADD"
+
+func handleGeoadd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	key := sa[1]
+	var opts state.ZaddOptions
+	ch := false
+	i := 2
+flags:
+	for ; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "NX":
+			opts.NX = true
+		case "XX":
+			opts.XX = true
+		case "CH":
+			ch = true
+		default:
+			break flags
+		}
+	}
+	triples := sa[i:]
+	if len(triples) == 0 || len(triples)%3 != 0 {
+		return &resp.RESPSimpleError{Value: "ERR syntax error. Try GEOADD key [x1] [y1] [name1] [x2] [y2] [name2] ... "}, nil
+	}
+	if opts.NX && opts.XX {
+		return &resp.RESPSimpleError{Value: "ERR XX and NX options at the same time are not compatible"}, nil
+	}
+	members := make([]state.ZMember, len(triples)/3)
+	for j := range members {
+		p, errRes := parseGeoPoint(triples[3*j], triples[3*j+1])
+		if errRes != nil {
+			return errRes, nil
+		}
+		members[j] = state.ZMember{Member: triples[3*j+2], Score: state.GeoScore(p)}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var added, changed int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		added, changed, served, err = state.Zadd(key, members, opts)
+		if added+changed == 0 {
+			return nil, err
+		}
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if ch {
+		return resp.RESPInteger{Value: added + changed}, nil
+	}
+	return resp.RESPInteger{Value: added}, nil
+}
+
+// parseGeoPoint parses a longitude and latitude, which must be within the range that can be indexed
+func parseGeoPoint(long, lat string) (state.GeoPoint, resp.RESP) {
+	var p state.GeoPoint
+	var ok1, ok2 bool
+	p.Long, ok1 = parseScore(long)
+	p.Lat, ok2 = parseScore(lat)
+	if !ok1 || !ok2 {
+		return p, ErrorNotFloat
+	}
+	if !state.ValidGeoPoint(p) {
+		return p, &resp.RESPSimpleError{Value: fmt.Sprintf("ERR invalid longitude,latitude pair %f,%f", p.Long, p.Lat)}
+	}
+	return p, nil
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geodistCommand = "GEODIST"
+
+func handleGeodist(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 4 && len(sa) != 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4 or 5-element array"}, nil
+	}
+	conversion := 1.0
+	if len(sa) == 5 {
+		var errRes resp.RESP
+		conversion, errRes = parseGeoUnit(sa[4])
+		if errRes != nil {
+			return errRes, nil
+		}
+	}
+	d, err := state.Geodist(sa[1], sa[2], sa[3])
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeGeoDistance(d / conversion), nil
+}
+
+// parseGeoUnit parses a unit of distance, returning its length in meters
+func parseGeoUnit(s string) (float64, resp.RESP) {
+	switch strings.ToLower(s) {
+	case "m":
+		return 1, nil
+	case "km":
+		return 1000, nil
+	case "ft":
+		return 0.3048, nil
+	case "mi":
+		return 1609.34, nil
+	}
+	return 0, &resp.RESPSimpleError{Value: "ERR unsupported unit provided. please use M, KM, FT, MI"}
+}
+
+// encodeGeoDistance encodes a distance with 4 decimals, which Redis replies with as a bulk string for every protocol
+func encodeGeoDistance(d float64) resp.RESP {
+	return &resp.RESPBulkString{Value: strconv.FormatFloat(d, 'f', 4, 64)}
+}
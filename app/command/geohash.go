@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geohashCommand = "GEOHASH"
+
+func handleGeohash(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	hashes, err := state.Geohash(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(hashes))
+	for i, h := range hashes {
+		if h == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = &resp.RESPBulkString{Value: *h}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
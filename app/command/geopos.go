@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geoposCommand = "GEOPOS"
+
+func handleGeopos(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	points, err := state.Geopos(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(points))
+	for i, p := range points {
+		if p == nil {
+			av[i] = resp.NullArrayLit
+		} else {
+			av[i] = encodeGeoPoint(*p, ctx)
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
+
+// encodeGeoPoint encodes a longitude and latitude pair, in the fixed-point form with up to 17 decimals that Redis uses unless the connection uses RESP3
+func encodeGeoPoint(p state.GeoPoint, ctx Context) resp.RESP {
+	encode := func(f float64) resp.RESP {
+		if isResp3(ctx) {
+			return &resp.RESPDouble{Value: f}
+		}
+		s := strconv.FormatFloat(f, 'f', 17, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		return &resp.RESPBulkString{Value: s}
+	}
+	return &resp.RESPArray{Value: []resp.RESP{encode(p.Long), encode(p.Lat)}}
+}
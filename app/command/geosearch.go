@@ -0,0 +1,154 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geosearchCommand = "GEOSEARCH"
+
+func handleGeosearch(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	args, errRes := parseGeosearchArgs(sa[0], sa[2:], false)
+	if errRes != nil {
+		return errRes, nil
+	}
+	results, err := state.Geosearch(sa[1], args.spec)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(results))
+	for i, r := range results {
+		member := &resp.RESPBulkString{Value: r.Member}
+		if !args.withDist && !args.withHash && !args.withCoord {
+			av[i] = member
+			continue
+		}
+		item := []resp.RESP{member}
+		if args.withDist {
+			item = append(item, encodeGeoDistance(r.Dist))
+		}
+		if args.withHash {
+			item = append(item, resp.RESPInteger{Value: int64(r.Score)})
+		}
+		if args.withCoord {
+			item = append(item, encodeGeoPoint(r.GeoPoint, ctx))
+		}
+		av[i] = &resp.RESPArray{Value: item}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
+
+type geosearchArgs struct {
+	spec                          state.GeoSearchSpec
+	withDist, withHash, withCoord bool
+	storeDist                     bool
+}
+
+// parseGeosearchArgs parses FROMMEMBER member | FROMLONLAT longitude latitude, BYRADIUS radius unit | BYBOX width height unit,
+// [ASC | DESC] [COUNT count [ANY]] and either [WITHCOORD] [WITHDIST] [WITHHASH], or [STOREDIST] if store is set.
+// command is the name of the command for error messages.
+func parseGeosearchArgs(command string, sa []string, store bool) (geosearchArgs, resp.RESP) {
+	var args geosearchArgs
+	spec := &args.spec
+	from, by := 0, 0
+	parseDistance := func(s, name string) (float64, resp.RESP) {
+		d, ok := parseScore(s)
+		if !ok {
+			return 0, &resp.RESPSimpleError{Value: "ERR need numeric " + name}
+		}
+		return d, nil
+	}
+	for i := 0; i < len(sa); i++ {
+		switch opt := strings.ToUpper(sa[i]); {
+		case opt == "FROMMEMBER" && i+1 < len(sa):
+			spec.FromMember = &sa[i+1]
+			from++
+			i++
+		case opt == "FROMLONLAT" && i+2 < len(sa):
+			p, errRes := parseGeoPoint(sa[i+1], sa[i+2])
+			if errRes != nil {
+				return args, errRes
+			}
+			spec.From = p
+			from++
+			i += 2
+		case opt == "BYRADIUS" && i+2 < len(sa):
+			r, errRes := parseDistance(sa[i+1], "radius")
+			if errRes != nil {
+				return args, errRes
+			}
+			if r < 0 {
+				return args, &resp.RESPSimpleError{Value: "ERR radius cannot be negative"}
+			}
+			if spec.Conversion, errRes = parseGeoUnit(sa[i+2]); errRes != nil {
+				return args, errRes
+			}
+			spec.Box, spec.Radius = false, r
+			by++
+			i += 2
+		case opt == "BYBOX" && i+3 < len(sa):
+			w, errRes := parseDistance(sa[i+1], "width")
+			if errRes != nil {
+				return args, errRes
+			}
+			h, errRes := parseDistance(sa[i+2], "height")
+			if errRes != nil {
+				return args, errRes
+			}
+			if w < 0 || h < 0 {
+				return args, &resp.RESPSimpleError{Value: "ERR height or width cannot be negative"}
+			}
+			if spec.Conversion, errRes = parseGeoUnit(sa[i+3]); errRes != nil {
+				return args, errRes
+			}
+			spec.Box, spec.Width, spec.Height = true, w, h
+			by++
+			i += 3
+		case opt == "ASC":
+			spec.Sort = state.GeoSortAsc
+		case opt == "DESC":
+			spec.Sort = state.GeoSortDesc
+		case opt == "COUNT" && i+1 < len(sa):
+			n, err := strconv.ParseInt(sa[i+1], 10, 64)
+			if err != nil {
+				return args, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+			}
+			if n <= 0 {
+				return args, &resp.RESPSimpleError{Value: "ERR COUNT must be > 0"}
+			}
+			spec.Count = n
+			i++
+			if i+1 < len(sa) && strings.ToUpper(sa[i+1]) == "ANY" {
+				spec.Any = true
+				i++
+			}
+		case opt == "WITHDIST" && !store:
+			args.withDist = true
+		case opt == "WITHHASH" && !store:
+			args.withHash = true
+		case opt == "WITHCOORD" && !store:
+			args.withCoord = true
+		case opt == "STOREDIST" && store:
+			args.storeDist = true
+		default:
+			return args, ErrorSyntax
+		}
+	}
+	if from != 1 {
+		return args, &resp.RESPSimpleError{Value: "ERR exactly one of FROMMEMBER or FROMLONLAT can be specified for " + command}
+	}
+	if by != 1 {
+		return args, &resp.RESPSimpleError{Value: "ERR exactly one of BYRADIUS and BYBOX can be specified for " + command}
+	}
+	// the nearest results are returned when their number is limited, unless any results will do
+	if spec.Count != 0 && !spec.Any && spec.Sort == state.GeoSortNone {
+		spec.Sort = state.GeoSortAsc
+	}
+	return args, nil
+}
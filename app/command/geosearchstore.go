@@ -0,0 +1,32 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var geosearchstoreCommand = "GEOSEARCHSTORE"
+
+func handleGeosearchstore(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	dst, src := sa[1], sa[2]
+	args, errRes := parseGeosearchArgs(sa[0], sa[3:], true)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		n, served, err = state.Geosearchstore(dst, src, args.spec, args.storeDist)
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
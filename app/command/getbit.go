@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var getbitCommand = "GETBIT"
+
+func handleGetbit(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	offset, errRes := parseBitOffset(sa[2])
+	if errRes != nil {
+		return errRes, nil
+	}
+	bit, err := state.Getbit(sa[1], offset)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: int64(bit)}, nil
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var getdelCommand = "GETDEL"
+
+func handleGetdel(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var value string
+	err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		value, err = state.Getdel(sa[1])
+		return []resp.RESP{ctx.Com}, err
+	})
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: value}, nil
+}
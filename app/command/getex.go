@@ -0,0 +1,59 @@
+package command
+
+import (
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var getexCommand = "GETEX"
+
+// handleGetex replies with the string at a key, and then sets or clears its expiry, as with GETEX key [EX seconds | PX milliseconds |
+// EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]. The change is propagated as a SET with the absolute expiry,
+// or as a DEL if the expiry is already past.
+func handleGetex(sa []string, ctx Context) (resp.RESP, error) {
+	var at time.Time
+	expire, persist := false, false
+	switch len(sa) {
+	case 2:
+	case 3:
+		if strings.ToUpper(sa[2]) != "PERSIST" {
+			return ErrorSyntax, nil
+		}
+		persist = true
+	case 4:
+		t, ok, errRes := parseExpiryOption(sa[0], sa[2], sa[3])
+		if !ok {
+			return ErrorSyntax, nil
+		}
+		if errRes != nil {
+			return errRes, nil
+		}
+		at, expire = t, true
+	default:
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2, 3 or 4-element array"}, nil
+	}
+	if (expire || persist) && ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var value string
+	err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		if value, err = state.Getex(sa[1], at, expire, persist); err != nil || !expire && !persist {
+			return nil, err
+		}
+		if expire && !at.After(time.Now()) {
+			return []resp.RESP{resp.EncodeStringSlice([]string{"DEL", sa[1]})}, nil
+		}
+		return []resp.RESP{encodeSetAt(sa[1], value, at)}, nil
+	})
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: value}, nil
+}
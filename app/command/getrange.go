@@ -0,0 +1,26 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var getrangeCommand = "GETRANGE"
+
+func handleGetrange(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	start, err1 := strconv.ParseInt(sa[2], 10, 64)
+	end, err2 := strconv.ParseInt(sa[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	s, err := state.Getrange(sa[1], start, end)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: s}, nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var getsetCommand = "GETSET"
+
+func handleGetset(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var prev *string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		prev, err = state.Getset(sa[1], sa[2])
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if prev == nil {
+		return resp.NullLit, nil
+	}
+	return &resp.RESPBulkString{Value: *prev}, nil
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var helloCommand = "HELLO"
+
+// handleHello switches the connection to the requested RESP version, which later replies with RESP3-only types (e.g. doubles) are encoded for.
+// Authentication is not supported, so AUTH is accepted for any credentials, and SETNAME is ignored.
+func handleHello(sa []string, ctx Context) (resp.RESP, error) {
+	protocol := *ctx.Protocol
+	if len(sa) >= 2 {
+		v, err := strconv.Atoi(sa[1])
+		if err != nil {
+			return &resp.RESPSimpleError{Value: "ERR Protocol version is not an integer or out of range"}, nil
+		}
+		if v != 2 && v != 3 {
+			return &resp.RESPSimpleError{Value: "NOPROTO unsupported protocol version"}, nil
+		}
+		protocol = v
+	}
+	for i := 2; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "AUTH":
+			if i+2 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			i += 2
+		case "SETNAME":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			i++
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	*ctx.Protocol = protocol
+	role := "master"
+	if ctx.IsReplica {
+		role = "replica"
+	}
+	info := []resp.RESP{
+		&resp.RESPBulkString{Value: "server"}, &resp.RESPBulkString{Value: "redis"},
+		&resp.RESPBulkString{Value: "version"}, &resp.RESPBulkString{Value: "7.4.0"},
+		&resp.RESPBulkString{Value: "proto"}, resp.RESPInteger{Value: int64(protocol)},
+		&resp.RESPBulkString{Value: "mode"}, &resp.RESPBulkString{Value: "standalone"},
+		&resp.RESPBulkString{Value: "role"}, &resp.RESPBulkString{Value: role},
+		&resp.RESPBulkString{Value: "modules"}, &resp.RESPArray{Value: []resp.RESP{}},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: info}, nil
+	}
+	return &resp.RESPArray{Value: info}, nil
+}
+
+// isResp3 reports whether the connection has switched to RESP3 with HELLO
+func isResp3(ctx Context) bool {
+	return ctx.Protocol != nil && *ctx.Protocol == 3
+}
@@ -0,0 +1,22 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hexistsCommand = "HEXISTS"
+
+func handleHexists(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	ok, err := state.Hexists(sa[1], sa[2])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if ok {
+		return resp.RESPInteger{Value: 1}, nil
+	}
+	return resp.RESPInteger{Value: 0}, nil
+}
@@ -0,0 +1,163 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	hexpireCommand    = "HEXPIRE"
+	hpexpireCommand   = "HPEXPIRE"
+	hexpireatCommand  = "HEXPIREAT"
+	hpexpireatCommand = "HPEXPIREAT"
+)
+
+func handleHexpire(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHexpireAux(sa, ctx, time.Second, false)
+}
+
+func handleHpexpire(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHexpireAux(sa, ctx, time.Millisecond, false)
+}
+
+func handleHexpireat(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHexpireAux(sa, ctx, time.Second, true)
+}
+
+func handleHpexpireat(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHexpireAux(sa, ctx, time.Millisecond, true)
+}
+
+// handleHexpireAux handles the HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT commands, where the expiry is given in units of unit,
+// and as a unix time if absolute is set
+func handleHexpireAux(sa []string, ctx Context, unit time.Duration, absolute bool) (resp.RESP, error) {
+	if len(sa) < 6 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 6-element array"}, nil
+	}
+	key := sa[1]
+	n, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	at, ok := expiryTime(n, unit, absolute)
+	if !ok {
+		return &resp.RESPSimpleError{Value: "ERR invalid expire time in '" + strings.ToLower(sa[0]) + "' command"}, nil
+	}
+	i := 3
+	cond := ""
+	switch c := strings.ToUpper(sa[i]); c {
+	case "NX", "XX", "GT", "LT":
+		cond = c
+		i++
+	}
+	fields, errRes := parseFieldsArgs(sa[i:], 1)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var res []int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		res, err = state.Hexpire(key, fields, at, cond)
+		return hashFieldExpiryCommands(key, fields, res, at), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeIntegerSlice(res), nil
+}
+
+// expiryTime returns the time at which a key expires, given its expiry in units of unit, and as a unix time if absolute is set.
+// It returns false if the expiry is out of range.
+func expiryTime(n int64, unit time.Duration, absolute bool) (time.Time, bool) {
+	perMs := int64(unit / time.Millisecond)
+	if n > math.MaxInt64/perMs/2 || n < math.MinInt64/perMs/2 {
+		return time.Time{}, false
+	}
+	ms := n * perMs
+	if !absolute {
+		ms += time.Now().UnixMilli()
+	}
+	return time.UnixMilli(ms), true
+}
+
+// parseExpiryOption parses an EX, PX, EXAT or PXAT option followed by its argument, as accepted by SET, GETEX, HGETEX and HSETEX.
+// It returns false if opt is not one of these options, and an error response if the argument is invalid.
+func parseExpiryOption(command, opt, arg string) (time.Time, bool, resp.RESP) {
+	var unit time.Duration
+	var absolute bool
+	switch strings.ToUpper(opt) {
+	case "EX":
+		unit, absolute = time.Second, false
+	case "PX":
+		unit, absolute = time.Millisecond, false
+	case "EXAT":
+		unit, absolute = time.Second, true
+	case "PXAT":
+		unit, absolute = time.Millisecond, true
+	default:
+		return time.Time{}, false, nil
+	}
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return time.Time{}, true, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+	}
+	at, ok := expiryTime(n, unit, absolute)
+	if n <= 0 || !ok {
+		return time.Time{}, true, &resp.RESPSimpleError{Value: "ERR invalid expire time in '" + strings.ToLower(command) + "' command"}
+	}
+	return at, true, nil
+}
+
+// parseFieldsArgs parses the FIELDS numfields argument of the hash field expiry commands, followed by numfields groups of stride arguments each.
+// It returns the arguments following numfields.
+func parseFieldsArgs(args []string, stride int) ([]string, resp.RESP) {
+	if len(args) < 2 || strings.ToUpper(args[0]) != "FIELDS" {
+		return nil, &resp.RESPSimpleError{Value: "ERR Mandatory argument FIELDS is missing or not at the right position"}
+	}
+	n, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || n <= 0 {
+		return nil, &resp.RESPSimpleError{Value: "ERR Parameter `numFields` should be greater than 0"}
+	}
+	if int64(len(args)-2) != n*int64(stride) {
+		return nil, &resp.RESPSimpleError{Value: "ERR The `numfields` parameter must match the number of arguments"}
+	}
+	return args[2:], nil
+}
+
+// hashFieldExpiryCommands returns the commands that effect setting the expiry of fields to at, given the results of doing so,
+// as absolute HPEXPIREAT for fields whose expiry was set and HDEL for fields that were deleted
+func hashFieldExpiryCommands(key string, fields []string, res []int64, at time.Time) []resp.RESP {
+	var set, deleted []string
+	for i, r := range res {
+		switch r {
+		case state.HashFieldSet:
+			set = append(set, fields[i])
+		case state.HashFieldDeleted:
+			deleted = append(deleted, fields[i])
+		}
+	}
+	var cmds []resp.RESP
+	if len(set) > 0 {
+		sa := []string{"HPEXPIREAT", key, strconv.FormatInt(at.UnixMilli(), 10), "FIELDS", strconv.Itoa(len(set))}
+		cmds = append(cmds, resp.EncodeStringSlice(append(sa, set...)))
+	}
+	if len(deleted) > 0 {
+		cmds = append(cmds, resp.EncodeStringSlice(append([]string{"HDEL", key}, deleted...)))
+	}
+	return cmds
+}
+
+func encodeIntegerSlice(ia []int64) resp.RESP {
+	av := make([]resp.RESP, len(ia))
+	for i, n := range ia {
+		av[i] = resp.RESPInteger{Value: n}
+	}
+	return &resp.RESPArray{Value: av}
+}
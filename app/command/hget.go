@@ -0,0 +1,22 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hgetCommand = "HGET"
+
+func handleHget(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	value, err := state.Hget(sa[1], sa[2])
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: value}, nil
+}
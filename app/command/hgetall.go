@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hgetallCommand = "HGETALL"
+
+func handleHgetall(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	entries, err := state.Hgetall(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.EncodeStringSlice(entries), nil
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hgetexCommand = "HGETEX"
+
+func handleHgetex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	key := sa[1]
+	var at time.Time
+	expire, persist := false, false
+	i := 2
+	if strings.ToUpper(sa[i]) == "PERSIST" {
+		persist = true
+		i++
+	} else if t, ok, errRes := parseExpiryOption(sa[0], sa[i], sa[i+1]); ok {
+		if errRes != nil {
+			return errRes, nil
+		}
+		at, expire = t, true
+		i += 2
+	}
+	fields, errRes := parseFieldsArgs(sa[i:], 1)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if (expire || persist) && ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var values resp.RESP
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var res []int64
+		values, res, err = state.Hgetex(key, fields, at, expire, persist)
+		if expire {
+			return hashFieldExpiryCommands(key, fields, res, at), err
+		}
+		if persist && slices.Contains(res, state.HashFieldSet) {
+			sa := []string{"HPERSIST", key, "FIELDS", strconv.Itoa(len(fields))}
+			return []resp.RESP{resp.EncodeStringSlice(append(sa, fields...))}, err
+		}
+		return nil, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return values, nil
+}
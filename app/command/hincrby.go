@@ -0,0 +1,33 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hincrbyCommand = "HINCRBY"
+
+func handleHincrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	key, field := sa[1], sa[2]
+	by, err := strconv.ParseInt(sa[3], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.Hincrby(key, field, by)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
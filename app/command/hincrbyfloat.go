@@ -0,0 +1,38 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hincrbyfloatCommand = "HINCRBYFLOAT"
+
+var ErrorNotFloat = &resp.RESPSimpleError{Value: "ERR value is not a valid float"}
+
+func handleHincrbyfloat(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	key, field := sa[1], sa[2]
+	by, err := strconv.ParseFloat(sa[3], 64)
+	if err != nil || math.IsNaN(by) || math.IsInf(by, 0) {
+		return ErrorNotFloat, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var value string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		value, err = state.Hincrbyfloat(key, field, by)
+		// the computed value is propagated rather than the increment, so that replicas cannot diverge due to differences in float rounding,
+		// in a form that retains the expiry of the field
+		return []resp.RESP{resp.EncodeStringSlice([]string{"HSETEX", key, "KEEPTTL", "FIELDS", "1", field, value})}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: value}, nil
+}
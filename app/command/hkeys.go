@@ -0,0 +1,28 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hkeysCommand = "HKEYS"
+
+func handleHkeys(sa []string, _ Context) (resp.RESP, error) {
+	return handleHashEntriesAux(sa, 0)
+}
+
+// handleHashEntriesAux handles the HKEYS and HVALS commands, responding with every other entry of the hash starting from offset
+func handleHashEntriesAux(sa []string, offset int) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	entries, err := state.Hgetall(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	res := make([]string, 0, len(entries)/2)
+	for i := offset; i < len(entries); i += 2 {
+		res = append(res, entries[i])
+	}
+	return resp.EncodeStringSlice(res), nil
+}
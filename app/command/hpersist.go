@@ -0,0 +1,36 @@
+package command
+
+import (
+	"slices"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hpersistCommand = "HPERSIST"
+
+func handleHpersist(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	key := sa[1]
+	fields, errRes := parseFieldsArgs(sa[2:], 1)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var res []int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		res, err = state.Hpersist(key, fields)
+		if !slices.Contains(res, state.HashFieldSet) {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeIntegerSlice(res), nil
+}
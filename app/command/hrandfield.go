@@ -0,0 +1,59 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hrandfieldCommand = "HRANDFIELD"
+
+func handleHrandfield(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 || len(sa) > 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 to 4-element array"}, nil
+	}
+	key := sa[1]
+	var count int64 = 1
+	withValues := false
+	if len(sa) >= 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+		}
+		// a negative count asks for twice as many strings when WITHVALUES is given
+		if count < -math.MaxInt64/2 {
+			return ErrorOutOfRange, nil
+		}
+	}
+	if len(sa) == 4 {
+		if strings.ToUpper(sa[3]) != "WITHVALUES" {
+			return ErrorSyntax, nil
+		}
+		withValues = true
+	}
+	entries, err := state.Hrandfield(key, count)
+	if err == state.ErrorNone {
+		if len(sa) == 2 {
+			return resp.NullLit, nil
+		}
+		return resp.EncodeStringSlice([]string{}), nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if len(sa) == 2 {
+		return &resp.RESPBulkString{Value: entries[0]}, nil
+	}
+	if withValues {
+		return resp.EncodeStringSlice(entries), nil
+	}
+	fields := make([]string, 0, len(entries)/2)
+	for i := 0; i < len(entries); i += 2 {
+		fields = append(fields, entries[i])
+	}
+	return resp.EncodeStringSlice(fields), nil
+}
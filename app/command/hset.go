@@ -0,0 +1,42 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	hsetCommand  = "HSET"
+	hmsetCommand = "HMSET"
+)
+
+func handleHset(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHsetAux(sa, ctx, false)
+}
+
+func handleHmset(sa []string, ctx Context) (resp.RESP, error) {
+	return handleHsetAux(sa, ctx, true)
+}
+
+// handleHsetAux handles the HSET and HMSET commands, which differ only in their response
+func handleHsetAux(sa []string, ctx Context, replyOk bool) (resp.RESP, error) {
+	if len(sa) < 4 || len(sa)%2 != 0 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected an even, at least 4-element array"}, nil
+	}
+	key := sa[1]
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.Hset(key, sa[2:], false)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if replyOk {
+		return resp.OkLit, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
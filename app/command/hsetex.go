@@ -0,0 +1,87 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var hsetexCommand = "HSETEX"
+
+func handleHsetex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 6 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 6-element array"}, nil
+	}
+	key := sa[1]
+	var at time.Time
+	cond, keepTTL, hasExpiry := "", false, false
+	i := 2
+	for ; i < len(sa) && strings.ToUpper(sa[i]) != "FIELDS"; i++ {
+		switch opt := strings.ToUpper(sa[i]); opt {
+		case "FNX", "FXX":
+			if cond != "" {
+				return ErrorSyntax, nil
+			}
+			cond = opt
+		case "KEEPTTL":
+			if keepTTL || hasExpiry {
+				return ErrorSyntax, nil
+			}
+			keepTTL = true
+		default:
+			if i+1 >= len(sa) || keepTTL || hasExpiry {
+				return ErrorSyntax, nil
+			}
+			t, ok, errRes := parseExpiryOption(sa[0], sa[i], sa[i+1])
+			if !ok {
+				return ErrorSyntax, nil
+			}
+			if errRes != nil {
+				return errRes, nil
+			}
+			at, hasExpiry = t, true
+			i++
+		}
+	}
+	pairs, errRes := parseFieldsArgs(sa[i:], 2)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var ok bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var deleted bool
+		ok, deleted, err = state.Hsetex(key, pairs, cond, at, keepTTL)
+		if !ok {
+			return nil, err
+		}
+		fields := make([]string, 0, len(pairs)/2)
+		for j := 0; j < len(pairs); j += 2 {
+			fields = append(fields, pairs[j])
+		}
+		if deleted {
+			return []resp.RESP{resp.EncodeStringSlice(append([]string{"HDEL", key}, fields...))}, err
+		}
+		// the condition has been evaluated and relative expiries resolved, so the command is propagated unconditionally with an absolute expiry
+		com := []string{"HSETEX", key}
+		if keepTTL {
+			com = append(com, "KEEPTTL")
+		} else if hasExpiry {
+			com = append(com, "PXAT", strconv.FormatInt(at.UnixMilli(), 10))
+		}
+		com = append(com, "FIELDS", strconv.Itoa(len(fields)))
+		return []resp.RESP{resp.EncodeStringSlice(append(com, pairs...))}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if ok {
+		return resp.RESPInteger{Value: 1}, nil
+	}
+	return resp.RESPInteger{Value: 0}, nil
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	httlCommand         = "HTTL"
+	hpttlCommand        = "HPTTL"
+	hexpiretimeCommand  = "HEXPIRETIME"
+	hpexpiretimeCommand = "HPEXPIRETIME"
+)
+
+func handleHttl(sa []string, _ Context) (resp.RESP, error) {
+	return handleHttlAux(sa, time.Second, false)
+}
+
+func handleHpttl(sa []string, _ Context) (resp.RESP, error) {
+	return handleHttlAux(sa, time.Millisecond, false)
+}
+
+func handleHexpiretime(sa []string, _ Context) (resp.RESP, error) {
+	return handleHttlAux(sa, time.Second, true)
+}
+
+func handleHpexpiretime(sa []string, _ Context) (resp.RESP, error) {
+	return handleHttlAux(sa, time.Millisecond, true)
+}
+
+// handleHttlAux handles the HTTL, HPTTL, HEXPIRETIME and HPEXPIRETIME commands, responding in units of unit,
+// and with the unix time of the expiry rather than the time remaining if absolute is set
+func handleHttlAux(sa []string, unit time.Duration, absolute bool) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	fields, errRes := parseFieldsArgs(sa[2:], 1)
+	if errRes != nil {
+		return errRes, nil
+	}
+	res, err := state.HexpireTimes(sa[1], fields)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	perMs := int64(unit / time.Millisecond)
+	now := time.Now().UnixMilli()
+	for i, ms := range res {
+		if ms < 0 {
+			continue
+		}
+		if absolute {
+			res[i] = ms / perMs
+		} else {
+			// round to the nearest unit, as Redis does
+			res[i] = (max(ms-now, 0) + perMs/2) / perMs
+		}
+	}
+	return encodeIntegerSlice(res), nil
+}
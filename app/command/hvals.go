@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var hvalsCommand = "HVALS"
+
+func handleHvals(sa []string, _ Context) (resp.RESP, error) {
+	return handleHashEntriesAux(sa, 1)
+}
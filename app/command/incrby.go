@@ -0,0 +1,64 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	incrbyCommand = "INCRBY"
+	decrCommand   = "DECR"
+	decrbyCommand = "DECRBY"
+)
+
+func handleIncrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	by, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	return handleIncrbyAux(sa[1], by, ctx)
+}
+
+func handleDecr(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	return handleIncrbyAux(sa[1], -1, ctx)
+}
+
+func handleDecrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	by, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	if by == math.MinInt64 {
+		return &resp.RESPSimpleError{Value: "ERR decrement would overflow"}, nil
+	}
+	return handleIncrbyAux(sa[1], -by, ctx)
+}
+
+// handleIncrbyAux handles the INCR, INCRBY, DECR and DECRBY commands, which all increment the integer at key by some amount.
+// The result is propagated as a SET that retains the expiry of the key.
+func handleIncrbyAux(key string, by int64, ctx Context) (resp.RESP, error) {
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var res int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		res, err = state.Incrby(key, by)
+		return []resp.RESP{resp.EncodeStringSlice([]string{"SET", key, strconv.FormatInt(res, 10), "KEEPTTL"})}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: res}, nil
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var incrbyfloatCommand = "INCRBYFLOAT"
+
+func handleIncrbyfloat(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	key := sa[1]
+	by, err := strconv.ParseFloat(sa[2], 64)
+	if err != nil || math.IsNaN(by) || math.IsInf(by, 0) {
+		return ErrorNotFloat, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var value string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		value, err = state.Incrbyfloat(key, by)
+		// as with HINCRBYFLOAT, the computed value is propagated rather than the increment
+		return []resp.RESP{resp.EncodeStringSlice([]string{"SET", key, value, "KEEPTTL"})}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: value}, nil
+}
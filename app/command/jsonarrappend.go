@@ -0,0 +1,48 @@
+package command
+
+import (
+	"slices"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonArrappendCommand = "JSON.ARRAPPEND"
+
+func handleJsonArrappend(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	path := sa[2]
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var lengths []*int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		lengths, err = state.JsonArrappend(sa[1], path, sa[3:])
+		if !slices.ContainsFunc(lengths, func(n *int64) bool { return n != nil }) {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if state.IsLegacyJSONPath(path) {
+		return resp.RESPInteger{Value: *lengths[len(lengths)-1]}, nil
+	}
+	return encodeOptionalIntegers(lengths), nil
+}
+
+// encodeOptionalIntegers encodes integers, with null for those that are nil
+func encodeOptionalIntegers(ia []*int64) resp.RESP {
+	av := make([]resp.RESP, len(ia))
+	for i, n := range ia {
+		if n == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = resp.RESPInteger{Value: *n}
+		}
+	}
+	return &resp.RESPArray{Value: av}
+}
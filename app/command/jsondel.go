@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonDelCommand = "JSON.DEL"
+
+func handleJsonDel(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	path := "$"
+	if len(sa) == 3 {
+		path = sa[2]
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.JsonDel(sa[1], path)
+		if n == 0 {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonGetCommand = "JSON.GET"
+
+func handleJsonGet(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	var format state.JSONFormat
+	var paths []string
+	for i := 2; i < len(sa); i++ {
+		var opt *string
+		switch strings.ToUpper(sa[i]) {
+		case "INDENT":
+			opt = &format.Indent
+		case "NEWLINE":
+			opt = &format.Newline
+		case "SPACE":
+			opt = &format.Space
+		case "NOESCAPE":
+			continue
+		default:
+			paths = append(paths, sa[i])
+			continue
+		}
+		if i+1 >= len(sa) {
+			return ErrorSyntax, nil
+		}
+		*opt = sa[i+1]
+		i++
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	s, err := state.JsonGet(sa[1], paths, format)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if s == nil {
+		return resp.NullLit, nil
+	}
+	return &resp.RESPBulkString{Value: *s}, nil
+}
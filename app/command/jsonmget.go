@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonMgetCommand = "JSON.MGET"
+
+func handleJsonMget(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	values, err := state.JsonMget(sa[1:len(sa)-1], sa[len(sa)-1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(values))
+	for i, v := range values {
+		if v == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = &resp.RESPBulkString{Value: *v}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
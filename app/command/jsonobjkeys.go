@@ -0,0 +1,37 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonObjkeysCommand = "JSON.OBJKEYS"
+
+func handleJsonObjkeys(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	path := "."
+	if len(sa) == 3 {
+		path = sa[2]
+	}
+	keys, err := state.JsonObjkeys(sa[1], path)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if keys == nil {
+		return resp.NullLit, nil
+	}
+	if state.IsLegacyJSONPath(path) {
+		return resp.EncodeStringSlice(keys[0]), nil
+	}
+	av := make([]resp.RESP, len(keys))
+	for i, k := range keys {
+		if k == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = resp.EncodeStringSlice(k)
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonSetCommand = "JSON.SET"
+
+func handleJsonSet(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 && len(sa) != 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4 or 5-element array"}, nil
+	}
+	nx, xx := false, false
+	if len(sa) == 5 {
+		switch strings.ToUpper(sa[4]) {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var updated bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		updated, err = state.JsonSet(sa[1], sa[2], sa[3], nx, xx)
+		if !updated {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if !updated {
+		return resp.NullLit, nil
+	}
+	return resp.OkLit, nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var jsonTypeCommand = "JSON.TYPE"
+
+func handleJsonType(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	path := "."
+	if len(sa) == 3 {
+		path = sa[2]
+	}
+	types, err := state.JsonType(sa[1], path)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if !state.IsLegacyJSONPath(path) {
+		return resp.EncodeStringSlice(types), nil
+	}
+	if len(types) == 0 {
+		return resp.NullLit, nil
+	}
+	return &resp.RESPSimpleString{Value: types[0]}, nil
+}
@@ -7,10 +7,11 @@ import (
 
 var keysCommand = "KEYS"
 
+// handleKeys replies with the keys matching a glob-style pattern, as with KEYS pattern
 func handleKeys(sa []string, _ Context) (resp.RESP, error) {
-	if len(sa) != 2 || sa[1] != "*" {
-		return &resp.RESPSimpleError{Value: `Unsupported input: only KEYS "*" is supported for the KEYS command`}, nil
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
 	}
-	keys := state.Keys()
+	keys := state.Keys(sa[1])
 	return resp.EncodeStringSlice(keys), nil
 }
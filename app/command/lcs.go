@@ -0,0 +1,79 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lcsCommand = "LCS"
+
+// handleLcs replies with the longest common subsequence of two strings, as with LCS key1 key2 [LEN] [IDX] [MINMATCHLEN len] [WITHMATCHLEN].
+// With LEN it replies with its length instead, and with IDX with its matches from the last to the first, each given by the ranges
+// it spans in both strings and its length with WITHMATCHLEN, leaving out matches shorter than MINMATCHLEN.
+func handleLcs(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	var length, idx, withMatchLen bool
+	var minMatchLen int64
+	for i := 3; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "LEN":
+			length = true
+		case "IDX":
+			idx = true
+		case "WITHMATCHLEN":
+			withMatchLen = true
+		case "MINMATCHLEN":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			n, err := strconv.ParseInt(sa[i+1], 10, 64)
+			if err != nil {
+				return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+			}
+			minMatchLen = max(n, 0)
+			i++
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if length && idx {
+		return &resp.RESPSimpleError{Value: "ERR If you want both the length and indexes, please just use IDX."}, nil
+	}
+	lcs, matches, err := state.Lcs(sa[1], sa[2])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if length {
+		return resp.RESPInteger{Value: int64(len(lcs))}, nil
+	}
+	if !idx {
+		return &resp.RESPBulkString{Value: lcs}, nil
+	}
+	av := []resp.RESP{}
+	for _, m := range matches {
+		if m.Len < minMatchLen {
+			continue
+		}
+		match := []resp.RESP{
+			&resp.RESPArray{Value: []resp.RESP{resp.RESPInteger{Value: m.A[0]}, resp.RESPInteger{Value: m.A[1]}}},
+			&resp.RESPArray{Value: []resp.RESP{resp.RESPInteger{Value: m.B[0]}, resp.RESPInteger{Value: m.B[1]}}},
+		}
+		if withMatchLen {
+			match = append(match, resp.RESPInteger{Value: m.Len})
+		}
+		av = append(av, &resp.RESPArray{Value: match})
+	}
+	res := []resp.RESP{
+		&resp.RESPSimpleString{Value: "matches"}, &resp.RESPArray{Value: av},
+		&resp.RESPSimpleString{Value: "len"}, resp.RESPInteger{Value: int64(len(lcs))},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: res}, nil
+	}
+	return &resp.RESPArray{Value: res}, nil
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lindexCommand = "LINDEX"
+
+func handleLindex(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	key := sa[1]
+	index, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	elem, err := state.Lindex(key, index)
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: elem}, nil
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var linsertCommand = "LINSERT"
+
+func handleLinsert(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 5-element array"}, nil
+	}
+	key, pivot, elem := sa[1], sa[3], sa[4]
+	var before bool
+	switch strings.ToUpper(sa[2]) {
+	case "BEFORE":
+		before = true
+	case "AFTER":
+		before = false
+	default:
+		return ErrorSyntax, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.Linsert(key, before, pivot, elem)
+		if n <= 0 {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
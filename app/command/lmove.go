@@ -0,0 +1,52 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lmoveCommand = "LMOVE"
+
+func handleLmove(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 5-element array"}, nil
+	}
+	src, dst := sa[1], sa[2]
+	srcFront, ok := parseListEnd(sa[3])
+	if !ok {
+		return ErrorSyntax, nil
+	}
+	dstFront, ok := parseListEnd(sa[4])
+	if !ok {
+		return ErrorSyntax, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var elem string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		elem, served, err = state.Lmove(src, dst, srcFront, dstFront)
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		if err == state.ErrorNone {
+			return resp.NullLit, nil
+		}
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return &resp.RESPBulkString{Value: elem}, nil
+}
+
+// parseListEnd parses LEFT or RIGHT, returning true for LEFT
+func parseListEnd(s string) (bool, bool) {
+	switch strings.ToUpper(s) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
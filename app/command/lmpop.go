@@ -0,0 +1,63 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lmpopCommand = "LMPOP"
+
+func handleLmpop(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	return handleLmpopAux(sa[1:], ctx, 0, false)
+}
+
+// handleLmpopAux handles the LMPOP and BLMPOP commands, given the arguments following the command name (and timeout, for BLMPOP)
+func handleLmpopAux(args []string, ctx Context, timeout time.Duration, block bool) (resp.RESP, error) {
+	numkeys, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	if numkeys <= 0 {
+		return &resp.RESPSimpleError{Value: "ERR numkeys should be greater than 0"}, nil
+	}
+	if int64(len(args)) < numkeys+2 {
+		return ErrorSyntax, nil
+	}
+	keys := args[1 : numkeys+1]
+	front, ok := parseListEnd(args[numkeys+1])
+	if !ok {
+		return ErrorSyntax, nil
+	}
+	var count int64 = 1
+	switch rest := args[numkeys+2:]; len(rest) {
+	case 0:
+	case 2:
+		if strings.ToUpper(rest[0]) != "COUNT" {
+			return ErrorSyntax, nil
+		}
+		count, err = strconv.ParseInt(rest[1], 10, 64)
+		if err != nil || count <= 0 {
+			return &resp.RESPSimpleError{Value: "ERR count should be greater than 0"}, nil
+		}
+	default:
+		return ErrorSyntax, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	res, err := state.Blmpop(keys, front, count, timeout, block && !ctx.InTransaction)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if res == nil {
+		return resp.NullArrayLit, nil
+	}
+	return res, nil
+}
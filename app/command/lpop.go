@@ -0,0 +1,54 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lpopCommand = "LPOP"
+
+func handleLpop(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePopAux(sa, ctx, true)
+}
+
+// handlePopAux handles the LPOP and RPOP commands
+func handlePopAux(sa []string, ctx Context, front bool) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	key := sa[1]
+	var count int64 = 1
+	if len(sa) == 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil || count < 0 {
+			return &resp.RESPSimpleError{Value: "ERR value is out of range, must be positive"}, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var elems []string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		elems, err = state.Pop(key, front, count)
+		if len(elems) == 0 {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		if err == state.ErrorNone {
+			if len(sa) == 3 {
+				return resp.NullArrayLit, nil
+			}
+			return resp.NullLit, nil
+		}
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if len(sa) == 3 {
+		return resp.EncodeStringSlice(elems), nil
+	}
+	return &resp.RESPBulkString{Value: elems[0]}, nil
+}
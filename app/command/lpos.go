@@ -0,0 +1,61 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var lposCommand = "LPOS"
+
+func handleLpos(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 || len(sa)%2 != 1 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected an odd, at least 3-element array"}, nil
+	}
+	key, elem := sa[1], sa[2]
+	var rank, count, maxlen int64 = 1, 1, 0
+	withCount := false
+	for i := 3; i < len(sa); i += 2 {
+		n, err := strconv.ParseInt(sa[i+1], 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+		}
+		switch strings.ToUpper(sa[i]) {
+		case "RANK":
+			if n == 0 {
+				return &resp.RESPSimpleError{Value: "ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list"}, nil
+			}
+			rank = n
+		case "COUNT":
+			if n < 0 {
+				return &resp.RESPSimpleError{Value: "ERR COUNT can't be negative"}, nil
+			}
+			count = n
+			withCount = true
+		case "MAXLEN":
+			if n < 0 {
+				return &resp.RESPSimpleError{Value: "ERR MAXLEN can't be negative"}, nil
+			}
+			maxlen = n
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	pos, err := state.Lpos(key, elem, rank, count, maxlen)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if !withCount {
+		if len(pos) == 0 {
+			return resp.NullLit, nil
+		}
+		return resp.RESPInteger{Value: pos[0]}, nil
+	}
+	res := make([]resp.RESP, len(pos))
+	for i, p := range pos {
+		res[i] = resp.RESPInteger{Value: p}
+	}
+	return &resp.RESPArray{Value: res}, nil
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	lpushCommand  = "LPUSH"
+	lpushxCommand = "LPUSHX"
+)
+
+func handleLpush(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePushAux(sa, ctx, true, false)
+}
+
+func handleLpushx(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePushAux(sa, ctx, true, true)
+}
+
+// handlePushAux handles the LPUSH, LPUSHX, RPUSH and RPUSHX commands
+func handlePushAux(sa []string, ctx Context, front, onlyIfExists bool) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	key := sa[1]
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		n, served, err = state.Push(key, sa[2:], front, onlyIfExists)
+		if n == 0 {
+			return nil, err
+		}
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
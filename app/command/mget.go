@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var mgetCommand = "MGET"
+
+func handleMget(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	values := state.Mget(sa[1:])
+	av := make([]resp.RESP, len(values))
+	for i, v := range values {
+		av[i] = resp.NullLit
+		if v != nil {
+			av[i] = &resp.RESPBulkString{Value: *v}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	msetCommand   = "MSET"
+	msetnxCommand = "MSETNX"
+)
+
+func handleMset(sa []string, ctx Context) (resp.RESP, error) {
+	return handleMsetAux(sa, ctx, false)
+}
+
+func handleMsetnx(sa []string, ctx Context) (resp.RESP, error) {
+	return handleMsetAux(sa, ctx, true)
+}
+
+// handleMsetAux handles the MSET and MSETNX commands. MSETNX sets none of the keys if any of them exists, and replies with whether it set them.
+func handleMsetAux(sa []string, ctx Context, onlyIfNew bool) (resp.RESP, error) {
+	if len(sa) < 3 || len(sa)%2 != 1 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected an odd, at least 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	set := true
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		if !onlyIfNew {
+			state.Mset(sa[1:])
+		} else if set = state.Msetnx(sa[1:]); !set {
+			return nil, nil
+		}
+		return []resp.RESP{ctx.Com}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if !onlyIfNew {
+		return resp.OkLit, nil
+	}
+	return encodeBool(set), nil
+}
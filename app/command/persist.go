@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var persistCommand = "PERSIST"
+
+func handlePersist(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var persisted bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		if persisted = state.Persist(sa[1]); !persisted {
+			return nil, nil
+		}
+		return []resp.RESP{ctx.Com}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(persisted), nil
+}
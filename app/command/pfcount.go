@@ -0,0 +1,37 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var pfcountCommand = "PFCOUNT"
+
+// handlePfcount caches the cardinality of a single HyperLogLog, propagating PFCOUNT so that replicas store the same value.
+// Replicas serving clients do not update the cache.
+func handlePfcount(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	keys := sa[1:]
+	cache := len(keys) == 1 && (!ctx.IsReplica || ctx.IsReplConn)
+	var n int64
+	var err error
+	if cache {
+		err = state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+			var updated bool
+			var err error
+			n, updated, err = state.Pfcount(keys, true)
+			if !updated && !ctx.IsReplConn {
+				return nil, err
+			}
+			return []resp.RESP{ctx.Com}, err
+		})
+	} else {
+		n, _, err = state.Pfcount(keys, false)
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var pfdebugCommand = "PFDEBUG"
+
+var pfdebugCommandHandlers = map[string]subhandler{
+	"GETREG":   handlePfdebugGetreg,
+	"ENCODING": handlePfdebugEncoding,
+	"DECODE":   handlePfdebugDecode,
+	"TODENSE":  handlePfdebugTodense,
+}
+
+func handlePfdebugCommands(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	handler, ok := pfdebugCommandHandlers[strings.ToUpper(sa[1])]
+	if !ok {
+		return &resp.RESPSimpleError{Value: "ERR Unknown PFDEBUG subcommand '" + sa[1] + "'"}, nil
+	}
+	return handler(sa, ctx)
+}
+
+// pfdebugError encodes an error of a PFDEBUG subcommand
+func pfdebugError(err error) resp.RESP {
+	if err == state.ErrorNone {
+		return &resp.RESPSimpleError{Value: "ERR The specified key does not exist"}
+	}
+	return &resp.RESPSimpleError{Value: err.Error()}
+}
+
+func handlePfdebugGetreg(sa []string, _ Context) (resp.RESP, error) {
+	registers, err := state.PfdebugGetreg(sa[2])
+	if err != nil {
+		return pfdebugError(err), nil
+	}
+	return encodeIntegerSlice(registers), nil
+}
+
+func handlePfdebugEncoding(sa []string, _ Context) (resp.RESP, error) {
+	encoding, err := state.PfdebugEncoding(sa[2])
+	if err != nil {
+		return pfdebugError(err), nil
+	}
+	return &resp.RESPSimpleString{Value: encoding}, nil
+}
+
+func handlePfdebugDecode(sa []string, _ Context) (resp.RESP, error) {
+	decoded, err := state.PfdebugDecode(sa[2])
+	if err != nil {
+		return pfdebugError(err), nil
+	}
+	return &resp.RESPBulkString{Value: decoded}, nil
+}
+
+func handlePfdebugTodense(sa []string, ctx Context) (resp.RESP, error) {
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var converted bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		converted, err = state.PfdebugTodense(sa[2])
+		if !converted && !ctx.IsReplConn {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return pfdebugError(err), nil
+	}
+	return encodeBool(converted), nil
+}
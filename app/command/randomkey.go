@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var randomkeyCommand = "RANDOMKEY"
+
+func handleRandomkey(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 1 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 1-element array"}, nil
+	}
+	key, ok := state.Randomkey()
+	if !ok {
+		return resp.NullLit, nil
+	}
+	return &resp.RESPBulkString{Value: key}, nil
+}
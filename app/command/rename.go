@@ -0,0 +1,44 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	renameCommand   = "RENAME"
+	renamenxCommand = "RENAMENX"
+)
+
+func handleRename(sa []string, ctx Context) (resp.RESP, error) {
+	return handleRenameAux(sa, ctx, false)
+}
+
+func handleRenamenx(sa []string, ctx Context) (resp.RESP, error) {
+	return handleRenameAux(sa, ctx, true)
+}
+
+// handleRenameAux handles the RENAME and RENAMENX commands. RENAMENX does not replace an existing key, and replies with whether it renamed the key.
+func handleRenameAux(sa []string, ctx Context, onlyIfNew bool) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var renamed bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		if renamed, served, err = state.Rename(sa[1], sa[2], onlyIfNew); err != nil || !renamed {
+			return nil, err
+		}
+		return append([]resp.RESP{ctx.Com}, served...), nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if onlyIfNew {
+		return encodeBool(renamed), nil
+	}
+	return resp.OkLit, nil
+}
@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var rpopCommand = "RPOP"
+
+func handleRpop(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePopAux(sa, ctx, false)
+}
@@ -0,0 +1,18 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var (
+	rpushCommand  = "RPUSH"
+	rpushxCommand = "RPUSHX"
+)
+
+func handleRpush(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePushAux(sa, ctx, false, false)
+}
+
+func handleRpushx(sa []string, ctx Context) (resp.RESP, error) {
+	return handlePushAux(sa, ctx, false, true)
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var scanCommand = "SCAN"
+
+// scanDefaultCount is the number of elements SCAN and its variants aim for without COUNT
+const scanDefaultCount = 10
+
+// handleScan walks the keyspace, as with SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]. It replies with the cursor to continue from,
+// which is 0 once the walk is complete, followed by the keys visited.
+func handleScan(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	cursor, opts, errRes := parseScanArgs(sa[0], sa[1:])
+	if errRes != nil {
+		return errRes, nil
+	}
+	cursor, keys := state.Scan(cursor, opts)
+	return encodeScanReply(cursor, resp.EncodeStringSlice(keys)), nil
+}
+
+// parseScanArgs parses the cursor and options of SCAN, HSCAN, SSCAN and ZSCAN, of which only SCAN accepts TYPE and only HSCAN accepts NOVALUES
+func parseScanArgs(command string, args []string) (uint64, state.ScanOptions, resp.RESP) {
+	opts := state.ScanOptions{Count: scanDefaultCount}
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, opts, &resp.RESPSimpleError{Value: "ERR invalid cursor"}
+	}
+	command = strings.ToUpper(command)
+	for i := 1; i < len(args); i++ {
+		opt := strings.ToUpper(args[i])
+		if opt == "NOVALUES" && command == "HSCAN" {
+			opts.NoValues = true
+			continue
+		}
+		if i+1 >= len(args) {
+			return 0, opts, ErrorSyntax
+		}
+		switch {
+		case opt == "MATCH":
+			opts.Match = args[i+1]
+			if opts.Match == "*" {
+				opts.Match = ""
+			}
+		case opt == "COUNT":
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil {
+				return 0, opts, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+			}
+			if n < 1 {
+				return 0, opts, ErrorSyntax
+			}
+			opts.Count = n
+		case opt == "TYPE" && command == "SCAN":
+			opts.Type = args[i+1]
+		default:
+			return 0, opts, ErrorSyntax
+		}
+		i++
+	}
+	return cursor, opts, nil
+}
+
+func encodeScanReply(cursor uint64, elems resp.RESP) resp.RESP {
+	return &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: strconv.FormatUint(cursor, 10)}, elems}}
+}
@@ -0,0 +1,12 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sdiffstoreCommand = "SDIFFSTORE"
+
+func handleSdiffstore(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSetAlgebraStoreAux(sa, ctx, state.SetOpDiff)
+}
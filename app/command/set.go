@@ -1,7 +1,6 @@
 package command
 
 import (
-	"strconv"
 	"strings"
 
 	"github.com/codecrafters-io/redis-starter-go/app/resp"
@@ -10,39 +9,78 @@ import (
 
 var setCommand = "SET"
 
+// handleSet sets a key to a string, as with SET key value [NX | XX | IFEQ comparison-value] [GET] [EX seconds | PX milliseconds |
+// EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]. It replies with OK, or nil if the condition was not met,
+// or with GET, the string previously at the key.
+// The command is propagated as a plain SET with the absolute expiry, or KEEPTTL, so that replicas need not evaluate its condition.
 func handleSet(sa []string, ctx Context) (resp.RESP, error) {
-	var (
-		key   string
-		value string
-		px    int64
-		err   error
-	)
-	switch len(sa) {
-	case 3:
-		key = sa[1]
-		value = sa[2]
-		px = -1
-	case 5:
-		if strings.ToUpper(sa[3]) != "PX" {
-			return &resp.RESPSimpleError{Value: "Invalid input: expected PX as 4th element"}, nil
-		}
-		key = sa[1]
-		value = sa[2]
-		px, err = strconv.ParseInt(sa[4], 10, 64)
-		if err != nil {
-			return &resp.RESPSimpleError{Value: "Invalid input: expected integer as 5th element"}, nil
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	key, value := sa[1], sa[2]
+	var opts state.SetOptions
+	expire := false
+	for i := 3; i < len(sa); i++ {
+		opt := strings.ToUpper(sa[i])
+		switch opt {
+		case "NX", "XX":
+			if opts.Cond != "" {
+				return ErrorSyntax, nil
+			}
+			opts.Cond = opt
+		case "IFEQ":
+			if opts.Cond != "" || i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			opts.Cond, opts.IfEq = opt, sa[i+1]
+			i++
+		case "GET":
+			opts.Get = true
+		case "KEEPTTL":
+			if expire || opts.KeepTTL {
+				return ErrorSyntax, nil
+			}
+			opts.KeepTTL = true
+		default:
+			if expire || opts.KeepTTL || i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			at, ok, errRes := parseExpiryOption(sa[0], opt, sa[i+1])
+			if !ok {
+				return ErrorSyntax, nil
+			}
+			if errRes != nil {
+				return errRes, nil
+			}
+			opts.At, expire = at, true
+			i++
 		}
-	default:
-		return &resp.RESPSimpleError{Value: "Invalid input: expected 3 or 5-element array"}, nil
 	}
 	if ctx.IsReplica && !ctx.IsReplConn {
 		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
 	}
-	coms := []resp.RESP{ctx.Com}
+	var prev *string
+	var set bool
 	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
-		return coms, state.Set(key, value, px)
+		var err error
+		if prev, set, err = state.Set(key, value, opts); err != nil || !set {
+			return nil, err
+		}
+		if opts.KeepTTL {
+			return []resp.RESP{resp.EncodeStringSlice([]string{"SET", key, value, "KEEPTTL"})}, nil
+		}
+		return []resp.RESP{encodeSetAt(key, value, opts.At)}, nil
 	}); err != nil {
-		return nil, err
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if opts.Get {
+		if prev == nil {
+			return resp.NullLit, nil
+		}
+		return &resp.RESPBulkString{Value: *prev}, nil
+	}
+	if !set {
+		return resp.NullLit, nil
 	}
 	return resp.OkLit, nil
 }
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var setbitCommand = "SETBIT"
+
+func handleSetbit(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	key := sa[1]
+	offset, errRes := parseBitOffset(sa[2])
+	if errRes != nil {
+		return errRes, nil
+	}
+	if sa[3] != "0" && sa[3] != "1" {
+		return &resp.RESPSimpleError{Value: "ERR bit is not an integer or out of range"}, nil
+	}
+	bit := sa[3][0] - '0'
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var prev byte
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		prev, err = state.Setbit(key, offset, bit)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: int64(prev)}, nil
+}
+
+// parseBitOffset parses the bit offset of SETBIT and GETBIT
+func parseBitOffset(s string) (int64, resp.RESP) {
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 || offset > state.BitMaxOffset {
+		return 0, &resp.RESPSimpleError{Value: "ERR bit offset is not an integer or out of range"}
+	}
+	return offset, nil
+}
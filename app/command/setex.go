@@ -0,0 +1,57 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	setexCommand  = "SETEX"
+	psetexCommand = "PSETEX"
+)
+
+func handleSetex(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSetexAux(sa, ctx, time.Second)
+}
+
+func handlePsetex(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSetexAux(sa, ctx, time.Millisecond)
+}
+
+// handleSetexAux handles the SETEX and PSETEX commands, which set a key to expire after a number of the given unit.
+// The command is propagated as a SET with the absolute expiry, so that replicas expire the key at the same time.
+func handleSetexAux(sa []string, ctx Context, unit time.Duration) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	n, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	at, ok := expiryTime(n, unit, false)
+	if n <= 0 || !ok {
+		return &resp.RESPSimpleError{Value: "ERR invalid expire time in '" + strings.ToLower(sa[0]) + "' command"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		state.SetAt(sa[1], sa[3], at)
+		return []resp.RESP{encodeSetAt(sa[1], sa[3], at)}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
+
+// encodeSetAt encodes a SET of key to value expiring at at, or not expiring if at is zero
+func encodeSetAt(key, value string, at time.Time) resp.RESP {
+	if at.IsZero() {
+		return resp.EncodeStringSlice([]string{"SET", key, value})
+	}
+	return resp.EncodeStringSlice([]string{"SET", key, value, "PXAT", strconv.FormatInt(at.UnixMilli(), 10)})
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var setnxCommand = "SETNX"
+
+func handleSetnx(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var set bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		if set = state.Setnx(sa[1], sa[2]); !set {
+			return nil, nil
+		}
+		return []resp.RESP{ctx.Com}, nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(set), nil
+}
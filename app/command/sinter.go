@@ -0,0 +1,24 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sinterCommand = "SINTER"
+
+func handleSinter(sa []string, _ Context) (resp.RESP, error) {
+	return handleSetAlgebraAux(sa, state.SetOpInter)
+}
+
+// handleSetAlgebraAux handles the SINTER, SUNION and SDIFF commands
+func handleSetAlgebraAux(sa []string, op state.SetOp) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	members, err := state.SetAlgebra(sa[1:], op)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.EncodeStringSlice(members), nil
+}
@@ -0,0 +1,68 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sintercardCommand = "SINTERCARD"
+
+func handleSintercard(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	keys, limit, errRes := parseIntercardArgs(sa[1:])
+	if errRes != nil {
+		return errRes, nil
+	}
+	n, err := state.Sintercard(keys, limit)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
+
+// parseIntercardArgs parses the arguments of SINTERCARD and ZINTERCARD, i.e. numkeys key [key ...] [LIMIT limit]
+func parseIntercardArgs(args []string) ([]string, int64, resp.RESP) {
+	keys, rest, errRes := parseNumkeys(args)
+	if errRes != nil {
+		return nil, 0, errRes
+	}
+	var limit int64
+	switch len(rest) {
+	case 0:
+	case 2:
+		if strings.ToUpper(rest[0]) != "LIMIT" {
+			return nil, 0, ErrorSyntax
+		}
+		var err error
+		limit, err = strconv.ParseInt(rest[1], 10, 64)
+		if err != nil {
+			return nil, 0, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+		}
+		if limit < 0 {
+			return nil, 0, &resp.RESPSimpleError{Value: "ERR LIMIT can't be negative"}
+		}
+	default:
+		return nil, 0, ErrorSyntax
+	}
+	return keys, limit, nil
+}
+
+// parseNumkeys parses a numkeys argument followed by that many keys, returning the keys and the arguments following them
+func parseNumkeys(args []string) ([]string, []string, resp.RESP) {
+	numkeys, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, nil, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+	}
+	if numkeys <= 0 {
+		return nil, nil, &resp.RESPSimpleError{Value: "ERR numkeys should be greater than 0"}
+	}
+	if int64(len(args)-1) < numkeys {
+		return nil, nil, &resp.RESPSimpleError{Value: "ERR Number of keys can't be greater than number of args"}
+	}
+	return args[1 : numkeys+1], args[numkeys+1:], nil
+}
@@ -0,0 +1,32 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sinterstoreCommand = "SINTERSTORE"
+
+func handleSinterstore(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSetAlgebraStoreAux(sa, ctx, state.SetOpInter)
+}
+
+// handleSetAlgebraStoreAux handles the SINTERSTORE, SUNIONSTORE and SDIFFSTORE commands
+func handleSetAlgebraStoreAux(sa []string, ctx Context, op state.SetOp) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	dst := sa[1]
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.SetAlgebraStore(dst, sa[2:], op)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sismemberCommand = "SISMEMBER"
+
+func handleSismember(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	res, err := state.Smismember(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(res[0]), nil
+}
+
+// encodeBool encodes b as an integer reply, as Redis replies to predicates
+func encodeBool(b bool) resp.RESP {
+	if b {
+		return resp.RESPInteger{Value: 1}
+	}
+	return resp.RESPInteger{Value: 0}
+}
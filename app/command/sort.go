@@ -0,0 +1,107 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	sortCommand   = "SORT"
+	sortRoCommand = "SORT_RO"
+)
+
+func handleSort(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSortAux(sa, ctx, false)
+}
+
+func handleSortRo(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSortAux(sa, ctx, true)
+}
+
+// handleSortAux handles the SORT and SORT_RO commands, as with SORT key [BY pattern] [LIMIT offset count] [GET pattern...] [ASC|DESC] [ALPHA] [STORE destination].
+// SORT_RO does not accept STORE. With STORE, it replies with the number of values stored, and the command is propagated as the list write
+// that it amounts to, as the values stored depend on other keys.
+func handleSortAux(sa []string, ctx Context, readOnly bool) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	opts := state.SortOptions{Count: -1}
+	var dst *string
+	for i := 2; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "ASC":
+			opts.Desc = false
+		case "DESC":
+			opts.Desc = true
+		case "ALPHA":
+			opts.Alpha = true
+		case "LIMIT":
+			if i+2 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			offset, err1 := strconv.ParseInt(sa[i+1], 10, 64)
+			count, err2 := strconv.ParseInt(sa[i+2], 10, 64)
+			if err1 != nil || err2 != nil {
+				return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+			}
+			opts.Offset, opts.Count = offset, count
+			i += 2
+		case "BY":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			opts.By = sa[i+1]
+			i++
+		case "GET":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			opts.Get = append(opts.Get, sa[i+1])
+			i++
+		case "STORE":
+			if readOnly || i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			dst = &sa[i+1]
+			i++
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if dst == nil {
+		values, err := state.Sort(sa[1], opts)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: err.Error()}, nil
+		}
+		av := make([]resp.RESP, len(values))
+		for i, v := range values {
+			av[i] = resp.NullLit
+			if v != nil {
+				av[i] = &resp.RESPBulkString{Value: *v}
+			}
+		}
+		return &resp.RESPArray{Value: av}, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		values, served, err := state.SortStore(sa[1], *dst, opts)
+		if err != nil {
+			return nil, err
+		}
+		n = int64(len(values))
+		cmds := []resp.RESP{resp.EncodeStringSlice([]string{"DEL", *dst})}
+		if n > 0 {
+			cmds = append(cmds, resp.EncodeStringSlice(append([]string{"RPUSH", *dst}, values...)))
+		}
+		return append(cmds, served...), nil
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
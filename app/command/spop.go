@@ -0,0 +1,50 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var spopCommand = "SPOP"
+
+func handleSpop(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	key := sa[1]
+	var count int64 = 1
+	if len(sa) == 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil || count < 0 {
+			return &resp.RESPSimpleError{Value: "ERR value is out of range, must be positive"}, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var members []string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		members, err = state.Spop(key, count)
+		if len(members) == 0 {
+			return nil, err
+		}
+		// the members popped are chosen at random, so the concrete members are propagated instead
+		return []resp.RESP{resp.EncodeStringSlice(append([]string{"SREM", key}, members...))}, err
+	}); err != nil {
+		if err == state.ErrorNone {
+			if len(sa) == 3 {
+				return resp.EncodeStringSlice([]string{}), nil
+			}
+			return resp.NullLit, nil
+		}
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if len(sa) == 3 {
+		return resp.EncodeStringSlice(members), nil
+	}
+	return &resp.RESPBulkString{Value: members[0]}, nil
+}
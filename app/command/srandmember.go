@@ -0,0 +1,42 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var srandmemberCommand = "SRANDMEMBER"
+
+func handleSrandmember(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	var count int64 = 1
+	if len(sa) == 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+		}
+		if count < -math.MaxInt64 {
+			return ErrorOutOfRange, nil
+		}
+	}
+	members, err := state.Srandmember(sa[1], count)
+	if err == state.ErrorNone {
+		if len(sa) == 3 {
+			return resp.EncodeStringSlice([]string{}), nil
+		}
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if len(sa) == 3 {
+		return resp.EncodeStringSlice(members), nil
+	}
+	return &resp.RESPBulkString{Value: members[0]}, nil
+}
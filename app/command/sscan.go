@@ -0,0 +1,25 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sscanCommand = "SSCAN"
+
+// handleSscan walks a set, as with SSCAN key cursor [MATCH pattern] [COUNT count]. It replies with the cursor to continue from,
+// followed by the members visited.
+func handleSscan(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	cursor, opts, errRes := parseScanArgs(sa[0], sa[2:])
+	if errRes != nil {
+		return errRes, nil
+	}
+	cursor, members, err := state.Sscan(sa[1], cursor, opts)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeScanReply(cursor, resp.EncodeStringSlice(members)), nil
+}
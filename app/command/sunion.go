@@ -0,0 +1,12 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sunionCommand = "SUNION"
+
+func handleSunion(sa []string, _ Context) (resp.RESP, error) {
+	return handleSetAlgebraAux(sa, state.SetOpUnion)
+}
@@ -0,0 +1,12 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var sunionstoreCommand = "SUNIONSTORE"
+
+func handleSunionstore(sa []string, ctx Context) (resp.RESP, error) {
+	return handleSetAlgebraStoreAux(sa, ctx, state.SetOpUnion)
+}
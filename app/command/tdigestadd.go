@@ -0,0 +1,48 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestAddCommand = "TDIGEST.ADD"
+
+func handleTdigestAdd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	values, errResp := parseTdigestValues(sa[2:], "T-Digest: error parsing val parameter")
+	if errResp != nil {
+		return errResp, nil
+	}
+	for _, x := range values {
+		if math.IsInf(x, 0) {
+			return &resp.RESPSimpleError{Value: "T-Digest: error parsing val parameter"}, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TdigestAdd(sa[1], values)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
+
+// parseTdigestValues parses the floats sa, replying with errMsg if any is not a number
+func parseTdigestValues(sa []string, errMsg string) ([]float64, resp.RESP) {
+	values := make([]float64, len(sa))
+	for i, s := range sa {
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(x) {
+			return nil, &resp.RESPSimpleError{Value: errMsg}
+		}
+		values[i] = x
+	}
+	return values, nil
+}
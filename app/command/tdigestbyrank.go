@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	tdigestByrankCommand    = "TDIGEST.BYRANK"
+	tdigestByrevrankCommand = "TDIGEST.BYREVRANK"
+)
+
+func handleTdigestByrank(sa []string, ctx Context) (resp.RESP, error) {
+	return tdigestByrankAux(sa, false, ctx)
+}
+
+func handleTdigestByrevrank(sa []string, ctx Context) (resp.RESP, error) {
+	return tdigestByrankAux(sa, true, ctx)
+}
+
+func tdigestByrankAux(sa []string, rev bool, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	ranks := make([]int64, len(sa)-2)
+	for i, s := range sa[2:] {
+		r, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: "T-Digest: error parsing rank"}, nil
+		}
+		if r < 0 {
+			return &resp.RESPSimpleError{Value: "T-Digest: rank needs to be non negative"}, nil
+		}
+		ranks[i] = r
+	}
+	values, err := state.TdigestByrank(sa[1], ranks, rev)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeScores(values, ctx), nil
+}
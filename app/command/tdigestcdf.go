@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestCdfCommand = "TDIGEST.CDF"
+
+func handleTdigestCdf(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	values, errResp := parseTdigestValues(sa[2:], "T-Digest: error parsing cdf")
+	if errResp != nil {
+		return errResp, nil
+	}
+	fractions, err := state.TdigestCdf(sa[1], values)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeScores(fractions, ctx), nil
+}
@@ -0,0 +1,48 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestCreateCommand = "TDIGEST.CREATE"
+
+func handleTdigestCreate(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 4-element array"}, nil
+	}
+	compression := int64(state.TDigestDefaultCompression)
+	if len(sa) == 4 {
+		if strings.ToUpper(sa[2]) != "COMPRESSION" {
+			return ErrorSyntax, nil
+		}
+		var errResp resp.RESP
+		if compression, errResp = parseTdigestCompression(sa[3]); errResp != nil {
+			return errResp, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TdigestCreate(sa[1], compression)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
+
+func parseTdigestCompression(s string) (int64, resp.RESP) {
+	compression, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || compression > math.MaxInt32 {
+		return 0, &resp.RESPSimpleError{Value: "T-Digest: error parsing compression parameter"}
+	}
+	if compression < 1 {
+		return 0, &resp.RESPSimpleError{Value: "T-Digest: compression parameter needs to be a positive integer"}
+	}
+	return compression, nil
+}
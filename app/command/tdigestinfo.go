@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestInfoCommand = "TDIGEST.INFO"
+
+func handleTdigestInfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	info, err := state.TdigestInfo(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := []resp.RESP{
+		&resp.RESPSimpleString{Value: "Compression"}, resp.RESPInteger{Value: info.Compression},
+		&resp.RESPSimpleString{Value: "Capacity"}, resp.RESPInteger{Value: info.Capacity},
+		&resp.RESPSimpleString{Value: "Merged nodes"}, resp.RESPInteger{Value: info.MergedNodes},
+		&resp.RESPSimpleString{Value: "Unmerged nodes"}, resp.RESPInteger{Value: info.UnmergedNodes},
+		&resp.RESPSimpleString{Value: "Merged weight"}, resp.RESPInteger{Value: info.MergedWeight},
+		&resp.RESPSimpleString{Value: "Unmerged weight"}, resp.RESPInteger{Value: info.UnmergedWeight},
+		&resp.RESPSimpleString{Value: "Observations"}, resp.RESPInteger{Value: info.Observations},
+		&resp.RESPSimpleString{Value: "Total compressions"}, resp.RESPInteger{Value: info.Compressions},
+		&resp.RESPSimpleString{Value: "Memory usage"}, resp.RESPInteger{Value: info.MemoryUsage},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestMergeCommand = "TDIGEST.MERGE"
+
+func handleTdigestMerge(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	numKeys, err := strconv.Atoi(sa[2])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: "T-Digest: error parsing numkeys"}, nil
+	}
+	if numKeys <= 0 || numKeys > len(sa)-3 {
+		return &resp.RESPSimpleError{Value: "T-Digest: numkeys needs to be a positive integer"}, nil
+	}
+	var compression int64
+	override := false
+	for i := 3 + numKeys; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "COMPRESSION":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			var errResp resp.RESP
+			if compression, errResp = parseTdigestCompression(sa[i+1]); errResp != nil {
+				return errResp, nil
+			}
+			i++
+		case "OVERRIDE":
+			override = true
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TdigestMerge(sa[1], sa[3:3+numKeys], compression, override)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
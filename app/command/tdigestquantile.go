@@ -0,0 +1,36 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestQuantileCommand = "TDIGEST.QUANTILE"
+
+func handleTdigestQuantile(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	quantiles, errResp := parseTdigestValues(sa[2:], "T-Digest: error parsing quantile")
+	if errResp != nil {
+		return errResp, nil
+	}
+	for _, q := range quantiles {
+		if q < 0 || q > 1 {
+			return &resp.RESPSimpleError{Value: "T-Digest: quantile should be in [0,1]"}, nil
+		}
+	}
+	values, err := state.TdigestQuantile(sa[1], quantiles)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeScores(values, ctx), nil
+}
+
+func encodeScores(fs []float64, ctx Context) resp.RESP {
+	av := make([]resp.RESP, len(fs))
+	for i, f := range fs {
+		av[i] = encodeScore(f, ctx)
+	}
+	return &resp.RESPArray{Value: av}
+}
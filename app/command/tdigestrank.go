@@ -0,0 +1,34 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	tdigestRankCommand    = "TDIGEST.RANK"
+	tdigestRevrankCommand = "TDIGEST.REVRANK"
+)
+
+func handleTdigestRank(sa []string, _ Context) (resp.RESP, error) {
+	return tdigestRankAux(sa, false)
+}
+
+func handleTdigestRevrank(sa []string, _ Context) (resp.RESP, error) {
+	return tdigestRankAux(sa, true)
+}
+
+func tdigestRankAux(sa []string, rev bool) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	values, errResp := parseTdigestValues(sa[2:], "T-Digest: error parsing value")
+	if errResp != nil {
+		return errResp, nil
+	}
+	ranks, err := state.TdigestRank(sa[1], values, rev)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeIntegerSlice(ranks), nil
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tdigestTrimmedMeanCommand = "TDIGEST.TRIMMED_MEAN"
+
+func handleTdigestTrimmedMean(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	low, errResp := parseTdigestValues(sa[2:3], "T-Digest: error parsing low_cut_percentile")
+	if errResp != nil {
+		return errResp, nil
+	}
+	high, errResp := parseTdigestValues(sa[3:4], "T-Digest: error parsing high_cut_percentile")
+	if errResp != nil {
+		return errResp, nil
+	}
+	if low[0] < 0 || low[0] > 1 || high[0] < 0 || high[0] > 1 {
+		return &resp.RESPSimpleError{Value: "T-Digest: low_cut_percentile and high_cut_percentile should be in [0,1]"}, nil
+	}
+	if low[0] >= high[0] {
+		return &resp.RESPSimpleError{Value: "T-Digest: low_cut_percentile should be lower than high_cut_percentile"}, nil
+	}
+	mean, err := state.TdigestTrimmedMean(sa[1], low[0], high[0])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeScore(mean, ctx), nil
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var topkAddCommand = "TOPK.ADD"
+
+func handleTopkAdd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	items := sa[2:]
+	increments := make([]int64, len(items))
+	for i := range increments {
+		increments[i] = 1
+	}
+	return topkIncrbyAux(sa[1], items, increments, ctx)
+}
+
+// topkIncrbyAux increments items for TOPK.ADD and TOPK.INCRBY, replying with the item expelled from the top k by each one, or nil.
+// The decay of counts is pseudo-random but deterministic, so replicas get the same result from the command.
+func topkIncrbyAux(key string, items []string, increments []int64, ctx Context) (resp.RESP, error) {
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var expelled []*string
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		expelled, err = state.TopkIncrby(key, items, increments)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(expelled))
+	for i, item := range expelled {
+		if item == nil {
+			av[i] = resp.NullLit
+		} else {
+			av[i] = &resp.RESPBulkString{Value: *item}
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
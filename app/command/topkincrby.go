@@ -0,0 +1,30 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var topkIncrbyCommand = "TOPK.INCRBY"
+
+// topkMaxIncrement bounds increments, since each one may decay a colliding count once per unit
+const topkMaxIncrement = 100000
+
+func handleTopkIncrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 || len(sa)%2 != 0 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected an even, at least 4-element array"}, nil
+	}
+	n := (len(sa) - 2) / 2
+	items := make([]string, n)
+	increments := make([]int64, n)
+	for i := range n {
+		items[i] = sa[2+2*i]
+		incr, err := strconv.ParseInt(sa[3+2*i], 10, 64)
+		if err != nil || incr < 1 || incr > topkMaxIncrement {
+			return &resp.RESPSimpleError{Value: "TopK: increment must be an integer greater or equal to 1 and less than or equal to 100000"}, nil
+		}
+		increments[i] = incr
+	}
+	return topkIncrbyAux(sa[1], items, increments, ctx)
+}
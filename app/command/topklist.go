@@ -0,0 +1,32 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var topkListCommand = "TOPK.LIST"
+
+func handleTopkList(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	withCount := len(sa) == 3
+	if withCount && strings.ToUpper(sa[2]) != "WITHCOUNT" {
+		return ErrorSyntax, nil
+	}
+	items, err := state.TopkList(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, 0, 2*len(items))
+	for _, it := range items {
+		av = append(av, &resp.RESPBulkString{Value: it.Item})
+		if withCount {
+			av = append(av, resp.RESPInteger{Value: it.Count})
+		}
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
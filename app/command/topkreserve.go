@@ -0,0 +1,44 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var topkReserveCommand = "TOPK.RESERVE"
+
+func handleTopkReserve(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 && len(sa) != 6 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3 or 6-element array"}, nil
+	}
+	k, err := strconv.ParseInt(sa[2], 10, 64)
+	if err != nil || k < 1 {
+		return &resp.RESPSimpleError{Value: "TopK: invalid k"}, nil
+	}
+	width, depth, decay := int64(state.TopKDefaultWidth), int64(state.TopKDefaultDepth), state.TopKDefaultDecay
+	if len(sa) == 6 {
+		width, err = strconv.ParseInt(sa[3], 10, 64)
+		if err != nil || width < 1 {
+			return &resp.RESPSimpleError{Value: "TopK: invalid width"}, nil
+		}
+		depth, err = strconv.ParseInt(sa[4], 10, 64)
+		if err != nil || depth < 1 {
+			return &resp.RESPSimpleError{Value: "TopK: invalid depth"}, nil
+		}
+		decay, err = strconv.ParseFloat(sa[5], 64)
+		if err != nil || !(decay > 0 && decay <= 1) {
+			return &resp.RESPSimpleError{Value: "TopK: invalid decay value. must be '<= 1' & '> 0'"}, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TopkReserve(sa[1], k, width, depth, decay)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
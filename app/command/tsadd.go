@@ -0,0 +1,58 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsAddCommand = "TS.ADD"
+
+// handleTsAdd propagates the command with the current time in place of a "*" timestamp, so that replicas add the same sample
+func handleTsAdd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	s, errResp := parseTsSample(sa[2], sa[3])
+	if errResp != nil {
+		return errResp, nil
+	}
+	opts, policy, errResp := parseTsOptions(sa[4:], true)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	com := append([]string{}, sa...)
+	com[2] = strconv.FormatInt(s.Timestamp, 10)
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{resp.EncodeStringSlice(com)}, state.TsAdd(sa[1], s, policy, &opts)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: s.Timestamp}, nil
+}
+
+// parseTsSample parses a sample, where the timestamp "*" stands for the current time
+func parseTsSample(timestamp, value string) (state.TSSample, resp.RESP) {
+	var s state.TSSample
+	if timestamp == "*" {
+		s.Timestamp = time.Now().UnixMilli()
+	} else {
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || ts < 0 {
+			return s, &resp.RESPSimpleError{Value: "TSDB: invalid timestamp"}
+		}
+		s.Timestamp = ts
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(v) {
+		return s, &resp.RESPSimpleError{Value: "TSDB: invalid value"}
+	}
+	s.Value = v
+	return s, nil
+}
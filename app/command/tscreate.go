@@ -0,0 +1,103 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsCreateCommand = "TS.CREATE"
+
+func handleTsCreate(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 2-element array"}, nil
+	}
+	opts, _, errResp := parseTsOptions(sa[2:], false)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TsCreate(sa[1], opts)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
+
+// parseTsOptions parses the options of a new series for TS.CREATE and TS.ADD, which also takes the ON_DUPLICATE policy if onDuplicate is set
+func parseTsOptions(sa []string, onDuplicate bool) (state.TSOptions, string, resp.RESP) {
+	opts := state.TSOptions{ChunkSize: state.TSDefaultChunkSize}
+	var policy string
+	for i := 0; i < len(sa); i++ {
+		opt := strings.ToUpper(sa[i])
+		if opt == "LABELS" {
+			rest := sa[i+1:]
+			if len(rest)%2 != 0 {
+				return opts, "", ErrorSyntax
+			}
+			for j := 0; j < len(rest); j += 2 {
+				opts.Labels = append(opts.Labels, state.TSLabel{Name: rest[j], Value: rest[j+1]})
+			}
+			break
+		}
+		if i+1 >= len(sa) {
+			return opts, "", ErrorSyntax
+		}
+		arg := sa[i+1]
+		i++
+		switch opt {
+		case "RETENTION":
+			n, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil || n < 0 {
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: Couldn't parse RETENTION"}
+			}
+			opts.Retention = n
+		case "ENCODING":
+			switch strings.ToUpper(arg) {
+			case "COMPRESSED":
+				opts.Uncompressed = false
+			case "UNCOMPRESSED":
+				opts.Uncompressed = true
+			default:
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: Unknown ENCODING parameter"}
+			}
+		case "CHUNK_SIZE":
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: Couldn't parse CHUNK_SIZE"}
+			}
+			if n < 48 || n > 1048576 || n%8 != 0 {
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: CHUNK_SIZE value must be a multiple of 8 in the range [48 .. 1048576]"}
+			}
+			opts.ChunkSize = n
+		case "DUPLICATE_POLICY":
+			if opts.DuplicatePolicy = parseTsDuplicatePolicy(arg); opts.DuplicatePolicy == "" {
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: Unknown DUPLICATE_POLICY"}
+			}
+		case "ON_DUPLICATE":
+			if !onDuplicate {
+				return opts, "", ErrorSyntax
+			}
+			if policy = parseTsDuplicatePolicy(arg); policy == "" {
+				return opts, "", &resp.RESPSimpleError{Value: "TSDB: Unknown ON_DUPLICATE policy"}
+			}
+		default:
+			return opts, "", ErrorSyntax
+		}
+	}
+	return opts, policy, nil
+}
+
+// parseTsDuplicatePolicy returns the duplicate policy named s, or "" if there is none
+func parseTsDuplicatePolicy(s string) string {
+	switch p := strings.ToUpper(s); p {
+	case state.TSDuplicateBlock, state.TSDuplicateFirst, state.TSDuplicateLast, state.TSDuplicateMin, state.TSDuplicateMax, state.TSDuplicateSum:
+		return p
+	}
+	return ""
+}
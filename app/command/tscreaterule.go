@@ -0,0 +1,40 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsCreateruleCommand = "TS.CREATERULE"
+
+func handleTsCreaterule(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 6 && len(sa) != 7 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 6 or 7-element array"}, nil
+	}
+	if strings.ToUpper(sa[3]) != "AGGREGATION" {
+		return ErrorSyntax, nil
+	}
+	agg, errResp := parseTsAggregation(sa[4], sa[5])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if len(sa) == 7 {
+		align, err := strconv.ParseInt(sa[6], 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: "TSDB: Couldn't parse alignTimestamp"}, nil
+		}
+		agg.Align = align
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		return []resp.RESP{ctx.Com}, state.TsCreaterule(sa[1], sa[2], agg)
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.OkLit, nil
+}
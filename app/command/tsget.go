@@ -0,0 +1,28 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsGetCommand = "TS.GET"
+
+func handleTsGet(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	latest := len(sa) == 3
+	if latest && strings.ToUpper(sa[2]) != "LATEST" {
+		return ErrorSyntax, nil
+	}
+	s, err := state.TsGet(sa[1], latest)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if s == nil {
+		return &resp.RESPArray{Value: []resp.RESP{}}, nil
+	}
+	return encodeTsSample(*s, ctx), nil
+}
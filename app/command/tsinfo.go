@@ -0,0 +1,67 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsInfoCommand = "TS.INFO"
+
+func handleTsInfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	info, err := state.TsInfo(sa[1])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	chunkType := "compressed"
+	if !info.Compressed {
+		chunkType = "uncompressed"
+	}
+	var duplicatePolicy resp.RESP = resp.NullLit
+	if info.DuplicatePolicy != "" {
+		duplicatePolicy = &resp.RESPBulkString{Value: strings.ToLower(info.DuplicatePolicy)}
+	}
+	var srcKey resp.RESP = resp.NullLit
+	if info.SrcKey != "" {
+		srcKey = &resp.RESPBulkString{Value: info.SrcKey}
+	}
+	labels := make([]resp.RESP, 0, 2*len(info.Labels))
+	for _, l := range info.Labels {
+		labels = append(labels, &resp.RESPBulkString{Value: l.Name}, &resp.RESPBulkString{Value: l.Value})
+	}
+	var encodedLabels resp.RESP = &resp.RESPMap{Value: labels}
+	if !isResp3(ctx) {
+		encodedLabels = encodeTsLabelPairs(labels)
+	}
+	rules := make([]resp.RESP, len(info.Rules))
+	for i, r := range info.Rules {
+		rules[i] = &resp.RESPArray{Value: []resp.RESP{
+			&resp.RESPBulkString{Value: r.Dest},
+			resp.RESPInteger{Value: r.Bucket},
+			&resp.RESPSimpleString{Value: strings.ToUpper(r.Aggregator)},
+			resp.RESPInteger{Value: r.Align},
+		}}
+	}
+	av := []resp.RESP{
+		&resp.RESPSimpleString{Value: "totalSamples"}, resp.RESPInteger{Value: info.TotalSamples},
+		&resp.RESPSimpleString{Value: "memoryUsage"}, resp.RESPInteger{Value: info.MemoryUsage},
+		&resp.RESPSimpleString{Value: "firstTimestamp"}, resp.RESPInteger{Value: info.FirstTimestamp},
+		&resp.RESPSimpleString{Value: "lastTimestamp"}, resp.RESPInteger{Value: info.LastTimestamp},
+		&resp.RESPSimpleString{Value: "retentionTime"}, resp.RESPInteger{Value: info.Retention},
+		&resp.RESPSimpleString{Value: "chunkCount"}, resp.RESPInteger{Value: info.ChunkCount},
+		&resp.RESPSimpleString{Value: "chunkSize"}, resp.RESPInteger{Value: info.ChunkSize},
+		&resp.RESPSimpleString{Value: "chunkType"}, &resp.RESPSimpleString{Value: chunkType},
+		&resp.RESPSimpleString{Value: "duplicatePolicy"}, duplicatePolicy,
+		&resp.RESPSimpleString{Value: "labels"}, encodedLabels,
+		&resp.RESPSimpleString{Value: "sourceKey"}, srcKey,
+		&resp.RESPSimpleString{Value: "rules"}, &resp.RESPArray{Value: rules},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
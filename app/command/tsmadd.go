@@ -0,0 +1,51 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var tsMaddCommand = "TS.MADD"
+
+// handleTsMadd adds each sample to an existing series, replying with its timestamp or the error adding it
+func handleTsMadd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	if (len(sa)-1)%3 != 0 {
+		return ErrorSyntax, nil
+	}
+	n := (len(sa) - 1) / 3
+	samples := make([]state.TSSample, n)
+	com := append([]string{}, sa...)
+	for i := range n {
+		s, errResp := parseTsSample(sa[2+3*i], sa[3+3*i])
+		if errResp != nil {
+			return errResp, nil
+		}
+		samples[i] = s
+		com[2+3*i] = strconv.FormatInt(s.Timestamp, 10)
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	av := make([]resp.RESP, n)
+	state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		added := false
+		for i, s := range samples {
+			if err := state.TsAdd(sa[1+3*i], s, "", nil); err != nil {
+				av[i] = &resp.RESPSimpleError{Value: err.Error()}
+			} else {
+				av[i] = resp.RESPInteger{Value: s.Timestamp}
+				added = true
+			}
+		}
+		if !added {
+			return nil, nil
+		}
+		return []resp.RESP{resp.EncodeStringSlice(com)}, nil
+	})
+	return &resp.RESPArray{Value: av}, nil
+}
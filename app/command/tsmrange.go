@@ -0,0 +1,147 @@
+package command
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	tsMrangeCommand    = "TS.MRANGE"
+	tsMrevrangeCommand = "TS.MREVRANGE"
+)
+
+func handleTsMrange(sa []string, ctx Context) (resp.RESP, error) {
+	return tsMrangeAux(sa, false, ctx)
+}
+
+func handleTsMrevrange(sa []string, ctx Context) (resp.RESP, error) {
+	return tsMrangeAux(sa, true, ctx)
+}
+
+// tsMrangeAux replies with the key, labels and samples of each matching series. Labels are only included with WITHLABELS,
+// or if selected with SELECTED_LABELS, in which case missing labels have a nil value.
+func tsMrangeAux(sa []string, rev bool, ctx Context) (resp.RESP, error) {
+	if len(sa) < 5 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 5-element array"}, nil
+	}
+	spec, rest, errResp := parseTsRangeSpec(sa[1:])
+	if errResp != nil {
+		return errResp, nil
+	}
+	spec.Rev = rev
+	withLabels := false
+	var selectedLabels []string
+	var filters []state.TSFilter
+	var groupBy *state.TSGroupBy
+	for i := 0; i < len(rest); i++ {
+		switch strings.ToUpper(rest[i]) {
+		case "WITHLABELS":
+			withLabels = true
+		case "SELECTED_LABELS":
+			for ; i+1 < len(rest) && !isTsMrangeOption(rest[i+1]); i++ {
+				selectedLabels = append(selectedLabels, rest[i+1])
+			}
+			if len(selectedLabels) == 0 {
+				return ErrorSyntax, nil
+			}
+		case "FILTER":
+			for ; i+1 < len(rest) && !isTsMrangeOption(rest[i+1]); i++ {
+				f, ok := parseTsFilter(rest[i+1])
+				if !ok {
+					return &resp.RESPSimpleError{Value: "TSDB: failed parsing labels"}, nil
+				}
+				filters = append(filters, f)
+			}
+		case "GROUPBY":
+			if i+3 >= len(rest) || strings.ToUpper(rest[i+2]) != "REDUCE" {
+				return ErrorSyntax, nil
+			}
+			groupBy = &state.TSGroupBy{Label: rest[i+1], Reducer: strings.ToLower(rest[i+3])}
+			if !state.IsTSAggregator(groupBy.Reducer) {
+				return &resp.RESPSimpleError{Value: "TSDB: Unknown reducer type"}, nil
+			}
+			i += 3
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if withLabels && selectedLabels != nil {
+		return &resp.RESPSimpleError{Value: "TSDB: WITHLABELS and SELECTED_LABELS cannot be specified together"}, nil
+	}
+	if !slices.ContainsFunc(filters, func(f state.TSFilter) bool { return !f.Negate && !slices.Contains(f.Values, "") }) {
+		return &resp.RESPSimpleError{Value: "TSDB: please provide at least one matcher"}, nil
+	}
+	series := state.TsMrange(filters, spec, groupBy)
+	av := make([]resp.RESP, 0, 2*len(series))
+	for _, s := range series {
+		var labels []resp.RESP
+		switch {
+		case withLabels || groupBy != nil:
+			for _, l := range s.Labels {
+				labels = append(labels, &resp.RESPBulkString{Value: l.Name}, &resp.RESPBulkString{Value: l.Value})
+			}
+		case selectedLabels != nil:
+			for _, name := range selectedLabels {
+				var value resp.RESP = resp.NullLit
+				if i := slices.IndexFunc(s.Labels, func(l state.TSLabel) bool { return l.Name == name }); i >= 0 {
+					value = &resp.RESPBulkString{Value: s.Labels[i].Value}
+				}
+				labels = append(labels, &resp.RESPBulkString{Value: name}, value)
+			}
+		}
+		samples := encodeTsSamples(s.Samples, ctx)
+		if isResp3(ctx) {
+			av = append(av, &resp.RESPBulkString{Value: s.Key}, &resp.RESPArray{Value: []resp.RESP{&resp.RESPMap{Value: labels}, samples}})
+		} else {
+			av = append(av, &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: s.Key}, encodeTsLabelPairs(labels), samples}})
+		}
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
+
+func isTsMrangeOption(s string) bool {
+	switch strings.ToUpper(s) {
+	case "WITHLABELS", "SELECTED_LABELS", "FILTER", "GROUPBY":
+		return true
+	}
+	return false
+}
+
+// parseTsFilter parses a label filter such as "label=value", "label!=value", "label=(value1,value2)" or "label!=",
+// which matches series where the label is present
+func parseTsFilter(s string) (state.TSFilter, bool) {
+	var f state.TSFilter
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return f, false
+	}
+	f.Label, f.Negate = s[:i], s[i-1] == '!'
+	if f.Negate {
+		f.Label = s[:i-1]
+	}
+	value := s[i+1:]
+	if f.Label == "" {
+		return f, false
+	}
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		f.Values = strings.Split(value[1:len(value)-1], ",")
+	} else {
+		f.Values = []string{value}
+	}
+	return f, true
+}
+
+// encodeTsLabelPairs encodes interleaved label names and values as an array of pairs, as RESP2 replies list labels
+func encodeTsLabelPairs(labels []resp.RESP) resp.RESP {
+	av := make([]resp.RESP, 0, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		av = append(av, &resp.RESPArray{Value: labels[i : i+2]})
+	}
+	return &resp.RESPArray{Value: av}
+}
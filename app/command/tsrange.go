@@ -0,0 +1,194 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	tsRangeCommand    = "TS.RANGE"
+	tsRevrangeCommand = "TS.REVRANGE"
+)
+
+func handleTsRange(sa []string, ctx Context) (resp.RESP, error) {
+	return tsRangeAux(sa, false, ctx)
+}
+
+func handleTsRevrange(sa []string, ctx Context) (resp.RESP, error) {
+	return tsRangeAux(sa, true, ctx)
+}
+
+func tsRangeAux(sa []string, rev bool, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	spec, rest, errResp := parseTsRangeSpec(sa[2:])
+	if errResp != nil {
+		return errResp, nil
+	}
+	if len(rest) > 0 {
+		return ErrorSyntax, nil
+	}
+	spec.Rev = rev
+	samples, err := state.TsRange(sa[1], spec)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeTsSamples(samples, ctx), nil
+}
+
+// parseTsRangeSpec parses the range and options of TS.RANGE and TS.MRANGE, returning the arguments from the first unknown option on
+func parseTsRangeSpec(sa []string) (state.TSRangeSpec, []string, resp.RESP) {
+	var spec state.TSRangeSpec
+	var err error
+	spec.From, spec.To = 0, math.MaxInt64
+	if sa[0] != "-" {
+		if spec.From, err = strconv.ParseInt(sa[0], 10, 64); err != nil || spec.From < 0 {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: wrong fromTimestamp"}
+		}
+	}
+	if sa[1] != "+" {
+		if spec.To, err = strconv.ParseInt(sa[1], 10, 64); err != nil || spec.To < 0 {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: wrong toTimestamp"}
+		}
+	}
+	var align string
+	bucketTimestamp := false
+	i := 2
+loop:
+	for ; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "LATEST":
+			spec.Latest = true
+		case "FILTER_BY_TS":
+			spec.FilterTimestamps = []int64{}
+			for ; i+1 < len(sa); i++ {
+				ts, err := strconv.ParseInt(sa[i+1], 10, 64)
+				if err != nil {
+					break
+				}
+				spec.FilterTimestamps = append(spec.FilterTimestamps, ts)
+			}
+			if len(spec.FilterTimestamps) == 0 {
+				return spec, nil, &resp.RESPSimpleError{Value: "TSDB: FILTER_BY_TS one or more arguments are missing"}
+			}
+		case "FILTER_BY_VALUE":
+			if i+2 >= len(sa) {
+				return spec, nil, ErrorSyntax
+			}
+			spec.FilterValue = true
+			if spec.MinValue, err = strconv.ParseFloat(sa[i+1], 64); err != nil {
+				return spec, nil, &resp.RESPSimpleError{Value: "TSDB: Couldn't parse MIN"}
+			}
+			if spec.MaxValue, err = strconv.ParseFloat(sa[i+2], 64); err != nil {
+				return spec, nil, &resp.RESPSimpleError{Value: "TSDB: Couldn't parse MAX"}
+			}
+			i += 2
+		case "COUNT":
+			if i+1 >= len(sa) {
+				return spec, nil, ErrorSyntax
+			}
+			if spec.Count, err = strconv.ParseInt(sa[i+1], 10, 64); err != nil || spec.Count < 1 {
+				return spec, nil, &resp.RESPSimpleError{Value: "TSDB: Couldn't parse COUNT"}
+			}
+			i++
+		case "ALIGN":
+			if i+1 >= len(sa) {
+				return spec, nil, ErrorSyntax
+			}
+			align = sa[i+1]
+			i++
+		case "AGGREGATION":
+			if i+2 >= len(sa) {
+				return spec, nil, ErrorSyntax
+			}
+			agg, errResp := parseTsAggregation(sa[i+1], sa[i+2])
+			if errResp != nil {
+				return spec, nil, errResp
+			}
+			spec.Aggregation = &agg
+			i += 2
+		case "BUCKETTIMESTAMP":
+			if i+1 >= len(sa) {
+				return spec, nil, ErrorSyntax
+			}
+			switch strings.ToLower(sa[i+1]) {
+			case "-", "low", "start":
+				spec.BucketTimestamp = state.TSBucketStart
+			case "+", "high", "end":
+				spec.BucketTimestamp = state.TSBucketEnd
+			case "~", "mid":
+				spec.BucketTimestamp = state.TSBucketMid
+			default:
+				return spec, nil, &resp.RESPSimpleError{Value: "TSDB: unknown BUCKETTIMESTAMP parameter"}
+			}
+			bucketTimestamp = true
+			i++
+		case "EMPTY":
+			spec.Empty = true
+		default:
+			break loop
+		}
+	}
+	if spec.Aggregation == nil {
+		if align != "" || bucketTimestamp || spec.Empty {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: ALIGN, BUCKETTIMESTAMP and EMPTY can only be used with AGGREGATION"}
+		}
+		return spec, sa[i:], nil
+	}
+	switch strings.ToLower(align) {
+	case "":
+	case "-", "start":
+		if sa[0] == "-" {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: start alignment can only be used with explicit start timestamp"}
+		}
+		spec.Aggregation.Align = spec.From
+	case "+", "end":
+		if sa[1] == "+" {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: end alignment can only be used with explicit end timestamp"}
+		}
+		spec.Aggregation.Align = spec.To
+	default:
+		if spec.Aggregation.Align, err = strconv.ParseInt(align, 10, 64); err != nil {
+			return spec, nil, &resp.RESPSimpleError{Value: "TSDB: unknown ALIGN parameter"}
+		}
+	}
+	return spec, sa[i:], nil
+}
+
+// parseTsAggregation parses an aggregator and a bucket duration, as with the AGGREGATION option
+func parseTsAggregation(aggregator, bucket string) (state.TSAggregation, resp.RESP) {
+	agg := state.TSAggregation{Aggregator: strings.ToLower(aggregator)}
+	if !state.IsTSAggregator(agg.Aggregator) {
+		return agg, &resp.RESPSimpleError{Value: "TSDB: Unknown aggregation type"}
+	}
+	var err error
+	if agg.Bucket, err = strconv.ParseInt(bucket, 10, 64); err != nil {
+		return agg, &resp.RESPSimpleError{Value: "TSDB: Couldn't parse bucketDuration"}
+	}
+	if agg.Bucket <= 0 {
+		return agg, &resp.RESPSimpleError{Value: "TSDB: bucketDuration must be greater than zero"}
+	}
+	return agg, nil
+}
+
+// encodeTsSample encodes a sample as its timestamp and value, which is a double for RESP3 connections and a simple string otherwise
+func encodeTsSample(s state.TSSample, ctx Context) resp.RESP {
+	var value resp.RESP = &resp.RESPSimpleString{Value: formatScore(s.Value)}
+	if isResp3(ctx) {
+		value = &resp.RESPDouble{Value: s.Value}
+	}
+	return &resp.RESPArray{Value: []resp.RESP{resp.RESPInteger{Value: s.Timestamp}, value}}
+}
+
+func encodeTsSamples(samples []state.TSSample, ctx Context) resp.RESP {
+	av := make([]resp.RESP, len(samples))
+	for i, s := range samples {
+		av[i] = encodeTsSample(s, ctx)
+	}
+	return &resp.RESPArray{Value: av}
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var (
+	ttlCommand         = "TTL"
+	pttlCommand        = "PTTL"
+	expiretimeCommand  = "EXPIRETIME"
+	pexpiretimeCommand = "PEXPIRETIME"
+)
+
+func handleTtl(sa []string, _ Context) (resp.RESP, error) {
+	return handleTtlAux(sa, time.Second, false)
+}
+
+func handlePttl(sa []string, _ Context) (resp.RESP, error) {
+	return handleTtlAux(sa, time.Millisecond, false)
+}
+
+func handleExpiretime(sa []string, _ Context) (resp.RESP, error) {
+	return handleTtlAux(sa, time.Second, true)
+}
+
+func handlePexpiretime(sa []string, _ Context) (resp.RESP, error) {
+	return handleTtlAux(sa, time.Millisecond, true)
+}
+
+// handleTtlAux handles the TTL, PTTL, EXPIRETIME and PEXPIRETIME commands, responding in units of unit, and with the unix time
+// of the expiry rather than the time remaining if absolute is set. It responds with -2 if there is no such key and -1 if it does not expire.
+func handleTtlAux(sa []string, unit time.Duration, absolute bool) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	ms := state.ExpireTime(sa[1])
+	if ms < 0 {
+		return resp.RESPInteger{Value: ms}, nil
+	}
+	perMs := int64(unit / time.Millisecond)
+	if absolute {
+		return resp.RESPInteger{Value: ms / perMs}, nil
+	}
+	// round to the nearest unit, as Redis does
+	return resp.RESPInteger{Value: (max(ms-time.Now().UnixMilli(), 0) + perMs/2) / perMs}, nil
+}
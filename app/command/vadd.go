@@ -0,0 +1,131 @@
+package command
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var vaddCommand = "VADD"
+
+// handleVadd adds an element to a vector set, as with
+// VADD key [REDUCE dim] (FP32 blob | VALUES num value...) element [CAS] [NOQUANT | Q8] [METRIC COSINE | L2] [EF num] [SETATTR json] [M num].
+// Since the graph is built deterministically, the command is propagated as is.
+func handleVadd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	var opts state.VsetOptions
+	i := 2
+	if strings.ToUpper(sa[i]) == "REDUCE" {
+		if i+1 >= len(sa) {
+			return ErrorSyntax, nil
+		}
+		n, err := strconv.Atoi(sa[i+1])
+		if err != nil || n <= 0 {
+			return &resp.RESPSimpleError{Value: "ERR invalid vector specification"}, nil
+		}
+		opts.Reduce = n
+		i += 2
+	}
+	vec, i, errResp := parseVsetVector(sa, i)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if i >= len(sa) {
+		return ErrorSyntax, nil
+	}
+	element := sa[i]
+	var attrs *string
+	for i++; i < len(sa); i++ {
+		opt := strings.ToUpper(sa[i])
+		switch opt {
+		case "CAS":
+		case "NOQUANT", "Q8":
+			if opts.Quant != "" {
+				return &resp.RESPSimpleError{Value: "ERR multiple quantization types specified"}, nil
+			}
+			opts.Quant = opt
+		case "METRIC", "EF", "SETATTR", "M":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			arg := sa[i+1]
+			i++
+			switch opt {
+			case "METRIC":
+				opts.Metric = strings.ToUpper(arg)
+				if opts.Metric != state.VsetMetricCos && opts.Metric != state.VsetMetricL2 {
+					return &resp.RESPSimpleError{Value: "ERR invalid METRIC"}, nil
+				}
+			case "EF":
+				n, err := strconv.Atoi(arg)
+				if err != nil || n <= 0 || n > 1000000 {
+					return &resp.RESPSimpleError{Value: "ERR invalid EF"}, nil
+				}
+				opts.EF = n
+			case "SETATTR":
+				attrs = &arg
+			case "M":
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 4 || n > state.VsetMaxM {
+					return &resp.RESPSimpleError{Value: "ERR invalid M"}, nil
+				}
+				opts.M = n
+			}
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var added bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		added, err = state.Vadd(sa[1], vec, element, attrs, opts)
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeBool(added), nil
+}
+
+// parseVsetVector parses a vector at sa[i], given either as FP32 followed by a blob of little-endian float32 values,
+// or as VALUES followed by their number and the values. It returns the index following the vector.
+func parseVsetVector(sa []string, i int) ([]float32, int, resp.RESP) {
+	if i+1 >= len(sa) {
+		return nil, 0, ErrorSyntax
+	}
+	switch strings.ToUpper(sa[i]) {
+	case "FP32":
+		blob := sa[i+1]
+		if len(blob) == 0 || len(blob)%4 != 0 {
+			return nil, 0, &resp.RESPSimpleError{Value: "ERR invalid vector specification"}
+		}
+		vec := make([]float32, len(blob)/4)
+		for j := range vec {
+			vec[j] = math.Float32frombits(binary.LittleEndian.Uint32([]byte(blob[4*j : 4*j+4])))
+		}
+		return vec, i + 2, nil
+	case "VALUES":
+		n, err := strconv.Atoi(sa[i+1])
+		if err != nil || n <= 0 || i+2+n > len(sa) {
+			return nil, 0, &resp.RESPSimpleError{Value: "ERR invalid vector specification"}
+		}
+		vec := make([]float32, n)
+		for j := range vec {
+			x, err := strconv.ParseFloat(sa[i+2+j], 32)
+			if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+				return nil, 0, &resp.RESPSimpleError{Value: "ERR invalid vector specification"}
+			}
+			vec[j] = float32(x)
+		}
+		return vec, i + 2 + n, nil
+	}
+	return nil, 0, ErrorSyntax
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var vembCommand = "VEMB"
+
+// handleVemb replies with the vector of an element, or with RAW, its quantization, the stored components as a blob, the norm of the vector,
+// and for Q8 the magnitude that the largest int8 component stands for
+func handleVemb(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 && len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3 or 4-element array"}, nil
+	}
+	raw := len(sa) == 4
+	if raw && strings.ToUpper(sa[3]) != "RAW" {
+		return ErrorSyntax, nil
+	}
+	emb, err := state.Vemb(sa[1], sa[2])
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if raw {
+		quant := "f32"
+		if emb.Quant == state.VsetQuantQ8 {
+			quant = "int8"
+		}
+		av := []resp.RESP{
+			&resp.RESPSimpleString{Value: quant},
+			&resp.RESPBulkString{Value: string(emb.Raw)},
+			encodeScore(float64(emb.Norm), ctx),
+		}
+		if emb.Quant == state.VsetQuantQ8 {
+			av = append(av, encodeScore(float64(emb.Scale)*127, ctx))
+		}
+		return &resp.RESPArray{Value: av}, nil
+	}
+	av := make([]resp.RESP, len(emb.Vector))
+	for i, x := range emb.Vector {
+		av[i] = encodeScore(float64(x), ctx)
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
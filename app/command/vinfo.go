@@ -0,0 +1,41 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var vinfoCommand = "VINFO"
+
+func handleVinfo(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 2 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2-element array"}, nil
+	}
+	info, err := state.Vinfo(sa[1])
+	if err == state.ErrorNone {
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	quant := "f32"
+	if info.Quant == state.VsetQuantQ8 {
+		quant = "int8"
+	}
+	av := []resp.RESP{
+		&resp.RESPSimpleString{Value: "quant-type"}, &resp.RESPSimpleString{Value: quant},
+		&resp.RESPSimpleString{Value: "distance-metric"}, &resp.RESPSimpleString{Value: strings.ToLower(info.Metric)},
+		&resp.RESPSimpleString{Value: "hnsw-m"}, resp.RESPInteger{Value: info.M},
+		&resp.RESPSimpleString{Value: "vector-dim"}, resp.RESPInteger{Value: info.Dim},
+		&resp.RESPSimpleString{Value: "projection-input-dim"}, resp.RESPInteger{Value: info.InputDim},
+		&resp.RESPSimpleString{Value: "size"}, resp.RESPInteger{Value: info.Size},
+		&resp.RESPSimpleString{Value: "max-level"}, resp.RESPInteger{Value: info.MaxLevel},
+		&resp.RESPSimpleString{Value: "attributes-count"}, resp.RESPInteger{Value: info.AttrsCount},
+	}
+	if isResp3(ctx) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var vsimCommand = "VSIM"
+
+// vsimDefaultCount is the number of elements returned without COUNT
+const vsimDefaultCount = 10
+
+// handleVsim replies with the elements most similar to a query, as with
+// VSIM key (ELE element | FP32 blob | VALUES num value...) [WITHSCORES] [WITHATTRIBS] [COUNT num] [EF num] [FILTER expression] [FILTER-EF num] [TRUTH] [NOTHREAD].
+// Scores and attributes are interleaved with the elements, or mapped from them for RESP3 connections.
+func handleVsim(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	q := state.VsimQuery{Count: vsimDefaultCount}
+	i := 2
+	if strings.ToUpper(sa[i]) == "ELE" {
+		q.Element = sa[i+1]
+		i += 2
+	} else {
+		var errResp resp.RESP
+		if q.Vector, i, errResp = parseVsetVector(sa, i); errResp != nil {
+			return errResp, nil
+		}
+	}
+	withScores := false
+	q.FilterEF = -1
+	for ; i < len(sa); i++ {
+		opt := strings.ToUpper(sa[i])
+		switch opt {
+		case "WITHSCORES":
+			withScores = true
+		case "WITHATTRIBS":
+			q.WithAttrs = true
+		case "TRUTH":
+			q.Truth = true
+		case "NOTHREAD":
+		case "COUNT", "EF", "FILTER-EF":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			n, err := strconv.Atoi(sa[i+1])
+			if err != nil || n < 0 || opt != "FILTER-EF" && n == 0 {
+				return &resp.RESPSimpleError{Value: "ERR invalid " + opt}, nil
+			}
+			switch opt {
+			case "COUNT":
+				q.Count = n
+			case "EF":
+				q.EF = n
+			case "FILTER-EF":
+				q.FilterEF = n
+			}
+			i++
+		case "FILTER":
+			if i+1 >= len(sa) {
+				return ErrorSyntax, nil
+			}
+			q.Filter = sa[i+1]
+			i++
+		default:
+			return ErrorSyntax, nil
+		}
+	}
+	if q.FilterEF < 0 {
+		q.FilterEF = q.Count * 100
+	}
+	results, err := state.Vsim(sa[1], q)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, 0, 3*len(results))
+	for _, r := range results {
+		av = append(av, &resp.RESPBulkString{Value: r.Element})
+		var extra []resp.RESP
+		if withScores {
+			extra = append(extra, encodeScore(r.Score, ctx))
+		}
+		if q.WithAttrs {
+			var attrs resp.RESP = resp.NullLit
+			if r.Attrs != nil {
+				attrs = &resp.RESPBulkString{Value: *r.Attrs}
+			}
+			extra = append(extra, attrs)
+		}
+		if isResp3(ctx) && len(extra) == 2 {
+			av = append(av, &resp.RESPArray{Value: extra})
+		} else {
+			av = append(av, extra...)
+		}
+	}
+	if isResp3(ctx) && (withScores || q.WithAttrs) {
+		return &resp.RESPMap{Value: av}, nil
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zaddCommand = "ZADD"
+
+func handleZadd(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	key := sa[1]
+	var opts state.ZaddOptions
+	ch, incr := false, false
+	i := 2
+flags:
+	for ; i < len(sa); i++ {
+		switch strings.ToUpper(sa[i]) {
+		case "NX":
+			opts.NX = true
+		case "XX":
+			opts.XX = true
+		case "GT":
+			opts.GT = true
+		case "LT":
+			opts.LT = true
+		case "CH":
+			ch = true
+		case "INCR":
+			incr = true
+		default:
+			break flags
+		}
+	}
+	pairs := sa[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return ErrorSyntax, nil
+	}
+	if opts.NX && opts.XX {
+		return &resp.RESPSimpleError{Value: "ERR XX and NX options at the same time are not compatible"}, nil
+	}
+	if (opts.GT && opts.LT) || (opts.NX && (opts.GT || opts.LT)) {
+		return &resp.RESPSimpleError{Value: "ERR GT, LT, and/or NX options at the same time are not compatible"}, nil
+	}
+	if incr && len(pairs) != 2 {
+		return &resp.RESPSimpleError{Value: "ERR INCR option supports a single increment-element pair"}, nil
+	}
+	members := make([]state.ZMember, len(pairs)/2)
+	for j := range members {
+		score, ok := parseScore(pairs[2*j])
+		if !ok {
+			return ErrorNotFloat, nil
+		}
+		members[j] = state.ZMember{Member: pairs[2*j+1], Score: score}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	if incr {
+		return zincrbyAux(key, members[0].Member, members[0].Score, opts, ctx)
+	}
+	var added, changed int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		added, changed, served, err = state.Zadd(key, members, opts)
+		if added+changed == 0 {
+			return nil, err
+		}
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if ch {
+		return resp.RESPInteger{Value: added + changed}, nil
+	}
+	return resp.RESPInteger{Value: added}, nil
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zcountCommand = "ZCOUNT"
+
+func handleZcount(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	r, errRes := parseScoreRange(sa[2], sa[3])
+	if errRes != nil {
+		return errRes, nil
+	}
+	n, err := state.Zcount(sa[1], state.ZRangeSpec{By: state.ZRangeByScore, Score: r})
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
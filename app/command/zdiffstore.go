@@ -0,0 +1,12 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zdiffstoreCommand = "ZDIFFSTORE"
+
+func handleZdiffstore(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZsetAlgebraStoreAux(sa, ctx, state.SetOpDiff)
+}
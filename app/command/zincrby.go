@@ -0,0 +1,44 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zincrbyCommand = "ZINCRBY"
+
+func handleZincrby(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	incr, ok := parseScore(sa[2])
+	if !ok {
+		return ErrorNotFloat, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	return zincrbyAux(sa[1], sa[3], incr, state.ZaddOptions{}, ctx)
+}
+
+// zincrbyAux increments the score of member as with ZINCRBY and ZADD INCR, replying with nil if opts prevented the update
+func zincrbyAux(key, member string, incr float64, opts state.ZaddOptions, ctx Context) (resp.RESP, error) {
+	var score float64
+	var updated bool
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		score, updated, served, err = state.Zincrby(key, member, incr, opts)
+		if !updated {
+			return nil, err
+		}
+		// the computed score is propagated rather than the increment, so that replicas cannot diverge due to differences in float rounding
+		return append([]resp.RESP{resp.EncodeStringSlice([]string{zaddCommand, key, formatScore(score), member})}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if !updated {
+		return resp.NullLit, nil
+	}
+	return encodeScore(score, ctx), nil
+}
@@ -0,0 +1,12 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zinterCommand = "ZINTER"
+
+func handleZinter(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZsetAlgebraAux(sa, ctx, state.SetOpInter)
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zmpopCommand = "ZMPOP"
+
+func handleZmpop(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	return handleZmpopAux(sa[1:], ctx, 0, false)
+}
+
+// handleZmpopAux handles the ZMPOP and BZMPOP commands, given the arguments following the command name (and timeout, for BZMPOP)
+func handleZmpopAux(args []string, ctx Context, timeout time.Duration, block bool) (resp.RESP, error) {
+	keys, rest, errRes := parseNumkeys(args)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if len(rest) == 0 {
+		return ErrorSyntax, nil
+	}
+	var max bool
+	switch strings.ToUpper(rest[0]) {
+	case "MIN":
+	case "MAX":
+		max = true
+	default:
+		return ErrorSyntax, nil
+	}
+	var count int64 = 1
+	switch rest = rest[1:]; len(rest) {
+	case 0:
+	case 2:
+		if strings.ToUpper(rest[0]) != "COUNT" {
+			return ErrorSyntax, nil
+		}
+		var err error
+		count, err = strconv.ParseInt(rest[1], 10, 64)
+		if err != nil || count <= 0 {
+			return &resp.RESPSimpleError{Value: "ERR count should be greater than 0"}, nil
+		}
+	default:
+		return ErrorSyntax, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	res, err := state.Bzmpop(keys, max, count, timeout, block && !ctx.InTransaction, func(key string, popped []state.ZMember) resp.RESP {
+		// the members are replied as member-score pairs whatever the protocol
+		pairs := make([]resp.RESP, len(popped))
+		for i, m := range popped {
+			pairs[i] = &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: m.Member}, encodeScore(m.Score, ctx)}}
+		}
+		return &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: key}, &resp.RESPArray{Value: pairs}}}
+	})
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if res == nil {
+		return resp.NullArrayLit, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zmscoreCommand = "ZMSCORE"
+
+func handleZmscore(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	scores, err := state.Zmscore(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, len(scores))
+	for i, score := range scores {
+		av[i] = encodeOptionalScore(score, ctx)
+	}
+	return &resp.RESPArray{Value: av}, nil
+}
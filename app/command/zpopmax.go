@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var zpopmaxCommand = "ZPOPMAX"
+
+func handleZpopmax(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZpopAux(sa, ctx, true)
+}
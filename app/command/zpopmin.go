@@ -0,0 +1,49 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zpopminCommand = "ZPOPMIN"
+
+func handleZpopmin(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZpopAux(sa, ctx, false)
+}
+
+// handleZpopAux handles the ZPOPMIN and ZPOPMAX commands
+func handleZpopAux(sa []string, ctx Context, max bool) (resp.RESP, error) {
+	if len(sa) != 2 && len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 or 3-element array"}, nil
+	}
+	key := sa[1]
+	var count int64 = 1
+	if len(sa) == 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil || count < 0 {
+			return &resp.RESPSimpleError{Value: "ERR value is out of range, must be positive"}, nil
+		}
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var popped []state.ZMember
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		popped, err = state.Zpop(key, max, count)
+		if len(popped) == 0 {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	// without a count, the member is replied as a flat member-score pair even to RESP3 clients
+	if len(sa) == 2 && len(popped) == 1 {
+		return &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: popped[0].Member}, encodeScore(popped[0].Score, ctx)}}, nil
+	}
+	return encodeZMembers(popped, true, ctx), nil
+}
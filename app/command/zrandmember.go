@@ -0,0 +1,51 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zrandmemberCommand = "ZRANDMEMBER"
+
+func handleZrandmember(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 2 || len(sa) > 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 2 to 4-element array"}, nil
+	}
+	var count int64 = 1
+	withScores := false
+	if len(sa) >= 3 {
+		var err error
+		count, err = strconv.ParseInt(sa[2], 10, 64)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+		}
+		// a negative count asks for twice as many strings when WITHSCORES is given
+		if count < -math.MaxInt64/2 {
+			return ErrorOutOfRange, nil
+		}
+	}
+	if len(sa) == 4 {
+		if strings.ToUpper(sa[3]) != "WITHSCORES" {
+			return ErrorSyntax, nil
+		}
+		withScores = true
+	}
+	members, err := state.Zrandmember(sa[1], count)
+	if err == state.ErrorNone {
+		if len(sa) == 2 {
+			return resp.NullLit, nil
+		}
+		return resp.EncodeStringSlice([]string{}), nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if len(sa) == 2 {
+		return &resp.RESPBulkString{Value: members[0].Member}, nil
+	}
+	return encodeZMembers(members, withScores, ctx), nil
+}
@@ -0,0 +1,198 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zrangeCommand = "ZRANGE"
+
+var (
+	ErrorScoreRange = &resp.RESPSimpleError{Value: "ERR min or max is not a float"}
+	ErrorLexRange   = &resp.RESPSimpleError{Value: "ERR min or max not valid string range item"}
+)
+
+func handleZrange(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	spec, withScores, errRes := parseZrangeArgs(sa[2:], true)
+	if errRes != nil {
+		return errRes, nil
+	}
+	members, err := state.Zrange(sa[1], spec)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeZMembers(members, withScores, ctx), nil
+}
+
+// parseZrangeArgs parses the arguments of ZRANGE following the key, i.e. start stop [BYSCORE | BYLEX] [REV] [LIMIT offset count] [WITHSCORES].
+// WITHSCORES is only accepted if allowWithScores is set.
+func parseZrangeArgs(args []string, allowWithScores bool) (state.ZRangeSpec, bool, resp.RESP) {
+	spec := state.ZRangeSpec{By: state.ZRangeByRank, Count: -1}
+	withScores, limit := false, false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "BYSCORE":
+			spec.By = state.ZRangeByScore
+		case "BYLEX":
+			spec.By = state.ZRangeByLex
+		case "REV":
+			spec.Rev = true
+		case "WITHSCORES":
+			if !allowWithScores {
+				return spec, false, ErrorSyntax
+			}
+			withScores = true
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return spec, false, ErrorSyntax
+			}
+			var err1, err2 error
+			spec.Offset, err1 = strconv.ParseInt(args[i+1], 10, 64)
+			spec.Count, err2 = strconv.ParseInt(args[i+2], 10, 64)
+			if err1 != nil || err2 != nil {
+				return spec, false, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+			}
+			limit = true
+			i += 2
+		default:
+			return spec, false, ErrorSyntax
+		}
+	}
+	if limit && spec.By == state.ZRangeByRank {
+		return spec, false, &resp.RESPSimpleError{Value: "ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"}
+	}
+	if withScores && spec.By == state.ZRangeByLex {
+		return spec, false, &resp.RESPSimpleError{Value: "ERR syntax error, WITHSCORES not supported in combination with BYLEX"}
+	}
+	// with REV, the range is given from its highest end
+	min, max := args[0], args[1]
+	if spec.Rev {
+		min, max = max, min
+	}
+	switch spec.By {
+	case state.ZRangeByRank:
+		var err1, err2 error
+		spec.Start, err1 = strconv.ParseInt(args[0], 10, 64)
+		spec.Stop, err2 = strconv.ParseInt(args[1], 10, 64)
+		if err1 != nil || err2 != nil {
+			return spec, false, &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}
+		}
+	case state.ZRangeByScore:
+		var errRes resp.RESP
+		if spec.Score, errRes = parseScoreRange(min, max); errRes != nil {
+			return spec, false, errRes
+		}
+	case state.ZRangeByLex:
+		var errRes resp.RESP
+		if spec.Lex, errRes = parseLexRange(min, max); errRes != nil {
+			return spec, false, errRes
+		}
+	}
+	return spec, withScores, nil
+}
+
+// parseScoreRange parses a range of scores such as "(1" "+inf", where "(" marks an exclusive end
+func parseScoreRange(min, max string) (state.ZScoreRange, resp.RESP) {
+	var r state.ZScoreRange
+	var ok1, ok2 bool
+	r.Min, r.MinExclusive, ok1 = parseScoreBound(min)
+	r.Max, r.MaxExclusive, ok2 = parseScoreBound(max)
+	if !ok1 || !ok2 {
+		return r, ErrorScoreRange
+	}
+	return r, nil
+}
+
+func parseScoreBound(s string) (float64, bool, bool) {
+	exclusive := strings.HasPrefix(s, "(")
+	if exclusive {
+		s = s[1:]
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false, false
+	}
+	return f, exclusive, true
+}
+
+// parseLexRange parses a lexicographical range such as "[a" "+", where "(" marks an exclusive end and "[" an inclusive one
+func parseLexRange(min, max string) (state.ZLexRange, resp.RESP) {
+	var r state.ZLexRange
+	var ok1, ok2 bool
+	r.Min, ok1 = parseLexBound(min)
+	r.Max, ok2 = parseLexBound(max)
+	if !ok1 || !ok2 {
+		return r, ErrorLexRange
+	}
+	return r, nil
+}
+
+func parseLexBound(s string) (state.ZLexBound, bool) {
+	switch {
+	case s == "-":
+		return state.ZLexBound{Inf: -1}, true
+	case s == "+":
+		return state.ZLexBound{Inf: 1}, true
+	case strings.HasPrefix(s, "["):
+		return state.ZLexBound{Value: s[1:]}, true
+	case strings.HasPrefix(s, "("):
+		return state.ZLexBound{Value: s[1:], Exclusive: true}, true
+	}
+	return state.ZLexBound{}, false
+}
+
+// parseScore parses a score as accepted by ZADD and ZINCRBY, which may be infinite
+func parseScore(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// formatScore formats a score as Redis does, in the shortest form that parses back to the same score
+func formatScore(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case f == 0 || (math.Abs(f) >= 1e-4 && math.Abs(f) < 1e21):
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeScore encodes a score as a double for RESP3 connections, and as a bulk string otherwise
+func encodeScore(f float64, ctx Context) resp.RESP {
+	if isResp3(ctx) {
+		return &resp.RESPDouble{Value: f}
+	}
+	return &resp.RESPBulkString{Value: formatScore(f)}
+}
+
+// encodeZMembers encodes members, interleaved with their scores if withScores is set, or as member-score pairs for RESP3 connections
+func encodeZMembers(members []state.ZMember, withScores bool, ctx Context) resp.RESP {
+	av := make([]resp.RESP, 0, len(members))
+	for _, m := range members {
+		member := &resp.RESPBulkString{Value: m.Member}
+		switch {
+		case !withScores:
+			av = append(av, member)
+		case isResp3(ctx):
+			av = append(av, &resp.RESPArray{Value: []resp.RESP{member, encodeScore(m.Score, ctx)}})
+		default:
+			av = append(av, member, encodeScore(m.Score, ctx))
+		}
+	}
+	return &resp.RESPArray{Value: av}
+}
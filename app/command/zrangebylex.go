@@ -0,0 +1,15 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var zrangebylexCommand = "ZRANGEBYLEX"
+
+// handleZrangebylex handles ZRANGEBYLEX key min max [LIMIT offset count] as ZRANGE key min max BYLEX ...
+func handleZrangebylex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	return handleZrange(append([]string{zrangeCommand, sa[1], sa[2], sa[3], "BYLEX"}, sa[4:]...), ctx)
+}
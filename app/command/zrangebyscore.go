@@ -0,0 +1,15 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var zrangebyscoreCommand = "ZRANGEBYSCORE"
+
+// handleZrangebyscore handles ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count] as ZRANGE key min max BYSCORE ...
+func handleZrangebyscore(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	return handleZrange(append([]string{zrangeCommand, sa[1], sa[2], sa[3], "BYSCORE"}, sa[4:]...), ctx)
+}
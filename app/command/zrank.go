@@ -0,0 +1,39 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zrankCommand = "ZRANK"
+
+func handleZrank(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZrankAux(sa, ctx, false)
+}
+
+// handleZrankAux handles the ZRANK and ZREVRANK commands
+func handleZrankAux(sa []string, ctx Context, rev bool) (resp.RESP, error) {
+	if len(sa) != 3 && len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3 or 4-element array"}, nil
+	}
+	withScore := len(sa) == 4
+	if withScore && strings.ToUpper(sa[3]) != "WITHSCORE" {
+		return ErrorSyntax, nil
+	}
+	rank, score, err := state.Zrank(sa[1], sa[2], rev)
+	if err == state.ErrorNone {
+		if withScore {
+			return resp.NullArrayLit, nil
+		}
+		return resp.NullLit, nil
+	}
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	if withScore {
+		return &resp.RESPArray{Value: []resp.RESP{resp.RESPInteger{Value: rank}, encodeScore(score, ctx)}}, nil
+	}
+	return resp.RESPInteger{Value: rank}, nil
+}
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zremrangebylexCommand = "ZREMRANGEBYLEX"
+
+func handleZremrangebylex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	r, errRes := parseLexRange(sa[2], sa[3])
+	if errRes != nil {
+		return errRes, nil
+	}
+	return handleZremrangeAux(sa[1], state.ZRangeSpec{By: state.ZRangeByLex, Lex: r}, ctx)
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zremrangebyrankCommand = "ZREMRANGEBYRANK"
+
+func handleZremrangebyrank(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 4-element array"}, nil
+	}
+	start, err1 := strconv.ParseInt(sa[2], 10, 64)
+	stop, err2 := strconv.ParseInt(sa[3], 10, 64)
+	if err1 != nil || err2 != nil {
+		return &resp.RESPSimpleError{Value: state.ErrorNotInteger.Error()}, nil
+	}
+	return handleZremrangeAux(sa[1], state.ZRangeSpec{By: state.ZRangeByRank, Start: start, Stop: stop}, ctx)
+}
+
+// handleZremrangeAux handles the ZREMRANGEBYRANK, ZREMRANGEBYSCORE and ZREMRANGEBYLEX commands
+func handleZremrangeAux(key string, spec state.ZRangeSpec, ctx Context) (resp.RESP, error) {
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	spec.Count = -1
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		n, err = state.Zremrange(key, spec)
+		if n == 0 {
+			return nil, err
+		}
+		return []resp.RESP{ctx.Com}, err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
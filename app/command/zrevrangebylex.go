@@ -0,0 +1,15 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var zrevrangebylexCommand = "ZREVRANGEBYLEX"
+
+// handleZrevrangebylex handles ZREVRANGEBYLEX key max min [LIMIT offset count] as ZRANGE key max min BYLEX REV ...
+func handleZrevrangebylex(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	return handleZrange(append([]string{zrangeCommand, sa[1], sa[2], sa[3], "BYLEX", "REV"}, sa[4:]...), ctx)
+}
@@ -0,0 +1,11 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var zrevrankCommand = "ZREVRANK"
+
+func handleZrevrank(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZrankAux(sa, ctx, true)
+}
@@ -0,0 +1,29 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zscanCommand = "ZSCAN"
+
+// handleZscan walks a sorted set, as with ZSCAN key cursor [MATCH pattern] [COUNT count]. It replies with the cursor to continue from,
+// followed by the members visited, each followed by its score.
+func handleZscan(sa []string, _ Context) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	cursor, opts, errRes := parseScanArgs(sa[0], sa[2:])
+	if errRes != nil {
+		return errRes, nil
+	}
+	cursor, members, err := state.Zscan(sa[1], cursor, opts)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	av := make([]resp.RESP, 0, 2*len(members))
+	for _, m := range members {
+		av = append(av, &resp.RESPBulkString{Value: m.Member}, &resp.RESPBulkString{Value: formatScore(m.Score)})
+	}
+	return encodeScanReply(cursor, &resp.RESPArray{Value: av}), nil
+}
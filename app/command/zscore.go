@@ -0,0 +1,27 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zscoreCommand = "ZSCORE"
+
+func handleZscore(sa []string, ctx Context) (resp.RESP, error) {
+	if len(sa) != 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected 3-element array"}, nil
+	}
+	scores, err := state.Zmscore(sa[1], sa[2:])
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeOptionalScore(scores[0], ctx), nil
+}
+
+// encodeOptionalScore encodes score as with encodeScore, or as nil if there is none
+func encodeOptionalScore(score *float64, ctx Context) resp.RESP {
+	if score == nil {
+		return resp.NullLit
+	}
+	return encodeScore(*score, ctx)
+}
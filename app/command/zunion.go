@@ -0,0 +1,87 @@
+package command
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zunionCommand = "ZUNION"
+
+func handleZunion(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZsetAlgebraAux(sa, ctx, state.SetOpUnion)
+}
+
+// handleZsetAlgebraAux handles the ZUNION, ZINTER and ZDIFF commands
+func handleZsetAlgebraAux(sa []string, ctx Context, op state.SetOp) (resp.RESP, error) {
+	if len(sa) < 3 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 3-element array"}, nil
+	}
+	args, errRes := parseZsetAlgebraArgs(sa[1:], op, true)
+	if errRes != nil {
+		return errRes, nil
+	}
+	members, err := state.ZsetAlgebra(args.keys, args.weights, args.agg, op)
+	if err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return encodeZMembers(members, args.withScores, ctx), nil
+}
+
+type zsetAlgebraArgs struct {
+	keys       []string
+	weights    []float64
+	agg        state.ZAggregate
+	withScores bool
+}
+
+// parseZsetAlgebraArgs parses numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX] [WITHSCORES].
+// WEIGHTS and AGGREGATE are not accepted for SetOpDiff, and WITHSCORES is only accepted if allowWithScores is set.
+func parseZsetAlgebraArgs(sa []string, op state.SetOp, allowWithScores bool) (zsetAlgebraArgs, resp.RESP) {
+	var args zsetAlgebraArgs
+	keys, rest, errRes := parseNumkeys(sa)
+	if errRes != nil {
+		return args, errRes
+	}
+	args.keys = keys
+	for i := 0; i < len(rest); i++ {
+		switch opt := strings.ToUpper(rest[i]); {
+		case opt == "WEIGHTS" && op != state.SetOpDiff:
+			if i+len(keys) >= len(rest) {
+				return args, ErrorSyntax
+			}
+			args.weights = make([]float64, len(keys))
+			for j := range keys {
+				w, err := strconv.ParseFloat(rest[i+1+j], 64)
+				if err != nil || math.IsNaN(w) {
+					return args, &resp.RESPSimpleError{Value: "ERR weight value is not a float"}
+				}
+				args.weights[j] = w
+			}
+			i += len(keys)
+		case opt == "AGGREGATE" && op != state.SetOpDiff:
+			if i+1 >= len(rest) {
+				return args, ErrorSyntax
+			}
+			switch strings.ToUpper(rest[i+1]) {
+			case "SUM":
+				args.agg = state.ZAggregateSum
+			case "MIN":
+				args.agg = state.ZAggregateMin
+			case "MAX":
+				args.agg = state.ZAggregateMax
+			default:
+				return args, ErrorSyntax
+			}
+			i++
+		case opt == "WITHSCORES" && allowWithScores:
+			args.withScores = true
+		default:
+			return args, ErrorSyntax
+		}
+	}
+	return args, nil
+}
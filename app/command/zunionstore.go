@@ -0,0 +1,37 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/state"
+)
+
+var zunionstoreCommand = "ZUNIONSTORE"
+
+func handleZunionstore(sa []string, ctx Context) (resp.RESP, error) {
+	return handleZsetAlgebraStoreAux(sa, ctx, state.SetOpUnion)
+}
+
+// handleZsetAlgebraStoreAux handles the ZUNIONSTORE, ZINTERSTORE and ZDIFFSTORE commands
+func handleZsetAlgebraStoreAux(sa []string, ctx Context, op state.SetOp) (resp.RESP, error) {
+	if len(sa) < 4 {
+		return &resp.RESPSimpleError{Value: "Invalid input: expected at least 4-element array"}, nil
+	}
+	dst := sa[1]
+	args, errRes := parseZsetAlgebraArgs(sa[2:], op, false)
+	if errRes != nil {
+		return errRes, nil
+	}
+	if ctx.IsReplica && !ctx.IsReplConn {
+		return &resp.RESPSimpleError{Value: "READONLY You can't write against a read only replica."}, nil
+	}
+	var n int64
+	if err := state.ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		var err error
+		var served []resp.RESP
+		n, served, err = state.ZsetAlgebraStore(dst, args.keys, args.weights, args.agg, op)
+		return append([]resp.RESP{ctx.Com}, served...), err
+	}); err != nil {
+		return &resp.RESPSimpleError{Value: err.Error()}, nil
+	}
+	return resp.RESPInteger{Value: n}, nil
+}
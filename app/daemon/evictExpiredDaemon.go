@@ -15,5 +15,6 @@ func evictExpiredDaemon() {
 		time.Sleep(evictExpiredDaemonTimeBetweenRuns)
 		// Note that this is a no-op for replicas
 		state.SyncTryEvictExpiredKeysSweep()
+		state.SyncTryEvictExpiredHashFieldsSweep()
 	}
 }
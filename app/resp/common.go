@@ -1,7 +1,8 @@
 package resp
 
 var (
-	OkLit     = &RESPSimpleString{Value: "OK"}
-	QueuedLit = &RESPSimpleString{Value: "QUEUED"}
-	NullLit   = &RESPNull{CompatibilityFlag: 1}
+	OkLit        = &RESPSimpleString{Value: "OK"}
+	QueuedLit    = &RESPSimpleString{Value: "QUEUED"}
+	NullLit      = &RESPNull{CompatibilityFlag: 1}
+	NullArrayLit = &RESPNull{CompatibilityFlag: 2}
 )
@@ -1,6 +1,7 @@
 package resp
 
 import (
+	"math"
 	"math/big"
 	"strconv"
 	"strings"
@@ -108,6 +109,12 @@ type RESPDouble struct {
 var _ RESP = (*RESPDouble)(nil)
 
 func (r RESPDouble) SerializeRESP() string {
+	switch {
+	case math.IsInf(r.Value, 1):
+		return ",inf\r\n"
+	case math.IsInf(r.Value, -1):
+		return ",-inf\r\n"
+	}
 	return "," + strings.ToLower(strconv.FormatFloat(r.Value, 'g', -1, 64)) + "\r\n"
 }
 
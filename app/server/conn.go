@@ -18,10 +18,12 @@ func HandleConn(c net.Conn) error {
 func HandleConnReader(r *respreader.BufferedRESPConnReader) error {
 	// Warning: opts here is reused
 	queued := resp.NewComSlice()
+	protocol := 2
 	for {
 		if err := command.HandleNext(r, command.HandlerOptions{
 			Queued:        queued,
 			InTransaction: false,
+			Protocol:      &protocol,
 		}); err != nil {
 			if err == io.EOF {
 				log.Println("handleReader: connection closed by client")
@@ -0,0 +1,134 @@
+package state
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+	"github.com/codecrafters-io/redis-starter-go/app/utility"
+)
+
+// blockPopper pops from the value stored at key on behalf of a client, returning a nil response if there is nothing at key to pop from.
+// Otherwise, it returns the response to the client, the commands effecting the pop that are to be propagated in place of the blocking command,
+// and any further keys that have received elements as a result. The caller must hold DbMu.
+type blockPopper func(key string) (res resp.RESP, cmds []resp.RESP, pushed []string, err error)
+
+type blockListener struct {
+	l    *sync.Mutex
+	cond *sync.Cond
+	keys []string
+	pop  blockPopper
+	res  resp.RESP
+}
+
+func newBlockListener(keys []string, pop blockPopper) *blockListener {
+	l := &sync.Mutex{}
+	return &blockListener{
+		l:    l,
+		cond: sync.NewCond(l),
+		keys: keys,
+		pop:  pop,
+		res:  nil,
+	}
+}
+
+// blockListeners holds, for each key, the clients blocked on it in the order that they blocked
+var blockListeners = make(map[string][]*blockListener)
+
+// While a goroutine holds the lock, no other goroutine is expected to register, unregister or serve blocked clients.
+// For deadlock avoidance, the lock should be acquired after DbMu, and before the lock of any listener
+var blockListenersMu sync.Mutex
+
+// unsafeUnregisterBlockListener assumes that the caller holds blockListenersMu
+func unsafeUnregisterBlockListener(listener *blockListener) {
+	for _, key := range listener.keys {
+		listeners := slices.DeleteFunc(blockListeners[key], func(l *blockListener) bool {
+			return l == listener
+		})
+		if len(listeners) == 0 {
+			delete(blockListeners, key)
+		} else {
+			blockListeners[key] = listeners
+		}
+	}
+}
+
+// unsafeServeBlockers serves the clients blocked on keys, longest-waiting first, for as long as there are values at keys that they can pop from.
+// Clients that cannot pop from the value at a key (e.g. as it has the wrong type) remain blocked.
+// Keys that receive elements as a result (e.g. the destination of BLMOVE) are served in turn.
+// It returns the commands effecting the pops, which are to be propagated after the command that pushed to keys. The caller must hold DbMu.
+func unsafeServeBlockers(keys ...string) []resp.RESP {
+	blockListenersMu.Lock()
+	defer blockListenersMu.Unlock()
+	var cmds []resp.RESP
+	for len(keys) > 0 {
+		key := keys[0]
+		keys = keys[1:]
+		for _, listener := range slices.Clone(blockListeners[key]) {
+			res, popCmds, pushed, err := listener.pop(key)
+			if err != nil || res == nil {
+				continue
+			}
+			cmds = append(cmds, popCmds...)
+			keys = append(keys, pushed...)
+			unsafeUnregisterBlockListener(listener)
+			listener.l.Lock()
+			listener.res = res
+			listener.cond.Broadcast()
+			listener.l.Unlock()
+		}
+	}
+	return cmds
+}
+
+// blockingOp pops from the first of keys that there is something to pop from using pop, blocking for up to timeout (0 being indefinitely) if there is none and block is set.
+// It returns nil if nothing could be popped in time. Pops are propagated as the commands returned by pop.
+func blockingOp(keys []string, pop blockPopper, timeout time.Duration, block bool) (resp.RESP, error) {
+	var res resp.RESP
+	var listener *blockListener
+	if err := ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		LockDbMu()
+		defer UnlockDbMu()
+		for _, key := range keys {
+			var cmds []resp.RESP
+			var pushed []string
+			var err error
+			res, cmds, pushed, err = pop(key)
+			if err != nil {
+				return nil, err
+			}
+			if res != nil {
+				return append(cmds, unsafeServeBlockers(pushed...)...), nil
+			}
+		}
+		if !block {
+			return nil, nil
+		}
+		listener = newBlockListener(keys, pop)
+		// the listener is locked before it is registered so that it cannot be served before it starts waiting
+		listener.l.Lock()
+		blockListenersMu.Lock()
+		for _, key := range keys {
+			blockListeners[key] = append(blockListeners[key], listener)
+		}
+		blockListenersMu.Unlock()
+		return nil, nil
+	}); err != nil {
+		return nil, err
+	}
+	if listener == nil {
+		return res, nil
+	}
+	go utility.Timeout(timeout, listener.l, listener.cond, nil)
+	listener.cond.Wait()
+	listener.l.Unlock()
+	// once unregistered, the listener can no longer be served, so its response is final
+	blockListenersMu.Lock()
+	unsafeUnregisterBlockListener(listener)
+	listener.l.Lock()
+	res = listener.res
+	listener.l.Unlock()
+	blockListenersMu.Unlock()
+	return res, nil
+}
@@ -0,0 +1,231 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	BloomDefaultErrorRate = 0.01
+	BloomDefaultCapacity  = 100
+	BloomDefaultExpansion = 2
+	// bloomErrorTightening is the factor by which the error rate of each sub-filter is tightened relative to the previous one,
+	// so that the compound error rate of a scaled filter converges
+	bloomErrorTightening = 0.5
+	bloomSeed            = 0xc6a4a7935bd1e995
+)
+
+var (
+	ErrorItemExists       = errors.New("ERR item exists")
+	ErrorFilterNotFound   = errors.New("ERR not found")
+	ErrorBloomFull        = errors.New("ERR non scaling filter is full")
+	ErrorBloomMaxCapacity = errors.New("ERR filter capacity is too large")
+)
+
+// DbBloom is a scalable Bloom filter, which adds a sub-filter with expansion times the capacity and a tighter error rate whenever the last one fills up.
+// Items are tested against every sub-filter, and added to the last one.
+type DbBloom struct {
+	dbExpiry
+	filters   []*bloomFilter
+	expansion int64
+	// expansion is ignored if nonScaling is set, in which case adding to a full filter fails
+	nonScaling bool
+}
+
+var _ DbValue = (*DbBloom)(nil)
+
+func (v *DbBloom) Type() string {
+	return "MBbloom--"
+}
+
+// bloomFilter is a Bloom filter sized for capacity items at errorRate, which sets hashes bits per item by double hashing
+type bloomFilter struct {
+	bits      []uint64
+	nbits     uint64
+	hashes    uint64
+	capacity  int64
+	errorRate float64
+	items     int64
+}
+
+func newBloomFilter(errorRate float64, capacity int64) (*bloomFilter, error) {
+	bitsPerItem := -math.Log(errorRate) / (math.Ln2 * math.Ln2)
+	nbits := math.Ceil(float64(capacity) * bitsPerItem)
+	if capacity <= 0 || nbits > 1<<40 {
+		return nil, ErrorBloomMaxCapacity
+	}
+	return &bloomFilter{
+		bits:      make([]uint64, (uint64(nbits)+63)/64),
+		nbits:     uint64(nbits),
+		hashes:    uint64(math.Ceil(math.Ln2 * bitsPerItem)),
+		capacity:  capacity,
+		errorRate: errorRate,
+	}, nil
+}
+
+// bloomHash returns the two hashes of item from which the bits of every filter are derived
+func bloomHash(item string) (uint64, uint64) {
+	a := murmurHash64A(item, bloomSeed)
+	return a, murmurHash64A(item, a)
+}
+
+func (f *bloomFilter) has(a, b uint64) bool {
+	for i := range f.hashes {
+		x := (a + i*b) % f.nbits
+		if f.bits[x/64]&(1<<(x%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) add(a, b uint64) {
+	for i := range f.hashes {
+		x := (a + i*b) % f.nbits
+		f.bits[x/64] |= 1 << (x % 64)
+	}
+	f.items++
+}
+
+func (v *DbBloom) has(a, b uint64) bool {
+	for _, f := range v.filters {
+		if f.has(a, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds an item with the hashes a and b, returning false if it may already have been added
+func (v *DbBloom) add(a, b uint64) (bool, error) {
+	if v.has(a, b) {
+		return false, nil
+	}
+	last := v.filters[len(v.filters)-1]
+	if last.items >= last.capacity {
+		if v.nonScaling {
+			return false, ErrorBloomFull
+		}
+		f, err := newBloomFilter(last.errorRate*bloomErrorTightening, last.capacity*v.expansion)
+		if err != nil {
+			return false, err
+		}
+		v.filters = append(v.filters, f)
+		last = f
+	}
+	last.add(a, b)
+	return true, nil
+}
+
+// unsafeGetBloom returns the Bloom filter stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetBloom(key string) (*DbBloom, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	bf, ok := v.(*DbBloom)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return bf, nil
+}
+
+func newDbBloom(errorRate float64, capacity, expansion int64, nonScaling bool) (*DbBloom, error) {
+	f, err := newBloomFilter(errorRate, capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &DbBloom{filters: []*bloomFilter{f}, expansion: expansion, nonScaling: nonScaling}, nil
+}
+
+// Bloom filter operations
+
+// BfReserve creates an empty Bloom filter at key, as with BF.RESERVE
+func BfReserve(key string, errorRate float64, capacity, expansion int64, nonScaling bool) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorItemExists
+	}
+	bf, err := newDbBloom(errorRate, capacity, expansion, nonScaling)
+	if err != nil {
+		return err
+	}
+	unsafeSet(key, bf)
+	return nil
+}
+
+// BfAdd adds items to the Bloom filter at key, creating it with the default parameters if needed, as with BF.ADD and BF.MADD.
+// It returns whether each item was added, as opposed to possibly existing already, up to the first item that could not be added on error.
+func BfAdd(key string, items []string) ([]bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	bf, err := unsafeGetBloom(key)
+	if err != nil {
+		return nil, err
+	}
+	if bf == nil {
+		bf, _ = newDbBloom(BloomDefaultErrorRate, BloomDefaultCapacity, BloomDefaultExpansion, false)
+		unsafeSet(key, bf)
+	}
+	res := make([]bool, 0, len(items))
+	for _, item := range items {
+		added, err := bf.add(bloomHash(item))
+		if err != nil {
+			return res, err
+		}
+		res = append(res, added)
+	}
+	return res, nil
+}
+
+// BfExists returns whether each of items may have been added to the Bloom filter at key, as with BF.EXISTS and BF.MEXISTS
+func BfExists(key string, items []string) ([]bool, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	bf, err := unsafeGetBloom(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]bool, len(items))
+	if bf == nil {
+		return res, nil
+	}
+	for i, item := range items {
+		res[i] = bf.has(bloomHash(item))
+	}
+	return res, nil
+}
+
+// BloomInfo describes a Bloom filter, as with BF.INFO. Expansion is 0 for a non-scaling filter.
+type BloomInfo struct {
+	Capacity  int64
+	Size      int64
+	Filters   int64
+	Items     int64
+	Expansion int64
+}
+
+func BfInfo(key string) (BloomInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	var info BloomInfo
+	bf, err := unsafeGetBloom(key)
+	if err != nil {
+		return info, err
+	}
+	if bf == nil {
+		return info, ErrorFilterNotFound
+	}
+	for _, f := range bf.filters {
+		info.Capacity += f.capacity
+		info.Size += int64(len(f.bits)) * 8
+		info.Items += f.items
+	}
+	info.Filters = int64(len(bf.filters))
+	if !bf.nonScaling {
+		info.Expansion = bf.expansion
+	}
+	return info, nil
+}
@@ -0,0 +1,188 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var (
+	ErrorCMSKeyExists = errors.New("CMS: key already exists")
+	ErrorCMSNoKey     = errors.New("CMS: key does not exist")
+	ErrorCMSOverflow  = errors.New("CMS: INCRBY overflow")
+	ErrorCMSMergeSize = errors.New("CMS: width/depth is not equal")
+	ErrorCMSTooLarge  = errors.New("CMS: width/depth too large")
+)
+
+// cmsMaxCounters bounds the number of counters of a sketch
+const cmsMaxCounters = 1 << 28
+
+// DbCMS is a Count-Min Sketch of depth rows of width 32 bit counters. Each item increments one counter per row,
+// and its count is estimated as the least of them.
+type DbCMS struct {
+	dbExpiry
+	width    int64
+	depth    int64
+	counters []uint32
+	// count is the total of every increment
+	count int64
+}
+
+var _ DbValue = (*DbCMS)(nil)
+
+func (v *DbCMS) Type() string {
+	return "CMSk-TYPE"
+}
+
+func newDbCMS(width, depth int64) (*DbCMS, error) {
+	if width > cmsMaxCounters/depth {
+		return nil, ErrorCMSTooLarge
+	}
+	return &DbCMS{width: width, depth: depth, counters: make([]uint32, width*depth)}, nil
+}
+
+// cells returns the index of the counter of item in each row
+func (v *DbCMS) cells(item string) []int64 {
+	cells := make([]int64, v.depth)
+	for i := range cells {
+		cells[i] = int64(i)*v.width + int64(murmurHash64A(item, uint64(i))%uint64(v.width))
+	}
+	return cells
+}
+
+func (v *DbCMS) query(cells []int64) int64 {
+	res := int64(math.MaxUint32)
+	for _, c := range cells {
+		res = min(res, int64(v.counters[c]))
+	}
+	return res
+}
+
+// unsafeGetCMS returns the sketch stored at key, or ErrorCMSNoKey if there is none. The caller must hold DbMu.
+func unsafeGetCMS(key string) (*DbCMS, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, ErrorCMSNoKey
+	}
+	cms, ok := v.(*DbCMS)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return cms, nil
+}
+
+// Count-Min Sketch operations
+
+// CmsInit creates an empty sketch at key, as with CMS.INITBYDIM and CMS.INITBYPROB
+func CmsInit(key string, width, depth int64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorCMSKeyExists
+	}
+	cms, err := newDbCMS(width, depth)
+	if err != nil {
+		return err
+	}
+	unsafeSet(key, cms)
+	return nil
+}
+
+// CmsIncrby increments the count of each item by the increment at the same index and returns the new estimates, as with CMS.INCRBY.
+// Nothing is incremented if any counter would overflow.
+func CmsIncrby(key string, items []string, increments []int64) ([]int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	cms, err := unsafeGetCMS(key)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([][]int64, len(items))
+	totals := make(map[int64]int64)
+	for i, item := range items {
+		cells[i] = cms.cells(item)
+		for _, c := range cells[i] {
+			totals[c] += increments[i]
+			if int64(cms.counters[c])+totals[c] > math.MaxUint32 {
+				return nil, ErrorCMSOverflow
+			}
+		}
+	}
+	res := make([]int64, len(items))
+	for i := range items {
+		for _, c := range cells[i] {
+			cms.counters[c] += uint32(increments[i])
+		}
+		cms.count += increments[i]
+		res[i] = cms.query(cells[i])
+	}
+	return res, nil
+}
+
+// CmsQuery returns the estimated count of each item, as with CMS.QUERY
+func CmsQuery(key string, items []string) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	cms, err := unsafeGetCMS(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, len(items))
+	for i, item := range items {
+		res[i] = cms.query(cms.cells(item))
+	}
+	return res, nil
+}
+
+// CmsMerge overwrites the sketch at dest with the sum of the sketches at sources multiplied by their weights, as with CMS.MERGE.
+// Every sketch must exist and have the same dimensions.
+func CmsMerge(dest string, sources []string, weights []int64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	cms, err := unsafeGetCMS(dest)
+	if err != nil {
+		return err
+	}
+	counters := make([]int64, len(cms.counters))
+	var count int64
+	for i, key := range sources {
+		src, err := unsafeGetCMS(key)
+		if err != nil {
+			return err
+		}
+		if src.width != cms.width || src.depth != cms.depth {
+			return ErrorCMSMergeSize
+		}
+		for j, c := range src.counters {
+			counters[j] += int64(c) * weights[i]
+		}
+		count += src.count * weights[i]
+	}
+	for _, c := range counters {
+		if c < 0 || c > math.MaxUint32 {
+			return ErrorCMSOverflow
+		}
+	}
+	for j, c := range counters {
+		cms.counters[j] = uint32(c)
+	}
+	cms.count = count
+	return nil
+}
+
+// CMSInfo describes a sketch, as with CMS.INFO
+type CMSInfo struct {
+	Width int64
+	Depth int64
+	Count int64
+}
+
+func CmsInfo(key string) (CMSInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	cms, err := unsafeGetCMS(key)
+	if err != nil {
+		return CMSInfo{}, err
+	}
+	return CMSInfo{Width: cms.width, Depth: cms.depth, Count: cms.count}, nil
+}
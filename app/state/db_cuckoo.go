@@ -0,0 +1,279 @@
+package state
+
+import (
+	"errors"
+	"math/bits"
+	"time"
+)
+
+const (
+	CuckooDefaultCapacity      = 1024
+	CuckooDefaultBucketSize    = 2
+	CuckooDefaultMaxIterations = 20
+	CuckooDefaultExpansion     = 1
+	// cuckooAltMultiplier scrambles a fingerprint into the offset between the two buckets of an item
+	cuckooAltMultiplier = 0x5bd1e995
+)
+
+var (
+	ErrorCuckooFull        = errors.New("ERR Filter is full")
+	ErrorCuckooMaxCapacity = errors.New("ERR filter capacity is too large")
+)
+
+// cuckooMaxSlots bounds the number of slots of a sub-filter
+const cuckooMaxSlots = 1 << 30
+
+// DbCuckoo is a scalable cuckoo filter of 8 bit fingerprints, which adds a sub-filter with expansion times the buckets whenever an item
+// cannot be placed in the last one, unless expansion is 0. Items are looked up in every sub-filter.
+// Victims are evicted in a fixed rotation rather than at random, so that replicas adding the same items end up with the same filter.
+type DbCuckoo struct {
+	dbExpiry
+	filters       []*cuckooFilter
+	bucketSize    int
+	maxIterations int
+	expansion     int
+}
+
+var _ DbValue = (*DbCuckoo)(nil)
+
+func (v *DbCuckoo) Type() string {
+	return "MBbloomCF"
+}
+
+// cuckooFilter stores fingerprints in numBuckets buckets of bucketSize slots, where 0 marks an empty slot.
+// An item may be stored in either of two buckets, the second of which is the first xored with a hash of its fingerprint.
+type cuckooFilter struct {
+	slots      []uint8
+	numBuckets uint64
+	bucketSize int
+}
+
+func newCuckooFilter(numBuckets uint64, bucketSize int) (*cuckooFilter, error) {
+	if numBuckets > cuckooMaxSlots/uint64(bucketSize) {
+		return nil, ErrorCuckooMaxCapacity
+	}
+	return &cuckooFilter{slots: make([]uint8, numBuckets*uint64(bucketSize)), numBuckets: numBuckets, bucketSize: bucketSize}, nil
+}
+
+// cuckooHash returns the hash and the non-zero fingerprint of item
+func cuckooHash(item string) (uint64, uint8) {
+	h := murmurHash64A(item, 0)
+	return h, uint8(h%255 + 1)
+}
+
+func (f *cuckooFilter) buckets(h uint64, fp uint8) (uint64, uint64) {
+	i := h % f.numBuckets
+	return i, f.alt(i, fp)
+}
+
+func (f *cuckooFilter) alt(i uint64, fp uint8) uint64 {
+	return (i ^ uint64(fp)*cuckooAltMultiplier) % f.numBuckets
+}
+
+func (f *cuckooFilter) bucket(i uint64) []uint8 {
+	return f.slots[i*uint64(f.bucketSize) : (i+1)*uint64(f.bucketSize)]
+}
+
+// place stores fp in a free slot of bucket i, returning false if there is none
+func (f *cuckooFilter) place(i uint64, fp uint8) bool {
+	b := f.bucket(i)
+	for j, s := range b {
+		if s == 0 {
+			b[j] = fp
+			return true
+		}
+	}
+	return false
+}
+
+func (f *cuckooFilter) count(h uint64, fp uint8) int64 {
+	i1, i2 := f.buckets(h, fp)
+	var n int64
+	for _, i := range []uint64{i1, i2} {
+		for _, s := range f.bucket(i) {
+			if s == fp {
+				n++
+			}
+		}
+		if i1 == i2 {
+			break
+		}
+	}
+	return n
+}
+
+func (f *cuckooFilter) remove(h uint64, fp uint8) bool {
+	i1, i2 := f.buckets(h, fp)
+	for _, i := range []uint64{i1, i2} {
+		b := f.bucket(i)
+		for j, s := range b {
+			if s == fp {
+				b[j] = 0
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// kick makes room for fp in bucket i by evicting fingerprints to their other buckets for up to maxIterations moves.
+// If no room is found, every move is undone and false is returned.
+func (f *cuckooFilter) kick(i uint64, fp uint8, maxIterations int) bool {
+	type move struct {
+		i  uint64
+		j  int
+		fp uint8
+	}
+	moves := make([]move, 0, maxIterations)
+	victim := 0
+	for range maxIterations {
+		b := f.bucket(i)
+		moves = append(moves, move{i, victim, b[victim]})
+		fp, b[victim] = b[victim], fp
+		i = f.alt(i, fp)
+		if f.place(i, fp) {
+			return true
+		}
+		victim = (victim + 1) % f.bucketSize
+	}
+	for k := len(moves) - 1; k >= 0; k-- {
+		m := moves[k]
+		f.bucket(m.i)[m.j] = m.fp
+	}
+	return false
+}
+
+// add adds an item with the hash h and fingerprint fp
+func (v *DbCuckoo) add(h uint64, fp uint8) error {
+	for k := len(v.filters) - 1; k >= 0; k-- {
+		f := v.filters[k]
+		i1, i2 := f.buckets(h, fp)
+		if f.place(i1, fp) || f.place(i2, fp) {
+			return nil
+		}
+	}
+	last := v.filters[len(v.filters)-1]
+	i1, _ := last.buckets(h, fp)
+	if last.kick(i1, fp, v.maxIterations) {
+		return nil
+	}
+	if v.expansion == 0 {
+		return ErrorCuckooFull
+	}
+	f, err := newCuckooFilter(last.numBuckets*uint64(v.expansion), v.bucketSize)
+	if err != nil {
+		return ErrorCuckooFull
+	}
+	v.filters = append(v.filters, f)
+	i1, _ = f.buckets(h, fp)
+	f.place(i1, fp)
+	return nil
+}
+
+func (v *DbCuckoo) count(h uint64, fp uint8) int64 {
+	var n int64
+	for _, f := range v.filters {
+		n += f.count(h, fp)
+	}
+	return n
+}
+
+// nextPowerOfTwo returns the smallest power of two no less than n, or 0 for 0
+func nextPowerOfTwo(n uint64) uint64 {
+	if n <= 1 {
+		return n
+	}
+	return 1 << bits.Len64(n-1)
+}
+
+func newDbCuckoo(capacity int64, bucketSize, maxIterations, expansion int) (*DbCuckoo, error) {
+	f, err := newCuckooFilter(max(1, nextPowerOfTwo(uint64(capacity)/uint64(bucketSize))), bucketSize)
+	if err != nil {
+		return nil, err
+	}
+	return &DbCuckoo{
+		filters:       []*cuckooFilter{f},
+		bucketSize:    bucketSize,
+		maxIterations: maxIterations,
+		expansion:     int(nextPowerOfTwo(uint64(expansion))),
+	}, nil
+}
+
+// unsafeGetCuckoo returns the cuckoo filter stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetCuckoo(key string) (*DbCuckoo, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	cf, ok := v.(*DbCuckoo)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return cf, nil
+}
+
+// Cuckoo filter operations
+
+// CfReserve creates an empty cuckoo filter at key, as with CF.RESERVE. The expansion is rounded up to a power of two.
+func CfReserve(key string, capacity int64, bucketSize, maxIterations, expansion int) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorItemExists
+	}
+	cf, err := newDbCuckoo(capacity, bucketSize, maxIterations, expansion)
+	if err != nil {
+		return err
+	}
+	unsafeSet(key, cf)
+	return nil
+}
+
+// CfAdd adds item to the cuckoo filter at key, creating it with the default parameters if needed, as with CF.ADD.
+// An item may be added multiple times.
+func CfAdd(key, item string) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	cf, err := unsafeGetCuckoo(key)
+	if err != nil {
+		return err
+	}
+	if cf == nil {
+		if cf, err = newDbCuckoo(CuckooDefaultCapacity, CuckooDefaultBucketSize, CuckooDefaultMaxIterations, CuckooDefaultExpansion); err != nil {
+			return err
+		}
+		unsafeSet(key, cf)
+	}
+	return cf.add(cuckooHash(item))
+}
+
+// CfDel removes one occurrence of item from the cuckoo filter at key, searching the newest sub-filters first, as with CF.DEL
+func CfDel(key, item string) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	cf, err := unsafeGetCuckoo(key)
+	if err != nil {
+		return false, err
+	}
+	if cf == nil {
+		return false, ErrorFilterNotFound
+	}
+	h, fp := cuckooHash(item)
+	for k := len(cf.filters) - 1; k >= 0; k-- {
+		if cf.filters[k].remove(h, fp) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CfCount returns the number of times item may have been added to the cuckoo filter at key, as with CF.COUNT and CF.EXISTS
+func CfCount(key, item string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	cf, err := unsafeGetCuckoo(key)
+	if err != nil || cf == nil {
+		return 0, err
+	}
+	return cf.count(cuckooHash(item)), nil
+}
@@ -0,0 +1,94 @@
+package state
+
+import (
+	"time"
+)
+
+// dbExpiry is the expiry of a key, embedded in every DbValue. A zero expiresAt means the key does not expire.
+type dbExpiry struct {
+	expiresAt time.Time
+}
+
+func (e *dbExpiry) ExpiresAt() time.Time {
+	return e.expiresAt
+}
+
+func (e *dbExpiry) setExpiresAt(t time.Time) {
+	e.expiresAt = t
+}
+
+// IsDefinitelyExpiredAt reports whether the value has expired at t and may be evicted, which only the master does
+func (e *dbExpiry) IsDefinitelyExpiredAt(t time.Time) bool {
+	return !IsReplica() && e.isExpiredAt(t)
+}
+
+// isExpiredAt reports whether the value has logically expired at t, irrespective of whether it may be evicted yet.
+func (e *dbExpiry) isExpiredAt(t time.Time) bool {
+	return !e.expiresAt.IsZero() && e.expiresAt.Before(t)
+}
+
+// expiryCondMet reports whether an expiry of current may be replaced by at subject to cond, which is one of "", "NX", "XX", "GT" and "LT".
+// No expiry is taken to be an expiry at infinity for the purposes of GT and LT.
+func expiryCondMet(current, at time.Time, cond string) bool {
+	switch cond {
+	case "NX":
+		return current.IsZero()
+	case "XX":
+		return !current.IsZero()
+	case "GT":
+		return !current.IsZero() && at.After(current)
+	case "LT":
+		return current.IsZero() || at.Before(current)
+	}
+	return true
+}
+
+// Expire sets the expiry of key to at, subject to every one of conds, which are among "NX", "XX", "GT" and "LT". The key is deleted outright
+// if at is not in the future, unless this is a replica, which waits for the master to delete it instead. It returns whether the expiry
+// was set, and whether the key was deleted in doing so.
+func Expire(key string, at time.Time, conds ...string) (bool, bool) {
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	v, ok := unsafeLookup(key, now)
+	if !ok {
+		return false, false
+	}
+	for _, cond := range conds {
+		if !expiryCondMet(v.ExpiresAt(), at, cond) {
+			return false, false
+		}
+	}
+	if !IsReplica() && !at.After(now) {
+		unsafeDelete(key, now)
+		return true, true
+	}
+	v.setExpiresAt(at)
+	return true, false
+}
+
+// ExpireTime returns the expiry of key as a unix time in milliseconds, or -2 if there is no value at key and -1 if it does not expire
+func ExpireTime(key string) int64 {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	v, ok := unsafeLookup(key, time.Now())
+	switch {
+	case !ok:
+		return -2
+	case v.ExpiresAt().IsZero():
+		return -1
+	}
+	return v.ExpiresAt().UnixMilli()
+}
+
+// Persist clears the expiry of key, and returns whether it had one
+func Persist(key string) bool {
+	LockDbMu()
+	defer UnlockDbMu()
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok || v.ExpiresAt().IsZero() {
+		return false
+	}
+	v.setExpiresAt(time.Time{})
+	return true
+}
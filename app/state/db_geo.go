@@ -0,0 +1,422 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// Geospatial indexes are sorted sets whose scores are 52 bit geohashes, interleaving 26 bits of latitude (in the even bits) and 26 bits of
+// longitude (in the odd bits), with latitudes limited to the range of the Web Mercator projection, as in Redis
+const (
+	geoStep       = 26
+	GeoLongMin    = -180.0
+	GeoLongMax    = 180.0
+	GeoLatMin     = -85.05112878
+	GeoLatMax     = 85.05112878
+	geoEarthR     = 6372797.560856
+	geoMercatorR  = 20037726.37
+	geoAlphabet   = "0123456789bcdefghjkmnpqrstuvwxyz"
+	geoHashLength = 11
+)
+
+var (
+	ErrorGeoMember = errors.New("ERR could not decode requested zset member")
+)
+
+// GeoPoint is a longitude and latitude in degrees
+type GeoPoint struct {
+	Long, Lat float64
+}
+
+// ValidGeoPoint reports whether p can be indexed
+func ValidGeoPoint(p GeoPoint) bool {
+	return p.Long >= GeoLongMin && p.Long <= GeoLongMax && p.Lat >= GeoLatMin && p.Lat <= GeoLatMax
+}
+
+func spreadBits(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+func compactBits(x uint64) uint32 {
+	x &= 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x>>4) & 0x00ff00ff00ff00ff
+	x = (x | x>>8) & 0x0000ffff0000ffff
+	x = (x | x>>16) & 0x00000000ffffffff
+	return uint32(x)
+}
+
+// geoCell is a cell of the grid of 2^step by 2^step cells over the latitude and longitude ranges
+type geoCell struct {
+	lat, long uint32
+	step      uint
+}
+
+// geoCellOf returns the cell containing p at step, within the latitude range [latMin, latMax]
+func geoCellOf(p GeoPoint, latMin, latMax float64, step uint) geoCell {
+	latOffset := (p.Lat - latMin) / (latMax - latMin) * float64(uint64(1)<<step)
+	longOffset := (p.Long - GeoLongMin) / (GeoLongMax - GeoLongMin) * float64(uint64(1)<<step)
+	return geoCell{lat: uint32(latOffset), long: uint32(longOffset), step: step}
+}
+
+func geoCellOfHash(hash uint64, step uint) geoCell {
+	return geoCell{lat: compactBits(hash), long: compactBits(hash >> 1), step: step}
+}
+
+func (c geoCell) hash() uint64 {
+	return spreadBits(c.lat) | spreadBits(c.long)<<1
+}
+
+// move returns the cell dlat cells north and dlong cells east of c, wrapping around
+func (c geoCell) move(dlat, dlong int) geoCell {
+	mask := uint32(1)<<c.step - 1
+	return geoCell{lat: (c.lat + uint32(dlat)) & mask, long: (c.long + uint32(dlong)) & mask, step: c.step}
+}
+
+// bounds returns the corners of c within the Web Mercator latitude range
+func (c geoCell) bounds() (GeoPoint, GeoPoint) {
+	n := float64(uint64(1) << c.step)
+	min := GeoPoint{
+		Long: GeoLongMin + float64(c.long)/n*(GeoLongMax-GeoLongMin),
+		Lat:  GeoLatMin + float64(c.lat)/n*(GeoLatMax-GeoLatMin),
+	}
+	max := GeoPoint{
+		Long: GeoLongMin + float64(c.long+1)/n*(GeoLongMax-GeoLongMin),
+		Lat:  GeoLatMin + float64(c.lat+1)/n*(GeoLatMax-GeoLatMin),
+	}
+	return min, max
+}
+
+// scoreRange returns the range [min, max) of the scores of points within c
+func (c geoCell) scoreRange() (float64, float64) {
+	shift := 2 * (geoStep - c.step)
+	return float64(c.hash() << shift), float64((c.hash() + 1) << shift)
+}
+
+// GeoScore returns the score with which p is indexed
+func GeoScore(p GeoPoint) float64 {
+	return float64(geoCellOf(p, GeoLatMin, GeoLatMax, geoStep).hash())
+}
+
+// geoDecode returns the center of the cell of the geohash score
+func geoDecode(score float64) GeoPoint {
+	min, max := geoCellOfHash(uint64(score), geoStep).bounds()
+	return GeoPoint{
+		Long: math.Max(GeoLongMin, math.Min(GeoLongMax, (min.Long+max.Long)/2)),
+		Lat:  math.Max(GeoLatMin, math.Min(GeoLatMax, (min.Lat+max.Lat)/2)),
+	}
+}
+
+// geoHashString returns the standard geohash of the point indexed with score, which uses the full latitude range
+func geoHashString(score float64) string {
+	hash := geoCellOf(geoDecode(score), -90, 90, geoStep).hash()
+	b := make([]byte, geoHashLength)
+	for i := range b {
+		idx := 0
+		// the last character has only 52 - 50 bits of the hash, and is taken to be 0 as in Redis
+		if i < geoHashLength-1 {
+			idx = int(hash>>(2*geoStep-(i+1)*5)) & 0x1f
+		}
+		b[i] = geoAlphabet[idx]
+	}
+	return string(b)
+}
+
+func degRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// geoLatDistance returns the distance in meters between two latitudes
+func geoLatDistance(lat1, lat2 float64) float64 {
+	return geoEarthR * math.Abs(degRad(lat2)-degRad(lat1))
+}
+
+// GeoDistance returns the distance in meters between p and q by the haversine formula
+func GeoDistance(p, q GeoPoint) float64 {
+	v := math.Sin((degRad(q.Long) - degRad(p.Long)) / 2)
+	if v == 0 {
+		return geoLatDistance(p.Lat, q.Lat)
+	}
+	u := math.Sin((degRad(q.Lat) - degRad(p.Lat)) / 2)
+	a := u*u + math.Cos(degRad(p.Lat))*math.Cos(degRad(q.Lat))*v*v
+	return 2 * geoEarthR * math.Asin(math.Sqrt(a))
+}
+
+type GeoSort int
+
+const (
+	GeoSortNone GeoSort = iota
+	GeoSortAsc
+	GeoSortDesc
+)
+
+// GeoSearchSpec describes a search of a geospatial index, as with GEOSEARCH.
+// The search is from the position of FromMember if it is set, or else From, within Radius if Box is not set, or else the Width by Height box.
+// Distances are in units of Conversion meters.
+type GeoSearchSpec struct {
+	FromMember    *string
+	From          GeoPoint
+	Box           bool
+	Radius        float64
+	Width, Height float64
+	Conversion    float64
+	Sort          GeoSort
+	// Count is the maximum number of results if it is not 0, which are any results found if Any is set, rather than the nearest
+	Count int64
+	Any   bool
+}
+
+// contains reports whether p is within the search area centered at center, and returns its distance from center in meters
+func (spec GeoSearchSpec) contains(center, p GeoPoint) (float64, bool) {
+	if !spec.Box {
+		d := GeoDistance(center, p)
+		return d, d <= spec.Radius*spec.Conversion
+	}
+	if geoLatDistance(p.Lat, center.Lat) > spec.Height*spec.Conversion/2 {
+		return 0, false
+	}
+	if GeoDistance(p, GeoPoint{Long: center.Long, Lat: p.Lat}) > spec.Width*spec.Conversion/2 {
+		return 0, false
+	}
+	return GeoDistance(center, p), true
+}
+
+// boundingBox returns the corners of a box containing the search area centered at center
+func (spec GeoSearchSpec) boundingBox(center GeoPoint) (GeoPoint, GeoPoint) {
+	height, width := spec.Radius, spec.Radius
+	if spec.Box {
+		height, width = spec.Height/2, spec.Width/2
+	}
+	height *= spec.Conversion
+	width *= spec.Conversion
+	latDelta := radDeg(height / geoEarthR)
+	longDeltaTop := radDeg(width / geoEarthR / math.Cos(degRad(center.Lat+latDelta)))
+	longDeltaBottom := radDeg(width / geoEarthR / math.Cos(degRad(center.Lat-latDelta)))
+	// the box widens towards the pole
+	longDelta := longDeltaTop
+	if center.Lat < 0 {
+		longDelta = longDeltaBottom
+	}
+	return GeoPoint{Long: center.Long - longDelta, Lat: center.Lat - latDelta}, GeoPoint{Long: center.Long + longDelta, Lat: center.Lat + latDelta}
+}
+
+// geoSearchStep estimates the step at which the cell containing a point and its neighbors cover the area within radius meters of it
+func geoSearchStep(radius, lat float64) uint {
+	if radius == 0 {
+		return geoStep
+	}
+	step := 1
+	for ; radius < geoMercatorR; radius *= 2 {
+		step++
+	}
+	step -= 2
+	// cells are narrower towards the poles
+	if lat > 66 || lat < -66 {
+		step--
+		if lat > 80 || lat < -80 {
+			step--
+		}
+	}
+	return uint(max(1, min(geoStep, step)))
+}
+
+// cells returns the cells covering the search area centered at center, in the order in which Redis searches them
+func (spec GeoSearchSpec) cells(center GeoPoint) []geoCell {
+	radius := spec.Radius
+	if spec.Box {
+		radius = math.Sqrt(spec.Width/2*spec.Width/2 + spec.Height/2*spec.Height/2)
+	}
+	step := geoSearchStep(radius*spec.Conversion, center.Lat)
+	min, max := spec.boundingBox(center)
+	c := geoCellOf(center, GeoLatMin, GeoLatMax, step)
+	// the neighbors at the estimated step may not reach the edges of the bounding box, in which case larger cells are used
+	_, north := c.move(1, 0).bounds()
+	south, _ := c.move(-1, 0).bounds()
+	_, east := c.move(0, 1).bounds()
+	west, _ := c.move(0, -1).bounds()
+	if step > 1 && (north.Lat < max.Lat || south.Lat > min.Lat || east.Long < max.Long || west.Long > min.Long) {
+		step--
+		c = geoCellOf(center, GeoLatMin, GeoLatMax, step)
+	}
+	// exclude the neighbors beyond the bounding box on each side
+	cmin, cmax := c.bounds()
+	useful := func(dlat, dlong int) bool {
+		if step < 2 {
+			return true
+		}
+		return !(dlat < 0 && cmin.Lat < min.Lat || dlat > 0 && cmax.Lat > max.Lat ||
+			dlong < 0 && cmin.Long < min.Long || dlong > 0 && cmax.Long > max.Long)
+	}
+	cells := []geoCell{c}
+	for _, d := range [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		if n := c.move(d[0], d[1]); useful(d[0], d[1]) && !slices.Contains(cells, n) {
+			cells = append(cells, n)
+		}
+	}
+	return cells
+}
+
+// GeoResult is a point found by a search, whose distance is in the units of the search
+type GeoResult struct {
+	Member string
+	Score  float64
+	Dist   float64
+	GeoPoint
+}
+
+// unsafeGeoSearch searches the geospatial index at key. The caller must hold DbMu.
+func unsafeGeoSearch(key string, spec GeoSearchSpec) ([]GeoResult, error) {
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return []GeoResult{}, err
+	}
+	center := spec.From
+	if spec.FromMember != nil {
+		score, ok := z.Score(*spec.FromMember)
+		if !ok {
+			return nil, ErrorGeoMember
+		}
+		center = geoDecode(score)
+	}
+	res := []GeoResult{}
+cells:
+	for _, c := range spec.cells(center) {
+		min, max := c.scoreRange()
+		for _, m := range z.Range(ZRangeSpec{By: ZRangeByScore, Score: ZScoreRange{Min: min, Max: max, MaxExclusive: true}, Count: -1}) {
+			p := geoDecode(m.Score)
+			d, ok := spec.contains(center, p)
+			if !ok {
+				continue
+			}
+			res = append(res, GeoResult{Member: m.Member, Score: m.Score, Dist: d / spec.Conversion, GeoPoint: p})
+			if spec.Any && int64(len(res)) == spec.Count {
+				break cells
+			}
+		}
+	}
+	switch spec.Sort {
+	case GeoSortAsc:
+		slices.SortStableFunc(res, func(a, b GeoResult) int { return cmpFloat(a.Dist, b.Dist) })
+	case GeoSortDesc:
+		slices.SortStableFunc(res, func(a, b GeoResult) int { return cmpFloat(b.Dist, a.Dist) })
+	}
+	if spec.Count != 0 && int64(len(res)) > spec.Count {
+		res = res[:spec.Count]
+	}
+	return res, nil
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Geospatial operations
+
+// Geopos returns the positions of members, with nil for those that are missing
+func Geopos(key string, members []string) ([]*GeoPoint, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*GeoPoint, len(members))
+	if z == nil {
+		return res, nil
+	}
+	for i, m := range members {
+		if score, ok := z.Score(m); ok {
+			p := geoDecode(score)
+			res[i] = &p
+		}
+	}
+	return res, nil
+}
+
+// Geodist returns the distance in meters between two members, or ErrorNone if either is missing
+func Geodist(key, member1, member2 string) (float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return 0, err
+	}
+	if z == nil {
+		return 0, ErrorNone
+	}
+	score1, ok1 := z.Score(member1)
+	score2, ok2 := z.Score(member2)
+	if !ok1 || !ok2 {
+		return 0, ErrorNone
+	}
+	return GeoDistance(geoDecode(score1), geoDecode(score2)), nil
+}
+
+// Geohash returns the standard geohashes of members, with nil for those that are missing
+func Geohash(key string, members []string) ([]*string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*string, len(members))
+	if z == nil {
+		return res, nil
+	}
+	for i, m := range members {
+		if score, ok := z.Score(m); ok {
+			hash := geoHashString(score)
+			res[i] = &hash
+		}
+	}
+	return res, nil
+}
+
+// Geosearch returns the members of the geospatial index at key within the area described by spec
+func Geosearch(key string, spec GeoSearchSpec) ([]GeoResult, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	return unsafeGeoSearch(key, spec)
+}
+
+// Geosearchstore stores the members of the geospatial index at src within the area described by spec at dst, with their distances as
+// scores if storeDist is set, returning the number of members stored.
+// It also returns the commands effecting the pops of any clients blocked on dst that were served as a result.
+func Geosearchstore(dst, src string, spec GeoSearchSpec, storeDist bool) (int64, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	found, err := unsafeGeoSearch(src, spec)
+	if err != nil {
+		return 0, nil, err
+	}
+	res := NewDbZSet()
+	for _, r := range found {
+		if storeDist {
+			res.Add(r.Member, r.Dist)
+		} else {
+			res.Add(r.Member, r.Score)
+		}
+	}
+	return unsafeStoreZSet(dst, res)
+}
@@ -0,0 +1,503 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+const (
+	// hashMaxCompactEntries is the number of fields beyond which a DbHash is converted from its compact encoding to a map
+	hashMaxCompactEntries = 128
+	// hashMaxCompactValue is the length of a field or value beyond which a DbHash is converted from its compact encoding to a map
+	hashMaxCompactValue = 64
+)
+
+var (
+	ErrorHashNotInteger = errors.New("ERR hash value is not an integer")
+	ErrorHashNotFloat   = errors.New("ERR hash value is not a float")
+	ErrorOverflow       = errors.New("ERR increment or decrement would overflow")
+	ErrorNaNOrInfinity  = errors.New("ERR increment would produce NaN or Infinity")
+)
+
+// DbHash stores small hashes compactly as a slice of alternating fields and values, which is scanned linearly,
+// and converts itself to a map once it grows past hashMaxCompactEntries fields or holds a field or value longer than hashMaxCompactValue.
+//
+// Fields may individually expire. Fields that have logically expired are hidden from all accessors, but remain stored
+// until the master reclaims them and propagates their deletion, so that replicas never expire fields on their own.
+type DbHash struct {
+	dbExpiry
+	// pairs is nil once the hash has been converted to a map
+	pairs []string
+	m     *dict[string]
+	// expires is nil if no field has an expiry
+	expires map[string]time.Time
+	// minExpiry is the earliest expiry in expires, or zero if it is nil, so that the fields need only be checked one by one
+	// once some of them may have expired
+	minExpiry time.Time
+}
+
+var _ DbValue = (*DbHash)(nil)
+
+func NewDbHash() *DbHash {
+	return &DbHash{pairs: make([]string, 0, 8)}
+}
+
+func (v *DbHash) Type() string {
+	return "hash"
+}
+
+func (v *DbHash) isCompact() bool {
+	return v.m == nil
+}
+
+func (v *DbHash) Len() int {
+	return v.lenAt(time.Now())
+}
+
+func (v *DbHash) lenAt(t time.Time) int {
+	n := len(v.pairs) / 2
+	if !v.isCompact() {
+		n = v.m.Len()
+	}
+	if !v.mayHaveExpiredFieldsAt(t) {
+		return n
+	}
+	for _, at := range v.expires {
+		if at.Before(t) {
+			n--
+		}
+	}
+	return n
+}
+
+// isExpiredAt reports whether the hash has logically expired at t, either as a key or because every one of its fields has
+func (v *DbHash) isExpiredAt(t time.Time) bool {
+	return v.dbExpiry.isExpiredAt(t) || v.expires != nil && v.lenAt(t) == 0
+}
+
+// mayHaveExpiredFieldsAt reports whether any field may have logically expired at t
+func (v *DbHash) mayHaveExpiredFieldsAt(t time.Time) bool {
+	return !v.minExpiry.IsZero() && v.minExpiry.Before(t)
+}
+
+func (v *DbHash) isFieldExpiredAt(field string, t time.Time) bool {
+	at, ok := v.expires[field]
+	return ok && at.Before(t)
+}
+
+func (v *DbHash) indexOf(field string) int {
+	for i := 0; i < len(v.pairs); i += 2 {
+		if v.pairs[i] == field {
+			return i
+		}
+	}
+	return -1
+}
+
+func (v *DbHash) Get(field string) (string, bool) {
+	if v.isFieldExpiredAt(field, time.Now()) {
+		return "", false
+	}
+	if !v.isCompact() {
+		return v.m.Get(field)
+	}
+	if i := v.indexOf(field); i != -1 {
+		return v.pairs[i+1], true
+	}
+	return "", false
+}
+
+// Set sets field to value and clears its expiry, returning true if the field is new
+func (v *DbHash) Set(field, value string) bool {
+	_, existed := v.Get(field)
+	v.Persist(field)
+	v.update(field, value)
+	return !existed
+}
+
+// update sets field to value, retaining its expiry
+func (v *DbHash) update(field, value string) bool {
+	if !v.isCompact() {
+		return v.m.Set(field, value)
+	}
+	if i := v.indexOf(field); i != -1 {
+		v.pairs[i+1] = value
+		v.convertIfNeeded(field, value)
+		return false
+	}
+	v.pairs = append(v.pairs, field, value)
+	v.convertIfNeeded(field, value)
+	return true
+}
+
+func (v *DbHash) convertIfNeeded(field, value string) {
+	if len(v.pairs)/2 <= hashMaxCompactEntries && len(field) <= hashMaxCompactValue && len(value) <= hashMaxCompactValue {
+		return
+	}
+	v.m = newDict[string](len(v.pairs))
+	for i := 0; i < len(v.pairs); i += 2 {
+		v.m.Set(v.pairs[i], v.pairs[i+1])
+	}
+	v.pairs = nil
+}
+
+// Delete deletes field, returning true if it was present and had not expired
+func (v *DbHash) Delete(field string) bool {
+	_, existed := v.Get(field)
+	v.Persist(field)
+	v.remove(field)
+	return existed
+}
+
+func (v *DbHash) remove(field string) bool {
+	if !v.isCompact() {
+		return v.m.Delete(field)
+	}
+	i := v.indexOf(field)
+	if i == -1 {
+		return false
+	}
+	v.pairs = append(v.pairs[:i], v.pairs[i+2:]...)
+	return true
+}
+
+// Entries returns the fields and values of the hash alternately
+func (v *DbHash) Entries() []string {
+	now := time.Now()
+	if v.isCompact() {
+		entries := make([]string, 0, len(v.pairs))
+		for i := 0; i < len(v.pairs); i += 2 {
+			if !v.isFieldExpiredAt(v.pairs[i], now) {
+				entries = append(entries, v.pairs[i], v.pairs[i+1])
+			}
+		}
+		return entries
+	}
+	entries := make([]string, 0, 2*v.m.Len())
+	v.m.Each(func(field, value string) bool {
+		if !v.isFieldExpiredAt(field, now) {
+			entries = append(entries, field, value)
+		}
+		return true
+	})
+	return entries
+}
+
+// Expiry returns the expiry of field, which is zero if the field has none. It returns false if there is no such field.
+func (v *DbHash) Expiry(field string) (time.Time, bool) {
+	if _, ok := v.Get(field); !ok {
+		return time.Time{}, false
+	}
+	return v.expires[field], true
+}
+
+func (v *DbHash) SetExpiry(field string, at time.Time) {
+	if v.expires == nil {
+		v.expires = make(map[string]time.Time)
+	}
+	prev, ok := v.expires[field]
+	v.expires[field] = at
+	switch {
+	case v.minExpiry.IsZero() || at.Before(v.minExpiry):
+		v.minExpiry = at
+	case ok && prev.Equal(v.minExpiry):
+		v.updateMinExpiry()
+	}
+}
+
+// Persist clears the expiry of field, returning true if it had one
+func (v *DbHash) Persist(field string) bool {
+	at, ok := v.expires[field]
+	if !ok {
+		return false
+	}
+	delete(v.expires, field)
+	if len(v.expires) == 0 {
+		v.expires = nil
+	}
+	if at.Equal(v.minExpiry) {
+		v.updateMinExpiry()
+	}
+	return true
+}
+
+// updateMinExpiry works out minExpiry again, once the field that held it has changed
+func (v *DbHash) updateMinExpiry() {
+	v.minExpiry = time.Time{}
+	for _, at := range v.expires {
+		if v.minExpiry.IsZero() || at.Before(v.minExpiry) {
+			v.minExpiry = at
+		}
+	}
+}
+
+// evictExpired removes the fields that have logically expired at t, returning them
+func (v *DbHash) evictExpired(t time.Time) []string {
+	if !v.mayHaveExpiredFieldsAt(t) {
+		return nil
+	}
+	var fields []string
+	for field, at := range v.expires {
+		if at.Before(t) {
+			fields = append(fields, field)
+			delete(v.expires, field)
+			v.remove(field)
+		}
+	}
+	if len(v.expires) == 0 {
+		v.expires = nil
+	}
+	v.updateMinExpiry()
+	return fields
+}
+
+// unsafeGetHash returns the hash stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetHash(key string) (*DbHash, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	h, ok := v.(*DbHash)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return h, nil
+}
+
+// unsafeGetOrCreateHash returns the hash stored at key, creating it if there is none. The caller must hold DbMu.
+func unsafeGetOrCreateHash(key string) (*DbHash, error) {
+	h, err := unsafeGetHash(key)
+	if err != nil || h != nil {
+		return h, err
+	}
+	h = NewDbHash()
+	unsafeSet(key, h)
+	return h, nil
+}
+
+// unsafeUpdateHashField sets field to value, retaining its expiry only if it already exists. The caller must hold DbMu.
+func unsafeUpdateHashField(h *DbHash, field, value string) {
+	if _, ok := h.Get(field); ok {
+		h.update(field, value)
+	} else {
+		h.Set(field, value)
+	}
+}
+
+// formatFloat formats f the way Redis replies with computed floats: in the shortest form that round-trips, without an exponent
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Hash operations
+
+// Hset sets the given alternating fields and values, only setting fields that do not exist if onlyIfNew is set.
+// It returns the number of fields that were added.
+func Hset(key string, pairs []string, onlyIfNew bool) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetOrCreateHash(key)
+	if err != nil {
+		return 0, err
+	}
+	var added int64
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if onlyIfNew {
+			if _, ok := h.Get(pairs[i]); ok {
+				continue
+			}
+		}
+		if h.Set(pairs[i], pairs[i+1]) {
+			added++
+		}
+	}
+	unsafeReindexKey(key)
+	return added, nil
+}
+
+// Hget returns ErrorNone if there is no such hash or field.
+func Hget(key, field string) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return "", err
+	}
+	if h == nil {
+		return "", ErrorNone
+	}
+	value, ok := h.Get(field)
+	if !ok {
+		return "", ErrorNone
+	}
+	return value, nil
+}
+
+func Hmget(key string, fields []string) (resp.RESP, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]resp.RESP, len(fields))
+	for i, field := range fields {
+		res[i] = resp.NullLit
+		if h == nil {
+			continue
+		}
+		if value, ok := h.Get(field); ok {
+			res[i] = &resp.RESPBulkString{Value: value}
+		}
+	}
+	return &resp.RESPArray{Value: res}, nil
+}
+
+func Hdel(key string, fields []string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil || h == nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, field := range fields {
+		if h.Delete(field) {
+			deleted++
+		}
+	}
+	if h.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	if deleted > 0 {
+		unsafeReindexKey(key)
+	}
+	return deleted, nil
+}
+
+// Hgetall returns the fields and values of the hash alternately
+func Hgetall(key string) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return []string{}, nil
+	}
+	return h.Entries(), nil
+}
+
+func Hincrby(key, field string, by int64) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetOrCreateHash(key)
+	if err != nil {
+		return 0, err
+	}
+	var i int64
+	if value, ok := h.Get(field); ok {
+		i, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, ErrorHashNotInteger
+		}
+	}
+	if (by > 0 && i > math.MaxInt64-by) || (by < 0 && i < math.MinInt64-by) {
+		return 0, ErrorOverflow
+	}
+	i += by
+	unsafeUpdateHashField(h, field, strconv.FormatInt(i, 10))
+	unsafeReindexKey(key)
+	return i, nil
+}
+
+// Hincrbyfloat returns the new value of the field as formatted for storage
+func Hincrbyfloat(key, field string, by float64) (string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetOrCreateHash(key)
+	if err != nil {
+		return "", err
+	}
+	var f float64
+	if value, ok := h.Get(field); ok {
+		f, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", ErrorHashNotFloat
+		}
+	}
+	f += by
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrorNaNOrInfinity
+	}
+	s := formatFloat(f)
+	unsafeUpdateHashField(h, field, s)
+	unsafeReindexKey(key)
+	return s, nil
+}
+
+func Hexists(key, field string) (bool, error) {
+	_, err := Hget(key, field)
+	if err == ErrorNone {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func Hlen(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil || h == nil {
+		return 0, err
+	}
+	return int64(h.Len()), nil
+}
+
+func Hstrlen(key, field string) (int64, error) {
+	value, err := Hget(key, field)
+	if err == ErrorNone {
+		return 0, nil
+	}
+	return int64(len(value)), err
+}
+
+// Hrandfield returns up to count distinct random fields with their values alternately, or exactly -count fields that may repeat if count is negative.
+// It returns ErrorNone if there is no such hash.
+func Hrandfield(key string, count int64) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, ErrorNone
+	}
+	entries := h.Entries()
+	n := int64(len(entries) / 2)
+	if count < 0 {
+		var res []string
+		for ; count < 0; count++ {
+			i := rand.Int63n(n)
+			res = append(res, entries[2*i], entries[2*i+1])
+		}
+		return res, nil
+	}
+	if count >= n {
+		return entries, nil
+	}
+	// partial Fisher-Yates shuffle of the pairs
+	for i := int64(0); i < count; i++ {
+		j := i + rand.Int63n(n-i)
+		entries[2*i], entries[2*j] = entries[2*j], entries[2*i]
+		entries[2*i+1], entries[2*j+1] = entries[2*j+1], entries[2*i+1]
+	}
+	return entries[:2*count], nil
+}
@@ -0,0 +1,269 @@
+package state
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// Results of setting or clearing the expiry of a hash field, as replied by HEXPIRE and HPERSIST
+const (
+	HashFieldNoSuchField int64 = -2
+	HashFieldNoExpiry    int64 = -1
+	HashFieldNotSet      int64 = 0
+	HashFieldSet         int64 = 1
+	HashFieldDeleted     int64 = 2
+)
+
+// unsafeExpireHashFields sets the expiry of each of fields to at, subject to cond, which is one of "", "NX", "XX", "GT" and "LT".
+// Fields are deleted outright if at is not in the future, unless this is a replica, which waits for the master to delete them instead.
+// The caller must hold DbMu.
+func unsafeExpireHashFields(key string, h *DbHash, fields []string, at time.Time, cond string) []int64 {
+	now := time.Now()
+	res := make([]int64, len(fields))
+	for i, field := range fields {
+		current, ok := h.Expiry(field)
+		if !ok {
+			res[i] = HashFieldNoSuchField
+			continue
+		}
+		if !expiryCondMet(current, at, cond) {
+			res[i] = HashFieldNotSet
+			continue
+		}
+		if !IsReplica() && !at.After(now) {
+			h.Delete(field)
+			res[i] = HashFieldDeleted
+			continue
+		}
+		h.SetExpiry(field, at)
+		res[i] = HashFieldSet
+	}
+	if h.Len() == 0 {
+		unsafeDelete(key, now)
+	}
+	if slices.Contains(res, HashFieldDeleted) {
+		unsafeReindexKey(key)
+	}
+	return res
+}
+
+// Hexpire sets the expiry of each of fields to at, subject to cond, which is one of "", "NX", "XX", "GT" and "LT".
+// It returns, for each field, one of HashFieldNoSuchField, HashFieldNotSet, HashFieldSet and HashFieldDeleted.
+func Hexpire(key string, fields []string, at time.Time, cond string) ([]int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		res := make([]int64, len(fields))
+		for i := range res {
+			res[i] = HashFieldNoSuchField
+		}
+		return res, nil
+	}
+	return unsafeExpireHashFields(key, h, fields, at, cond), nil
+}
+
+// HexpireTimes returns, for each of fields, its expiry as a unix time in milliseconds, or one of HashFieldNoSuchField and HashFieldNoExpiry.
+func HexpireTimes(key string, fields []string) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, len(fields))
+	for i, field := range fields {
+		res[i] = HashFieldNoSuchField
+		if h == nil {
+			continue
+		}
+		at, ok := h.Expiry(field)
+		if !ok {
+			continue
+		}
+		if at.IsZero() {
+			res[i] = HashFieldNoExpiry
+		} else {
+			res[i] = at.UnixMilli()
+		}
+	}
+	return res, nil
+}
+
+// unsafePersistHashFields clears the expiry of each of fields. The caller must hold DbMu.
+func unsafePersistHashFields(h *DbHash, fields []string) []int64 {
+	res := make([]int64, len(fields))
+	for i, field := range fields {
+		res[i] = HashFieldNoSuchField
+		if h == nil {
+			continue
+		}
+		if _, ok := h.Get(field); !ok {
+			continue
+		}
+		if h.Persist(field) {
+			res[i] = HashFieldSet
+		} else {
+			res[i] = HashFieldNoExpiry
+		}
+	}
+	return res
+}
+
+// Hpersist clears the expiry of each of fields, returning for each field one of HashFieldNoSuchField, HashFieldNoExpiry and HashFieldSet.
+func Hpersist(key string, fields []string) ([]int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	return unsafePersistHashFields(h, fields), nil
+}
+
+// Hgetex returns the values of fields, and then sets their expiry to at if expire is set, or clears it if persist is set.
+// It returns the results of setting or clearing the expiry as with Hexpire and Hpersist, or nil if neither was requested.
+func Hgetex(key string, fields []string, at time.Time, expire, persist bool) (resp.RESP, []int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]resp.RESP, len(fields))
+	for i, field := range fields {
+		values[i] = resp.NullLit
+		if h == nil {
+			continue
+		}
+		if value, ok := h.Get(field); ok {
+			values[i] = &resp.RESPBulkString{Value: value}
+		}
+	}
+	var res []int64
+	if h != nil && expire {
+		res = unsafeExpireHashFields(key, h, fields, at, "")
+	}
+	if persist {
+		res = unsafePersistHashFields(h, fields)
+	}
+	return &resp.RESPArray{Value: values}, res, nil
+}
+
+// Hsetex sets the given alternating fields and values, subject to cond, which is one of "", "FNX" (none of the fields exist) and "FXX" (all of the fields exist).
+// The fields are set to expire at at, or retain their expiry if keepTTL is set, or otherwise have their expiry cleared if at is zero.
+// It returns false if cond was not met, and whether the fields were deleted outright since at is not in the future.
+func Hsetex(key string, pairs []string, cond string, at time.Time, keepTTL bool) (bool, bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil {
+		return false, false, err
+	}
+	if cond != "" {
+		for i := 0; i+1 < len(pairs); i += 2 {
+			exists := false
+			if h != nil {
+				_, exists = h.Get(pairs[i])
+			}
+			if exists != (cond == "FXX") {
+				return false, false, nil
+			}
+		}
+	}
+	if h == nil {
+		h = NewDbHash()
+		unsafeSet(key, h)
+	}
+	fields := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if keepTTL {
+			unsafeUpdateHashField(h, pairs[i], pairs[i+1])
+		} else {
+			h.Set(pairs[i], pairs[i+1])
+		}
+		fields = append(fields, pairs[i])
+	}
+	unsafeReindexKey(key)
+	if at.IsZero() {
+		return true, false, nil
+	}
+	res := unsafeExpireHashFields(key, h, fields, at, "")
+	return true, len(res) > 0 && res[0] == HashFieldDeleted, nil
+}
+
+// TryEvictExpiredHashFields reclaims the expired fields of the hash at key, propagating their deletion to replicas as HDEL.
+// It is a no-op on replicas, which wait for the master to propagate the deletion instead.
+func TryEvictExpiredHashFields(key string) {
+	if IsReplica() {
+		return
+	}
+	ExecuteAndReplicateCommand(func() ([]resp.RESP, error) {
+		LockDbMu()
+		defer UnlockDbMu()
+		v, ok := state.Db.Get(key)
+		if !ok {
+			return nil, nil
+		}
+		h, ok := v.(*DbHash)
+		if !ok || h.expires == nil {
+			return nil, nil
+		}
+		fields := h.evictExpired(time.Now())
+		if len(fields) == 0 {
+			return nil, nil
+		}
+		if h.Len() == 0 {
+			unsafeDelete(key, time.Now())
+		}
+		unsafeReindexKey(key)
+		return []resp.RESP{resp.EncodeStringSlice(append([]string{"HDEL", key}, fields...))}, nil
+	})
+}
+
+// SyncTryEvictExpiredHashFieldsSweep is a helper function for daemons to reclaim expired fields from all hashes. It is expected to run for a long time.
+// The keyspace is walked with a cursor in batches, as with SyncTryEvictExpiredKeysSweep.
+func SyncTryEvictExpiredHashFieldsSweep() {
+	if IsReplica() {
+		log.Println("SyncTryEvictExpiredHashFieldsSweep: not a master, skipping")
+		return
+	}
+	log.Println("SyncTryEvictExpiredHashFieldsSweep: started")
+	RLockDbMu()
+	size := state.Db.Len()
+	RUnlockDbMu()
+	if size < evictionSweepMapSizeThreshold {
+		return
+	}
+	var cursor uint64
+	for {
+		now := time.Now()
+		var keys []string
+		RLockDbMu()
+		for i := 0; i < evictionSweepCountPerAcquisition; {
+			cursor = state.Db.Scan(cursor, func(k string, v DbValue) {
+				if h, ok := v.(*DbHash); ok && h.mayHaveExpiredFieldsAt(now) {
+					keys = append(keys, k)
+				}
+				i++
+			})
+			if cursor == 0 {
+				break
+			}
+		}
+		RUnlockDbMu()
+		for _, k := range keys {
+			TryEvictExpiredHashFields(k)
+		}
+		if cursor == 0 {
+			return
+		}
+		time.Sleep(evictionSweepSleepPerAcquisition)
+	}
+}
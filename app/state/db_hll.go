@@ -0,0 +1,491 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HyperLogLogs are stored as string values in the same format as Redis, so that they can be exchanged with Redis.
+// The format is a 16 byte header, consisting of the magic "HYLL", the encoding, 3 unused bytes and the cached cardinality as a
+// little endian integer (whose most significant bit is set if the cache is invalid), followed by the registers in either encoding.
+//
+// The dense encoding packs each 6 bit register starting from the least significant bit of a byte.
+// The sparse encoding is a sequence of run-length opcodes:
+//   - ZERO 00xxxxxx: a run of xxxxxx+1 zero registers
+//   - XZERO 01xxxxxx yyyyyyyy: a run of xxxxxxyyyyyyyy+1 zero registers
+//   - VAL 1vvvvvxx: a run of xx+1 registers of value vvvvv+1
+const (
+	hllP         = 14
+	hllQ         = 64 - hllP
+	hllRegisters = 1 << hllP
+	hllBits      = 6
+	hllHeaderLen = 16
+	hllDenseLen  = hllHeaderLen + (hllRegisters*hllBits+7)/8
+	// hllSparseMaxBytes is the size beyond which a sparse HyperLogLog is converted to the dense encoding
+	hllSparseMaxBytes = 3000
+	hllSparseValMax   = 32
+	hllSparseValLen   = 4
+	hllSparseZeroLen  = 64
+	hllSparseXZeroLen = 16384
+	hllAlphaInf       = 0.721347520444481703680
+	hllSeed           = 0xadc83b19
+)
+
+const (
+	hllEncodingDense  byte = 0
+	hllEncodingSparse byte = 1
+)
+
+var (
+	ErrorNotHLL       = errors.New("WRONGTYPE Key is not a valid HyperLogLog string value.")
+	ErrorHLLCorrupted = errors.New("INVALIDOBJ Corrupted HLL object detected")
+)
+
+// murmurHash64A is the MurmurHash2 variant used by Redis to hash HyperLogLog elements
+func murmurHash64A(key string, seed uint64) uint64 {
+	const (
+		m = 0xc6a4a7935bd1e995
+		r = 47
+	)
+	h := seed ^ (uint64(len(key)) * m)
+	n := len(key) / 8 * 8
+	for i := 0; i < n; i += 8 {
+		k := binary.LittleEndian.Uint64([]byte(key[i : i+8]))
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+	tail := key[n:]
+	if len(tail) > 0 {
+		for i := len(tail) - 1; i >= 0; i-- {
+			h ^= uint64(tail[i]) << (8 * i)
+		}
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// hllPatLen returns the register of elem, and the length of the run of zero bits that follows in its hash, plus one
+func hllPatLen(elem string) (int, byte) {
+	hash := murmurHash64A(elem, hllSeed)
+	index := int(hash & (hllRegisters - 1))
+	// the sentinel bit ensures the count is at most hllQ+1
+	hash = hash>>hllP | 1<<hllQ
+	count := byte(1)
+	for bit := uint64(1); hash&bit == 0; bit <<= 1 {
+		count++
+	}
+	return index, count
+}
+
+// hll is a decoded HyperLogLog
+type hll struct {
+	registers [hllRegisters]byte
+	sparse    bool
+	// card is the cached cardinality, which is valid only if cardValid is set
+	card      uint64
+	cardValid bool
+}
+
+func newHll() *hll {
+	return &hll{sparse: true, cardValid: true}
+}
+
+// decodeHll decodes the HyperLogLog s, returning ErrorNotHLL if it is not one, and ErrorHLLCorrupted if its registers are malformed
+func decodeHll(s string) (*hll, error) {
+	if len(s) < hllHeaderLen || s[:4] != "HYLL" {
+		return nil, ErrorNotHLL
+	}
+	h := &hll{}
+	switch s[4] {
+	case hllEncodingDense:
+		if len(s) != hllDenseLen {
+			return nil, ErrorNotHLL
+		}
+		for i := range h.registers {
+			h.registers[i] = denseRegister(s[hllHeaderLen:], i)
+		}
+	case hllEncodingSparse:
+		h.sparse = true
+		i := 0
+		for p := hllHeaderLen; p < len(s); p++ {
+			op := s[p]
+			var run int
+			var value byte
+			switch {
+			case op&0xc0 == 0:
+				run = int(op&0x3f) + 1
+			case op&0xc0 == 0x40:
+				if p+1 >= len(s) {
+					return nil, ErrorHLLCorrupted
+				}
+				run = (int(op&0x3f)<<8 | int(s[p+1])) + 1
+				p++
+			default:
+				run = int(op&0x3) + 1
+				value = (op>>2)&0x1f + 1
+			}
+			if i+run > hllRegisters {
+				return nil, ErrorHLLCorrupted
+			}
+			for ; run > 0; run-- {
+				h.registers[i] = value
+				i++
+			}
+		}
+		if i != hllRegisters {
+			return nil, ErrorHLLCorrupted
+		}
+	default:
+		return nil, ErrorNotHLL
+	}
+	card := binary.LittleEndian.Uint64([]byte(s[8:16]))
+	h.cardValid = card>>63 == 0
+	h.card = card &^ (1 << 63)
+	return h, nil
+}
+
+func denseRegister(r string, i int) byte {
+	b := i * hllBits / 8
+	fb := uint(i * hllBits % 8)
+	v := uint(r[b]) >> fb
+	if b+1 < len(r) {
+		v |= uint(r[b+1]) << (8 - fb)
+	}
+	return byte(v & (1<<hllBits - 1))
+}
+
+// encode encodes h, in the sparse encoding if h is sparse and fits, and the dense encoding otherwise
+func (h *hll) encode() string {
+	var b []byte
+	if h.sparse {
+		b = h.encodeSparse()
+		h.sparse = b != nil
+	}
+	if b == nil {
+		b = h.encodeDense()
+	}
+	card := h.card
+	if !h.cardValid {
+		card |= 1 << 63
+	}
+	binary.LittleEndian.PutUint64(b[8:16], card)
+	return string(b)
+}
+
+func hllHeader(encoding byte, size int) []byte {
+	b := make([]byte, hllHeaderLen, size)
+	copy(b, "HYLL")
+	b[4] = encoding
+	return b
+}
+
+func (h *hll) encodeDense() []byte {
+	b := hllHeader(hllEncodingDense, hllDenseLen)
+	b = b[:hllDenseLen]
+	r := b[hllHeaderLen:]
+	for i, v := range h.registers {
+		p := i * hllBits / 8
+		fb := uint(i * hllBits % 8)
+		r[p] |= v << fb
+		if p+1 < len(r) {
+			r[p+1] |= byte(uint(v) >> (8 - fb))
+		}
+	}
+	return b
+}
+
+// encodeSparse returns nil if a register is too large for the sparse encoding or the encoding would be too large
+func (h *hll) encodeSparse() []byte {
+	b := hllHeader(hllEncodingSparse, hllHeaderLen+64)
+	for i := 0; i < hllRegisters; {
+		value := h.registers[i]
+		run := 1
+		for i+run < hllRegisters && h.registers[i+run] == value {
+			run++
+		}
+		i += run
+		switch {
+		case value > hllSparseValMax:
+			return nil
+		case value > 0:
+			for ; run > 0; run -= hllSparseValLen {
+				n := min(run, hllSparseValLen)
+				b = append(b, 0x80|(value-1)<<2|byte(n-1))
+			}
+		case run > hllSparseZeroLen:
+			b = append(b, 0x40|byte((run-1)>>8), byte(run-1))
+		default:
+			b = append(b, byte(run-1))
+		}
+		if len(b) > hllSparseMaxBytes {
+			return nil
+		}
+	}
+	return b
+}
+
+// add adds elem, returning whether any register changed
+func (h *hll) add(elem string) bool {
+	index, count := hllPatLen(elem)
+	if h.registers[index] >= count {
+		return false
+	}
+	h.registers[index] = count
+	h.cardValid = false
+	return true
+}
+
+// merge sets each register to the maximum of its value and its value in other
+func (h *hll) merge(other *hll) {
+	for i, v := range other.registers {
+		h.registers[i] = max(h.registers[i], v)
+	}
+	h.cardValid = false
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y := 1.0
+	z := x
+	for {
+		x *= x
+		zPrime := z
+		z += x * y
+		y += y
+		if zPrime == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y := 1.0
+	z := 1 - x
+	for {
+		x = math.Sqrt(x)
+		zPrime := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if zPrime == z {
+			return z / 3
+		}
+	}
+}
+
+// count estimates the cardinality with the estimator of Otmar Ertl, as Redis does
+func (h *hll) count() uint64 {
+	var histogram [64]int
+	for _, v := range h.registers {
+		histogram[v]++
+	}
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histogram[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	return uint64(math.Round(hllAlphaInf * m * m / z))
+}
+
+// unsafeGetHll returns the HyperLogLog stored at key, or nil if there is none, along with its expiry. The caller must hold DbMu.
+func unsafeGetHll(key string) (*hll, time.Time, error) {
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return nil, time.Time{}, ErrorNotHLL
+	}
+	if w == nil {
+		return nil, time.Time{}, nil
+	}
+	h, err := decodeHll(w.string)
+	return h, w.expiresAt, err
+}
+
+// HyperLogLog operations
+
+// Pfadd adds elems to the HyperLogLog at key, returning whether it was created or any of its registers changed
+func Pfadd(key string, elems []string) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, expiresAt, err := unsafeGetHll(key)
+	if err != nil {
+		return false, err
+	}
+	changed := h == nil
+	if h == nil {
+		h = newHll()
+	}
+	for _, elem := range elems {
+		if h.add(elem) {
+			changed = true
+		}
+	}
+	if changed {
+		UnsafeSet(key, h.encode(), expiresAt)
+	}
+	return changed, nil
+}
+
+// Pfcount estimates the cardinality of the union of the HyperLogLogs at keys.
+// For a single key, the cardinality is cached in the HyperLogLog if cache is set, and Pfcount returns whether the cache was updated.
+func Pfcount(keys []string, cache bool) (int64, bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	if len(keys) == 1 {
+		h, expiresAt, err := unsafeGetHll(keys[0])
+		if err != nil || h == nil {
+			return 0, false, err
+		}
+		if h.cardValid {
+			return int64(h.card), false, nil
+		}
+		card := h.count()
+		if !cache {
+			return int64(card), false, nil
+		}
+		h.card, h.cardValid = card, true
+		UnsafeSet(keys[0], h.encode(), expiresAt)
+		return int64(card), true, nil
+	}
+	union := newHll()
+	for _, key := range keys {
+		h, _, err := unsafeGetHll(key)
+		if err != nil {
+			return 0, false, err
+		}
+		if h != nil {
+			union.merge(h)
+		}
+	}
+	return int64(union.count()), false, nil
+}
+
+// Pfmerge merges the HyperLogLogs at keys into the one at dst, creating it if needed.
+// The result is sparse only if every HyperLogLog merged is sparse and the result fits.
+func Pfmerge(dst string, keys []string) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	res, expiresAt, err := unsafeGetHll(dst)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		res = newHll()
+	}
+	for _, key := range keys {
+		h, _, err := unsafeGetHll(key)
+		if err != nil {
+			return err
+		}
+		if h != nil {
+			res.merge(h)
+			res.sparse = res.sparse && h.sparse
+		}
+	}
+	UnsafeSet(dst, res.encode(), expiresAt)
+	return nil
+}
+
+// PfdebugGetreg returns the registers of the HyperLogLog at key
+func PfdebugGetreg(key string) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, _, err := unsafeGetHll(key)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, ErrorNone
+	}
+	res := make([]int64, hllRegisters)
+	for i, v := range h.registers {
+		res[i] = int64(v)
+	}
+	return res, nil
+}
+
+// PfdebugEncoding returns "sparse" or "dense"
+func PfdebugEncoding(key string) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, _, err := unsafeGetHll(key)
+	if err != nil {
+		return "", err
+	}
+	if h == nil {
+		return "", ErrorNone
+	}
+	if h.sparse {
+		return "sparse", nil
+	}
+	return "dense", nil
+}
+
+// PfdebugDecode describes the opcodes of the sparse HyperLogLog at key, e.g. "Z:3 v:2,1 XZ:16380"
+func PfdebugDecode(key string) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return "", ErrorNotHLL
+	}
+	if w == nil {
+		return "", ErrorNone
+	}
+	h, err := decodeHll(w.string)
+	if err != nil {
+		return "", err
+	}
+	if !h.sparse {
+		return "", errors.New("ERR HLL encoding is not sparse")
+	}
+	s := w.string
+	var ops []string
+	for p := hllHeaderLen; p < len(s); p++ {
+		op := s[p]
+		switch {
+		case op&0xc0 == 0:
+			ops = append(ops, "Z:"+strconv.Itoa(int(op&0x3f)+1))
+		case op&0xc0 == 0x40:
+			ops = append(ops, "XZ:"+strconv.Itoa((int(op&0x3f)<<8|int(s[p+1]))+1))
+			p++
+		default:
+			ops = append(ops, "v:"+strconv.Itoa(int((op>>2)&0x1f)+1)+","+strconv.Itoa(int(op&0x3)+1))
+		}
+	}
+	return strings.Join(ops, " "), nil
+}
+
+// PfdebugTodense converts the HyperLogLog at key to the dense encoding, returning whether it was sparse
+func PfdebugTodense(key string) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	h, expiresAt, err := unsafeGetHll(key)
+	if err != nil {
+		return false, err
+	}
+	if h == nil {
+		return false, ErrorNone
+	}
+	if !h.sparse {
+		return false, nil
+	}
+	h.sparse = false
+	UnsafeSet(key, h.encode(), expiresAt)
+	return true, nil
+}
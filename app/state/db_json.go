@@ -0,0 +1,831 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// DbJSON stores a parsed JSON document, so that parts of it can be read and updated in place.
+// JSON values are represented as nil (null), bool, int64 or float64 (numbers, depending on whether they were written as integers),
+// string, *jsonArray and *jsonObject, which keeps its keys in insertion order.
+type DbJSON struct {
+	dbExpiry
+	root any
+}
+
+var _ DbValue = (*DbJSON)(nil)
+
+func (v *DbJSON) Type() string {
+	return "ReJSON-RL"
+}
+
+type jsonArray struct {
+	elems []any
+}
+
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: map[string]any{}}
+}
+
+// set sets the value of key, appending it to the keys if it is new
+func (o *jsonObject) set(key string, value any) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *jsonObject) remove(key string) {
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// jsonCopy returns a deep copy of v, so that the same parsed value can be stored in multiple places
+func jsonCopy(v any) any {
+	switch v := v.(type) {
+	case *jsonArray:
+		res := &jsonArray{elems: make([]any, len(v.elems))}
+		for i, e := range v.elems {
+			res.elems[i] = jsonCopy(e)
+		}
+		return res
+	case *jsonObject:
+		res := &jsonObject{keys: append([]string(nil), v.keys...), values: make(map[string]any, len(v.values))}
+		for k, e := range v.values {
+			res.values[k] = jsonCopy(e)
+		}
+		return res
+	}
+	return v
+}
+
+// jsonTypeName returns the type of v as reported by JSON.TYPE
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *jsonArray:
+		return "array"
+	}
+	return "object"
+}
+
+// parsing
+
+type jsonParser struct {
+	s   string
+	pos int
+}
+
+// parseJSON parses a single JSON value, with errors reported in the style of RedisJSON
+func parseJSON(s string) (any, error) {
+	p := &jsonParser{s: s}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("trailing characters")
+	}
+	return v, nil
+}
+
+func (p *jsonParser) errorf(format string, a ...any) error {
+	line, col := 1, 0
+	for _, c := range p.s[:min(p.pos+1, len(p.s))] {
+		if c == '\n' {
+			line, col = line+1, 0
+		} else {
+			col++
+		}
+	}
+	return fmt.Errorf("ERR "+format+" at line %d column %d", append(a, line, col)...)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *jsonParser) value() (any, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("EOF while parsing a value")
+	}
+	switch c := p.s[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		return p.string()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.number()
+	}
+	switch rest := p.s[p.pos:]; {
+	case strings.HasPrefix(rest, "null"):
+		p.pos += 4
+		return nil, nil
+	case strings.HasPrefix(rest, "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(rest, "false"):
+		p.pos += 5
+		return false, nil
+	}
+	return nil, p.errorf("expected value")
+}
+
+func (p *jsonParser) object() (any, error) {
+	o := newJSONObject()
+	p.pos++
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '}' {
+		p.pos++
+		return o, nil
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("EOF while parsing an object")
+		}
+		if p.s[p.pos] != '"' {
+			return nil, p.errorf("key must be a string")
+		}
+		key, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ':' {
+			return nil, p.errorf("expected `:`")
+		}
+		p.pos++
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		o.set(key, v)
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("EOF while parsing an object")
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return o, nil
+		default:
+			return nil, p.errorf("expected `,` or `}`")
+		}
+	}
+}
+
+func (p *jsonParser) array() (any, error) {
+	a := &jsonArray{elems: []any{}}
+	p.pos++
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == ']' {
+		p.pos++
+		return a, nil
+	}
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		a.elems = append(a.elems, v)
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return nil, p.errorf("EOF while parsing a list")
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return a, nil
+		default:
+			return nil, p.errorf("expected `,` or `]`")
+		}
+	}
+}
+
+func (p *jsonParser) hex4() (rune, bool) {
+	if p.pos+4 > len(p.s) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(p.s[p.pos:p.pos+4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	p.pos += 4
+	return rune(n), true
+}
+
+func (p *jsonParser) string() (string, error) {
+	var b strings.Builder
+	p.pos++
+	for {
+		if p.pos >= len(p.s) {
+			return "", p.errorf("EOF while parsing a string")
+		}
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character (\\u0000-\\u001F) found while parsing a string")
+		case c != '\\':
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+		p.pos++
+		if p.pos >= len(p.s) {
+			return "", p.errorf("EOF while parsing a string")
+		}
+		esc := p.s[p.pos]
+		p.pos++
+		switch esc {
+		case '"', '\\', '/':
+			b.WriteByte(esc)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			r, ok := p.hex4()
+			if !ok {
+				return "", p.errorf("invalid escape")
+			}
+			if utf16.IsSurrogate(r) {
+				if !strings.HasPrefix(p.s[p.pos:], "\\u") {
+					return "", p.errorf("lone leading surrogate in hex escape")
+				}
+				p.pos += 2
+				r2, ok := p.hex4()
+				if r = utf16.DecodeRune(r, r2); !ok || r == utf8.RuneError {
+					return "", p.errorf("invalid unicode code point")
+				}
+			}
+			b.WriteRune(r)
+		default:
+			return "", p.errorf("invalid escape")
+		}
+	}
+}
+
+func (p *jsonParser) number() (any, error) {
+	start := p.pos
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+	if p.s[p.pos] == '-' {
+		p.pos++
+	}
+	intStart := p.pos
+	if digits() == 0 {
+		return nil, p.errorf("invalid number")
+	}
+	if p.s[intStart] == '0' && p.pos-intStart > 1 {
+		p.pos = intStart + 1
+		return nil, p.errorf("invalid number")
+	}
+	isFloat := false
+	if p.pos < len(p.s) && p.s[p.pos] == '.' {
+		isFloat = true
+		p.pos++
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	if p.pos < len(p.s) && (p.s[p.pos] == 'e' || p.s[p.pos] == 'E') {
+		isFloat = true
+		p.pos++
+		if p.pos < len(p.s) && (p.s[p.pos] == '+' || p.s[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	text := p.s[start:p.pos]
+	if !isFloat {
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i, nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, p.errorf("number out of range")
+	}
+	return f, nil
+}
+
+// serialization
+
+// JSONFormat is the formatting of serialized JSON, as with the INDENT, NEWLINE and SPACE options of JSON.GET.
+// The zero value serializes JSON compactly.
+type JSONFormat struct {
+	Indent, Newline, Space string
+}
+
+func (f JSONFormat) serialize(v any) string {
+	var b strings.Builder
+	f.write(&b, v, 0)
+	return b.String()
+}
+
+func (f JSONFormat) write(b *strings.Builder, v any, level int) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		b.WriteString(formatJSONFloat(v))
+	case string:
+		writeJSONString(b, v)
+	case *jsonArray:
+		if len(v.elems) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteByte('[')
+		for i, e := range v.elems {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			f.newline(b, level+1)
+			f.write(b, e, level+1)
+		}
+		f.newline(b, level)
+		b.WriteByte(']')
+	case *jsonObject:
+		if len(v.keys) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteByte('{')
+		for i, k := range v.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			f.newline(b, level+1)
+			writeJSONString(b, k)
+			b.WriteByte(':')
+			b.WriteString(f.Space)
+			f.write(b, v.values[k], level+1)
+		}
+		f.newline(b, level)
+		b.WriteByte('}')
+	}
+}
+
+func (f JSONFormat) newline(b *strings.Builder, level int) {
+	b.WriteString(f.Newline)
+	for range level {
+		b.WriteString(f.Indent)
+	}
+}
+
+func writeJSONString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// formatJSONFloat formats f as RedisJSON does, in the shortest form that parses back to f,
+// in decimal notation with at least one decimal unless the decimal point is more than 16 digits away from the first digit
+func formatJSONFloat(f float64) string {
+	sign := ""
+	if math.Signbit(f) {
+		sign, f = "-", -f
+	}
+	// split the shortest scientific form d.ddde±xx into its digits and exponent
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	// point is the position of the decimal point relative to the first digit
+	point := e + 1
+	switch {
+	case point >= len(digits) && point <= 16:
+		return sign + digits + strings.Repeat("0", point-len(digits)) + ".0"
+	case point > 0 && point <= 16:
+		return sign + digits[:point] + "." + digits[point:]
+	case point > -5 && point <= 0:
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	case len(digits) == 1:
+		return sign + digits + "e" + strconv.Itoa(point-1)
+	}
+	return sign + digits[:1] + "." + digits[1:] + "e" + strconv.Itoa(point-1)
+}
+
+var (
+	ErrorJSONNewAtRoot = errors.New("ERR new objects must be created at the root")
+	ErrorJSONNoKey     = errors.New("ERR could not perform this operation on a key that doesn't exist")
+	ErrorJSONNotNumber = errors.New("ERR result is not a valid JSON number")
+	ErrorJSONBadPath   = errors.New("ERR invalid JSONPath")
+)
+
+func errorJSONPathMissing(path string) error {
+	return fmt.Errorf("ERR Path '%s' does not exist", path)
+}
+
+func errorJSONWrongType(expected string, v any) error {
+	return fmt.Errorf("WRONGTYPE wrong type of path value - expected %s but found %s", expected, jsonTypeName(v))
+}
+
+// IsLegacyJSONPath reports whether path is a legacy path, which selects a single value rather than every match
+func IsLegacyJSONPath(path string) bool {
+	return !strings.HasPrefix(path, "$")
+}
+
+// unsafeGetJSON returns the JSON document stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetJSON(key string) (*DbJSON, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	doc, ok := v.(*DbJSON)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return doc, nil
+}
+
+// evalJSONPath compiles path and evaluates it within doc.
+// Legacy paths must match, and only their first match is returned.
+func evalJSONPath(doc *DbJSON, path string) ([]jsonLoc, error) {
+	p, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	locs := p.eval(doc.root)
+	if p.legacy {
+		if len(locs) == 0 {
+			return nil, errorJSONPathMissing(path)
+		}
+		return locs[:1], nil
+	}
+	return locs, nil
+}
+
+// JSON operations
+
+// JsonSet sets the values at path to the JSON value, as with JSON.SET, returning whether the document was updated.
+// A value that is missing is added only if path names a child of existing objects. NX only adds values, while XX only replaces them.
+func JsonSet(key, path, value string, nx, xx bool) (bool, error) {
+	v, err := parseJSON(value)
+	if err != nil {
+		return false, err
+	}
+	p, err := compileJSONPath(path)
+	if err != nil {
+		return false, err
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil {
+		return false, err
+	}
+	if doc == nil {
+		if !p.isRoot() {
+			return false, ErrorJSONNewAtRoot
+		}
+		if xx {
+			return false, nil
+		}
+		unsafeSet(key, &DbJSON{root: v})
+		return true, nil
+	}
+	if locs := p.eval(doc.root); len(locs) > 0 {
+		if nx {
+			return false, nil
+		}
+		for _, l := range locs {
+			l.set(doc, jsonCopy(v))
+		}
+		return true, nil
+	}
+	if xx || !p.isStaticChild() {
+		return false, nil
+	}
+	name := p.segments[len(p.segments)-1].names[0]
+	updated := false
+	for _, l := range p.evalParents(doc.root) {
+		if o, ok := l.value.(*jsonObject); ok {
+			o.set(name, jsonCopy(v))
+			updated = true
+		}
+	}
+	return updated, nil
+}
+
+// JsonGet serializes the values at paths with format, as with JSON.GET, returning nil if key does not exist.
+// The values at a single path are serialized as an array, or a single value for a legacy path. The values at multiple paths are
+// serialized as an object keyed by path, of single values only if all paths are legacy paths.
+func JsonGet(key string, paths []string, format JSONFormat) (*string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	legacy := !slices.ContainsFunc(paths, func(path string) bool { return !IsLegacyJSONPath(path) })
+	values := make([]any, len(paths))
+	for i, path := range paths {
+		locs, err := evalJSONPath(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if legacy {
+			values[i] = locs[0].value
+		} else {
+			values[i] = jsonLocValues(locs)
+		}
+	}
+	if len(paths) == 1 {
+		s := format.serialize(values[0])
+		return &s, nil
+	}
+	res := newJSONObject()
+	for i, path := range paths {
+		res.set(path, values[i])
+	}
+	s := format.serialize(res)
+	return &s, nil
+}
+
+func jsonLocValues(locs []jsonLoc) *jsonArray {
+	res := &jsonArray{elems: make([]any, len(locs))}
+	for i, l := range locs {
+		res.elems[i] = l.value
+	}
+	return res
+}
+
+// JsonDel removes the values at path, as with JSON.DEL, deleting key if path is the root. It returns the number of values removed.
+func JsonDel(key, path string) (int64, error) {
+	p, err := compileJSONPath(path)
+	if err != nil {
+		return 0, err
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil || doc == nil {
+		return 0, err
+	}
+	if p.isRoot() {
+		unsafeDelete(key, time.Now())
+		return 1, nil
+	}
+	return int64(removeJSONLocs(p.eval(doc.root))), nil
+}
+
+// JsonType returns the types of the values at path, as with JSON.TYPE, or nil if key does not exist
+func JsonType(key, path string) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	p, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for _, l := range p.eval(doc.root) {
+		res = append(res, jsonTypeName(l.value))
+	}
+	return res, nil
+}
+
+// JsonArrappend appends the JSON values to the arrays at path, as with JSON.ARRAPPEND, returning their new lengths, or nil for values that are not arrays
+func JsonArrappend(key, path string, values []string) ([]*int64, error) {
+	elems := make([]any, len(values))
+	for i, value := range values {
+		v, err := parseJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = v
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, ErrorJSONNoKey
+	}
+	locs, err := evalJSONPath(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*int64, len(locs))
+	for i, l := range locs {
+		a, ok := l.value.(*jsonArray)
+		if !ok {
+			if IsLegacyJSONPath(path) {
+				return nil, errorJSONWrongType("array", l.value)
+			}
+			continue
+		}
+		for _, e := range elems {
+			a.elems = append(a.elems, jsonCopy(e))
+		}
+		n := int64(len(a.elems))
+		res[i] = &n
+	}
+	return res, nil
+}
+
+// JsonNumincrby increments the numbers at path by the JSON number incr, as with JSON.NUMINCRBY, returning their new values serialized as an array
+// with null for values that are not numbers, or as a single value for a legacy path. It also returns a JSON.SET of each new value
+// at its normalized path, which is propagated rather than the increment, so that replicas cannot diverge due to differences in float rounding.
+func JsonNumincrby(key, path, incr string) (string, []resp.RESP, error) {
+	by, err := parseJSON(incr)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := jsonNumber(by); !ok {
+		return "", nil, errorJSONWrongType("a number", by)
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil {
+		return "", nil, err
+	}
+	if doc == nil {
+		return "", nil, ErrorJSONNoKey
+	}
+	locs, err := evalJSONPath(doc, path)
+	if err != nil {
+		return "", nil, err
+	}
+	// compute every result before updating any, so that an error leaves the document untouched
+	res := &jsonArray{elems: make([]any, len(locs))}
+	for i, l := range locs {
+		x, ok := jsonNumber(l.value)
+		if !ok {
+			if IsLegacyJSONPath(path) {
+				return "", nil, errorJSONWrongType("a number", l.value)
+			}
+			continue
+		}
+		a, aInt := l.value.(int64)
+		b, bInt := by.(int64)
+		if sum := a + b; aInt && bInt && (sum > a) == (b > 0) {
+			res.elems[i] = sum
+		} else {
+			y, _ := jsonNumber(by)
+			if math.IsInf(x+y, 0) || math.IsNaN(x+y) {
+				return "", nil, ErrorJSONNotNumber
+			}
+			res.elems[i] = x + y
+		}
+	}
+	var cmds []resp.RESP
+	for i, l := range locs {
+		if res.elems[i] != nil {
+			l.set(doc, res.elems[i])
+			cmds = append(cmds, resp.EncodeStringSlice([]string{"JSON.SET", key, l.normalizedPath(), JSONFormat{}.serialize(res.elems[i])}))
+		}
+	}
+	if IsLegacyJSONPath(path) {
+		return JSONFormat{}.serialize(res.elems[0]), cmds, nil
+	}
+	return JSONFormat{}.serialize(res), cmds, nil
+}
+
+// JsonObjkeys returns the keys of the objects at path, as with JSON.OBJKEYS, with nil for values that are not objects.
+// It returns nil if key does not exist.
+func JsonObjkeys(key, path string) ([][]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	doc, err := unsafeGetJSON(key)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	locs, err := evalJSONPath(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	res := make([][]string, len(locs))
+	for i, l := range locs {
+		o, ok := l.value.(*jsonObject)
+		if !ok {
+			if IsLegacyJSONPath(path) {
+				return nil, errorJSONWrongType("object", l.value)
+			}
+			continue
+		}
+		res[i] = append([]string{}, o.keys...)
+	}
+	return res, nil
+}
+
+// JsonMget serializes the values at path within the documents at keys, as with JSON.MGET, with nil for keys that are missing or not documents
+// and, for a legacy path, documents without a match
+func JsonMget(keys []string, path string) ([]*string, error) {
+	p, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	RLockDbMu()
+	defer RUnlockDbMu()
+	res := make([]*string, len(keys))
+	for i, key := range keys {
+		doc, err := unsafeGetJSON(key)
+		if err != nil || doc == nil {
+			continue
+		}
+		locs := p.eval(doc.root)
+		var s string
+		switch {
+		case !p.legacy:
+			s = JSONFormat{}.serialize(jsonLocValues(locs))
+		case len(locs) > 0:
+			s = JSONFormat{}.serialize(locs[0].value)
+		default:
+			continue
+		}
+		res[i] = &s
+	}
+	return res, nil
+}
@@ -0,0 +1,708 @@
+package state
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath, supporting the root $, children by .name or ['name'], indexes [n], unions [a,b], slices [start:end:step],
+// wildcards .* and [*], recursive descent .. and filters [?(expr)].
+// Legacy paths, which do not start with $, select a single value, such as .a.b, a.b or . for the root.
+type jsonPath struct {
+	text     string
+	legacy   bool
+	segments []jsonSegment
+}
+
+type jsonSegmentKind int
+
+const (
+	jsonSegmentNames jsonSegmentKind = iota
+	jsonSegmentIndexes
+	jsonSegmentWildcard
+	jsonSegmentSlice
+	jsonSegmentFilter
+)
+
+// jsonSegment selects children of a value, or of its descendants (including itself) if recursive is set
+type jsonSegment struct {
+	kind      jsonSegmentKind
+	recursive bool
+	names     []string
+	indexes   []int
+	// start and end are nil if omitted from a slice
+	start, end *int
+	step       int
+	filter     jsonExpr
+}
+
+// jsonLoc is a value found within a document together with its location, so that it can be replaced or removed
+type jsonLoc struct {
+	// parent is a *jsonArray or *jsonObject, or nil for the root
+	parent any
+	key    string
+	index  int
+	value  any
+	// up is the location of parent, from which normalizedPath is worked out
+	up *jsonLoc
+}
+
+// jsonPathNameEscaper escapes a name to be quoted within a normalized path
+var jsonPathNameEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// normalizedPath returns the JSONPath selecting the value at l alone, such as $["a"][0]
+func (l jsonLoc) normalizedPath() string {
+	var segments []string
+	for ; l.up != nil; l = *l.up {
+		switch l.parent.(type) {
+		case *jsonArray:
+			segments = append(segments, "["+strconv.Itoa(l.index)+"]")
+		case *jsonObject:
+			segments = append(segments, `["`+jsonPathNameEscaper.Replace(l.key)+`"]`)
+		}
+	}
+	slices.Reverse(segments)
+	return "$" + strings.Join(segments, "")
+}
+
+// compileJSONPath compiles a JSONPath or legacy path
+func compileJSONPath(text string) (*jsonPath, error) {
+	p := &jsonPath{text: text}
+	s := text
+	if !strings.HasPrefix(text, "$") {
+		p.legacy = true
+		switch {
+		case text == "." || text == "":
+			s = "$"
+		case strings.HasPrefix(text, ".") || strings.HasPrefix(text, "["):
+			s = "$" + text
+		default:
+			s = "$." + text
+		}
+	}
+	c := &jsonPathCompiler{s: s, pos: 1}
+	segments, err := c.segments()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos != len(c.s) {
+		return nil, ErrorJSONBadPath
+	}
+	p.segments = segments
+	return p, nil
+}
+
+// isStaticChild reports whether the last segment of p selects a single child by name, which JSON.SET may create
+func (p *jsonPath) isStaticChild() bool {
+	if len(p.segments) == 0 {
+		return false
+	}
+	last := p.segments[len(p.segments)-1]
+	return last.kind == jsonSegmentNames && !last.recursive && len(last.names) == 1
+}
+
+func (p *jsonPath) isRoot() bool {
+	return len(p.segments) == 0
+}
+
+// eval returns the values selected by p within root
+func (p *jsonPath) eval(root any) []jsonLoc {
+	return evalJSONSegments(p.segments, jsonLoc{value: root}, root)
+}
+
+// evalParents returns the values selected by p without its last segment
+func (p *jsonPath) evalParents(root any) []jsonLoc {
+	return evalJSONSegments(p.segments[:len(p.segments)-1], jsonLoc{value: root}, root)
+}
+
+func evalJSONSegments(segments []jsonSegment, from jsonLoc, root any) []jsonLoc {
+	locs := []jsonLoc{from}
+	for _, seg := range segments {
+		var next []jsonLoc
+		for _, l := range locs {
+			if seg.recursive {
+				for _, d := range jsonDescendants(l, nil) {
+					next = seg.apply(d, root, next)
+				}
+			} else {
+				next = seg.apply(l, root, next)
+			}
+		}
+		locs = next
+	}
+	return locs
+}
+
+// jsonChildren returns the elements of an array or the values of an object
+func jsonChildren(l jsonLoc) []jsonLoc {
+	var res []jsonLoc
+	switch v := l.value.(type) {
+	case *jsonArray:
+		for i, e := range v.elems {
+			res = append(res, jsonLoc{parent: v, index: i, value: e, up: &l})
+		}
+	case *jsonObject:
+		for _, k := range v.keys {
+			res = append(res, jsonLoc{parent: v, key: k, value: v.values[k], up: &l})
+		}
+	}
+	return res
+}
+
+// jsonDescendants appends l and its descendants in pre-order to res
+func jsonDescendants(l jsonLoc, res []jsonLoc) []jsonLoc {
+	res = append(res, l)
+	for _, c := range jsonChildren(l) {
+		res = jsonDescendants(c, res)
+	}
+	return res
+}
+
+func (seg jsonSegment) apply(l jsonLoc, root any, res []jsonLoc) []jsonLoc {
+	switch seg.kind {
+	case jsonSegmentNames:
+		if o, ok := l.value.(*jsonObject); ok {
+			for _, name := range seg.names {
+				if v, ok := o.values[name]; ok {
+					res = append(res, jsonLoc{parent: o, key: name, value: v, up: &l})
+				}
+			}
+		}
+	case jsonSegmentIndexes:
+		if a, ok := l.value.(*jsonArray); ok {
+			for _, i := range seg.indexes {
+				if i < 0 {
+					i += len(a.elems)
+				}
+				if i >= 0 && i < len(a.elems) {
+					res = append(res, jsonLoc{parent: a, index: i, value: a.elems[i], up: &l})
+				}
+			}
+		}
+	case jsonSegmentWildcard:
+		res = append(res, jsonChildren(l)...)
+	case jsonSegmentSlice:
+		if a, ok := l.value.(*jsonArray); ok && seg.step > 0 {
+			n := len(a.elems)
+			bound := func(p *int, def int) int {
+				if p == nil {
+					return def
+				}
+				i := *p
+				if i < 0 {
+					i += n
+				}
+				return max(0, min(n, i))
+			}
+			for i := bound(seg.start, 0); i < bound(seg.end, n); i += seg.step {
+				res = append(res, jsonLoc{parent: a, index: i, value: a.elems[i], up: &l})
+			}
+		}
+	case jsonSegmentFilter:
+		for _, c := range jsonChildren(l) {
+			if seg.filter.test(c.value, root) {
+				res = append(res, c)
+			}
+		}
+	}
+	return res
+}
+
+// set replaces the value at l within doc
+func (l jsonLoc) set(doc *DbJSON, v any) {
+	switch p := l.parent.(type) {
+	case nil:
+		doc.root = v
+	case *jsonArray:
+		p.elems[l.index] = v
+	case *jsonObject:
+		p.values[l.key] = v
+	}
+}
+
+// removeJSONLocs removes the values at locs, which must not include the root, returning the number removed
+func removeJSONLocs(locs []jsonLoc) int {
+	// remove later elements of an array first, so that the indexes of earlier ones remain valid
+	slices.SortStableFunc(locs, func(a, b jsonLoc) int { return b.index - a.index })
+	n := 0
+	for _, l := range locs {
+		switch p := l.parent.(type) {
+		case *jsonArray:
+			if l.index < len(p.elems) {
+				p.elems = slices.Delete(p.elems, l.index, l.index+1)
+				n++
+			}
+		case *jsonObject:
+			if _, ok := p.values[l.key]; ok {
+				p.remove(l.key)
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// compilation
+
+type jsonPathCompiler struct {
+	s   string
+	pos int
+}
+
+func (c *jsonPathCompiler) peek(prefix string) bool {
+	return strings.HasPrefix(c.s[c.pos:], prefix)
+}
+
+func (c *jsonPathCompiler) skipSpace() {
+	for c.pos < len(c.s) && c.s[c.pos] == ' ' {
+		c.pos++
+	}
+}
+
+// segments compiles segments for as long as they continue
+func (c *jsonPathCompiler) segments() ([]jsonSegment, error) {
+	var res []jsonSegment
+	for {
+		var seg jsonSegment
+		switch {
+		case c.peek(".."):
+			c.pos += 2
+			seg.recursive = true
+			if c.peek("[") {
+				c.pos++
+				if err := c.bracket(&seg); err != nil {
+					return nil, err
+				}
+			} else if err := c.dotted(&seg); err != nil {
+				return nil, err
+			}
+		case c.peek("."):
+			c.pos++
+			if err := c.dotted(&seg); err != nil {
+				return nil, err
+			}
+		case c.peek("["):
+			c.pos++
+			if err := c.bracket(&seg); err != nil {
+				return nil, err
+			}
+		default:
+			return res, nil
+		}
+		res = append(res, seg)
+	}
+}
+
+// dotted compiles the name or wildcard following a dot
+func (c *jsonPathCompiler) dotted(seg *jsonSegment) error {
+	if c.peek("*") {
+		c.pos++
+		seg.kind = jsonSegmentWildcard
+		return nil
+	}
+	start := c.pos
+	for c.pos < len(c.s) && !strings.ContainsRune(".[]()<>=!&|, \"'", rune(c.s[c.pos])) {
+		c.pos++
+	}
+	if c.pos == start {
+		return ErrorJSONBadPath
+	}
+	seg.kind = jsonSegmentNames
+	seg.names = []string{c.s[start:c.pos]}
+	return nil
+}
+
+// bracket compiles the selector following an opening bracket, through the closing bracket
+func (c *jsonPathCompiler) bracket(seg *jsonSegment) error {
+	c.skipSpace()
+	switch {
+	case c.peek("*"):
+		c.pos++
+		seg.kind = jsonSegmentWildcard
+	case c.peek("?"):
+		c.pos++
+		c.skipSpace()
+		expr, err := c.or()
+		if err != nil {
+			return err
+		}
+		seg.kind = jsonSegmentFilter
+		seg.filter = expr
+	case c.peek("'") || c.peek("\""):
+		seg.kind = jsonSegmentNames
+		for {
+			name, err := c.quoted()
+			if err != nil {
+				return err
+			}
+			seg.names = append(seg.names, name)
+			c.skipSpace()
+			if !c.peek(",") {
+				break
+			}
+			c.pos++
+			c.skipSpace()
+		}
+	default:
+		if err := c.indexes(seg); err != nil {
+			return err
+		}
+	}
+	c.skipSpace()
+	if !c.peek("]") {
+		return ErrorJSONBadPath
+	}
+	c.pos++
+	return nil
+}
+
+func (c *jsonPathCompiler) quoted() (string, error) {
+	if c.pos >= len(c.s) || (c.s[c.pos] != '\'' && c.s[c.pos] != '"') {
+		return "", ErrorJSONBadPath
+	}
+	quote := c.s[c.pos]
+	var b strings.Builder
+	for c.pos++; c.pos < len(c.s); c.pos++ {
+		switch ch := c.s[c.pos]; {
+		case ch == quote:
+			c.pos++
+			return b.String(), nil
+		case ch == '\\' && c.pos+1 < len(c.s):
+			c.pos++
+			b.WriteByte(c.s[c.pos])
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return "", ErrorJSONBadPath
+}
+
+// integer compiles an optionally signed integer, returning nil if there is none
+func (c *jsonPathCompiler) integer() *int {
+	start := c.pos
+	if c.peek("-") {
+		c.pos++
+	}
+	for c.pos < len(c.s) && c.s[c.pos] >= '0' && c.s[c.pos] <= '9' {
+		c.pos++
+	}
+	i, err := strconv.Atoi(c.s[start:c.pos])
+	if err != nil {
+		c.pos = start
+		return nil
+	}
+	return &i
+}
+
+// indexes compiles a union of indexes or a slice
+func (c *jsonPathCompiler) indexes(seg *jsonSegment) error {
+	first := c.integer()
+	c.skipSpace()
+	if c.peek(":") {
+		c.pos++
+		c.skipSpace()
+		seg.kind = jsonSegmentSlice
+		seg.start, seg.end, seg.step = first, c.integer(), 1
+		c.skipSpace()
+		if c.peek(":") {
+			c.pos++
+			c.skipSpace()
+			step := c.integer()
+			if step == nil {
+				return ErrorJSONBadPath
+			}
+			seg.step = *step
+		}
+		return nil
+	}
+	if first == nil {
+		return ErrorJSONBadPath
+	}
+	seg.kind = jsonSegmentIndexes
+	seg.indexes = []int{*first}
+	for c.peek(",") {
+		c.pos++
+		c.skipSpace()
+		i := c.integer()
+		if i == nil {
+			return ErrorJSONBadPath
+		}
+		seg.indexes = append(seg.indexes, *i)
+		c.skipSpace()
+	}
+	return nil
+}
+
+// filters
+
+// jsonExpr is a filter expression, evaluated for a candidate value @ within the document $
+type jsonExpr interface {
+	test(current, root any) bool
+}
+
+type jsonAnd struct{ left, right jsonExpr }
+type jsonOr struct{ left, right jsonExpr }
+type jsonNot struct{ expr jsonExpr }
+
+// jsonComparison compares two operands, or tests that the left operand exists if op is empty
+type jsonComparison struct {
+	left, right jsonOperand
+	op          string
+	re          *regexp.Regexp
+}
+
+// jsonOperand is either a literal, or a path relative to @ or $ of which the first value found is used
+type jsonOperand struct {
+	literal  any
+	relative bool
+	absolute bool
+	segments []jsonSegment
+}
+
+func (e jsonAnd) test(current, root any) bool {
+	return e.left.test(current, root) && e.right.test(current, root)
+}
+
+func (e jsonOr) test(current, root any) bool {
+	return e.left.test(current, root) || e.right.test(current, root)
+}
+
+func (e jsonNot) test(current, root any) bool {
+	return !e.expr.test(current, root)
+}
+
+func (o jsonOperand) eval(current, root any) (any, bool) {
+	if !o.relative && !o.absolute {
+		return o.literal, true
+	}
+	from := current
+	if o.absolute {
+		from = root
+	}
+	locs := evalJSONSegments(o.segments, jsonLoc{value: from}, root)
+	if len(locs) == 0 {
+		return nil, false
+	}
+	return locs[0].value, true
+}
+
+func (e jsonComparison) test(current, root any) bool {
+	left, ok := e.left.eval(current, root)
+	if !ok {
+		return false
+	}
+	if e.op == "" {
+		return true
+	}
+	right, ok := e.right.eval(current, root)
+	if !ok {
+		return false
+	}
+	if e.op == "=~" {
+		s, ok := left.(string)
+		return ok && e.re != nil && e.re.MatchString(s)
+	}
+	cmp, comparable := compareJSON(left, right)
+	switch e.op {
+	case "==":
+		return comparable && cmp == 0
+	case "!=":
+		return !comparable || cmp != 0
+	case "<":
+		return comparable && cmp < 0
+	case "<=":
+		return comparable && cmp <= 0
+	case ">":
+		return comparable && cmp > 0
+	case ">=":
+		return comparable && cmp >= 0
+	}
+	return false
+}
+
+func jsonNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// compareJSON orders numbers and strings, and reports whether other values are equal (cmp 0) or not, returning false if a and b are not comparable
+func compareJSON(a, b any) (int, bool) {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			return cmpInt64(x, y), true
+		}
+	}
+	if x, ok := jsonNumber(a); ok {
+		if y, ok := jsonNumber(b); ok {
+			if math.IsNaN(x) || math.IsNaN(y) {
+				return 0, false
+			}
+			return cmpFloat(x, y), true
+		}
+		return 0, false
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+		return 0, false
+	}
+	// other values are only tested for equality, which compares arrays and objects by their serialization
+	if jsonTypeName(a) != jsonTypeName(b) {
+		return 0, false
+	}
+	if (JSONFormat{}).serialize(a) == (JSONFormat{}).serialize(b) {
+		return 0, true
+	}
+	return 1, true
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (c *jsonPathCompiler) or() (jsonExpr, error) {
+	left, err := c.and()
+	if err != nil {
+		return nil, err
+	}
+	for c.skipSpace(); c.peek("||"); c.skipSpace() {
+		c.pos += 2
+		right, err := c.and()
+		if err != nil {
+			return nil, err
+		}
+		left = jsonOr{left, right}
+	}
+	return left, nil
+}
+
+func (c *jsonPathCompiler) and() (jsonExpr, error) {
+	left, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+	for c.skipSpace(); c.peek("&&"); c.skipSpace() {
+		c.pos += 2
+		right, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = jsonAnd{left, right}
+	}
+	return left, nil
+}
+
+func (c *jsonPathCompiler) unary() (jsonExpr, error) {
+	c.skipSpace()
+	switch {
+	case c.peek("!") && !c.peek("!="):
+		c.pos++
+		expr, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		return jsonNot{expr}, nil
+	case c.peek("("):
+		c.pos++
+		expr, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		c.skipSpace()
+		if !c.peek(")") {
+			return nil, ErrorJSONBadPath
+		}
+		c.pos++
+		return expr, nil
+	}
+	return c.comparison()
+}
+
+func (c *jsonPathCompiler) comparison() (jsonExpr, error) {
+	left, err := c.operand()
+	if err != nil {
+		return nil, err
+	}
+	c.skipSpace()
+	var e jsonComparison
+	e.left = left
+	for _, op := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		if c.peek(op) {
+			c.pos += len(op)
+			e.op = op
+			break
+		}
+	}
+	if e.op == "" {
+		return e, nil
+	}
+	c.skipSpace()
+	if e.right, err = c.operand(); err != nil {
+		return nil, err
+	}
+	if e.op == "=~" {
+		pattern, ok := e.right.literal.(string)
+		if !ok {
+			return nil, ErrorJSONBadPath
+		}
+		if e.re, err = regexp.Compile(pattern); err != nil {
+			return nil, ErrorJSONBadPath
+		}
+	}
+	return e, nil
+}
+
+func (c *jsonPathCompiler) operand() (jsonOperand, error) {
+	var o jsonOperand
+	switch {
+	case c.peek("@") || c.peek("$"):
+		o.relative, o.absolute = c.peek("@"), c.peek("$")
+		c.pos++
+		segments, err := c.segments()
+		if err != nil {
+			return o, err
+		}
+		o.segments = segments
+	case c.peek("'") || c.peek("\""):
+		s, err := c.quoted()
+		if err != nil {
+			return o, err
+		}
+		o.literal = s
+	case c.peek("true"):
+		c.pos += 4
+		o.literal = true
+	case c.peek("false"):
+		c.pos += 5
+		o.literal = false
+	case c.peek("null"):
+		c.pos += 4
+		o.literal = nil
+	default:
+		start := c.pos
+		for c.pos < len(c.s) && strings.IndexByte("+-.0123456789eE", c.s[c.pos]) >= 0 {
+			c.pos++
+		}
+		v, err := parseJSON(c.s[start:c.pos])
+		if err != nil || c.pos == start {
+			return o, ErrorJSONBadPath
+		}
+		o.literal = v
+	}
+	return o, nil
+}
@@ -0,0 +1,309 @@
+package state
+
+import (
+	"errors"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var ErrorSameObject = errors.New("ERR source and destination objects are the same")
+
+// Keyspace operations, which apply to values of any type
+
+// unsafeDelete deletes the value at key, even if it has logically expired, and detaches it from the state that refers to it by its key:
+// search indexes and compaction rules. It returns the value deleted, and whether it had yet to expire. The caller must hold DbMu.
+func unsafeDelete(key string, now time.Time) (DbValue, bool) {
+	v, ok := state.Db.Get(key)
+	if !ok {
+		return nil, false
+	}
+	_, live := unsafeLookup(key, now)
+	state.Db.Delete(key)
+	state.SortedKeys.Remove(key)
+	if ts, ok := v.(*DbTimeSeries); ok {
+		ts.unsafeDetach(key)
+	}
+	unsafeReindexKey(key)
+	return v, live
+}
+
+// unsafeSet sets key to v, replacing any value there, which is detached as with unsafeDelete, even if it has logically expired.
+// Unlike unsafeStore, it serves no blocked clients, for callers that serve them once done. The caller must hold DbMu.
+func unsafeSet(key string, v DbValue) {
+	old, ok := state.Db.Get(key)
+	if ok && old == v {
+		return
+	}
+	if state.Db.Set(key, v) {
+		state.SortedKeys.Add(key, 0)
+	}
+	if ts, ok := old.(*DbTimeSeries); ok {
+		ts.unsafeDetach(key)
+	}
+	unsafeReindexKey(key)
+}
+
+// unsafeStore stores v at key, which must be empty, and serves any clients blocked on key. It returns the commands effecting the pops
+// of the clients served. The caller must hold DbMu.
+func unsafeStore(key string, v DbValue) []resp.RESP {
+	unsafeSet(key, v)
+	unsafeServeStreamReaders(key)
+	return unsafeServeBlockers(key)
+}
+
+// unsafeEachKeyWithPrefix calls f with each key starting with prefix in lexicographic order, along with its value, until it returns false.
+// f must not modify the database. The caller must hold DbMu.
+func unsafeEachKeyWithPrefix(prefix string, f func(key string, v DbValue) bool) {
+	x, _ := state.SortedKeys.seek(func(n *zsetNode) bool { return n.Member < prefix })
+	for n := x.level[0].forward; n != nil && strings.HasPrefix(n.Member, prefix); n = n.level[0].forward {
+		if !f(n.Member, state.Db.Value(n.Member)) {
+			return
+		}
+	}
+}
+
+// Del deletes the values at keys and returns the number of keys that held one. It serves UNLINK as well, synchronously,
+// since the garbage collector already reclaims the values deleted concurrently, off the request path.
+func Del(keys []string) int64 {
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	var n int64
+	for _, key := range keys {
+		if _, live := unsafeDelete(key, now); live {
+			n++
+		}
+	}
+	return n
+}
+
+// Exists returns the number of keys that hold a value, counting keys given more than once as many times
+func Exists(keys []string) int64 {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	now := time.Now()
+	var n int64
+	for _, key := range keys {
+		if _, ok := unsafeLookup(key, now); ok {
+			n++
+		}
+	}
+	return n
+}
+
+// Rename moves the value at src to dst, replacing any value at dst unless onlyIfNew is set, in which case it returns false if dst holds a value.
+// It returns ErrorNone if there is no value at src, along with the commands effecting the pops of any clients blocked on dst that were served.
+func Rename(src, dst string, onlyIfNew bool) (bool, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	v, ok := unsafeLookup(src, now)
+	if !ok {
+		return false, nil, ErrorNone
+	}
+	if src == dst {
+		return !onlyIfNew, nil, nil
+	}
+	if _, ok := unsafeLookup(dst, now); ok && onlyIfNew {
+		return false, nil, nil
+	}
+	unsafeDelete(dst, now)
+	state.Db.Delete(src)
+	state.SortedKeys.Remove(src)
+	if ts, ok := v.(*DbTimeSeries); ok {
+		ts.unsafeRename(src, dst)
+	}
+	unsafeReindexKey(src)
+	return true, unsafeStore(dst, v), nil
+}
+
+// Copy copies the value at src to dst along with its expiry, replacing any value at dst only if replace is set. It returns false if there
+// is no value at src, or if dst holds a value and replace is not set, along with the commands effecting the pops of any clients blocked
+// on dst that were served.
+func Copy(src, dst string, replace bool) (bool, []resp.RESP, error) {
+	if src == dst {
+		return false, nil, ErrorSameObject
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	v, ok := unsafeLookup(src, now)
+	if !ok {
+		return false, nil, nil
+	}
+	if _, ok := unsafeLookup(dst, now); ok && !replace {
+		return false, nil, nil
+	}
+	unsafeDelete(dst, now)
+	w := cloneValue(v)
+	w.setExpiresAt(v.ExpiresAt())
+	return true, unsafeStore(dst, w), nil
+}
+
+// randomkeyTries is the number of random keys Randomkey tries before looking through every key for one that has yet to expire
+const randomkeyTries = 100
+
+// Randomkey returns a random key holding a value, or false if there is none
+func Randomkey() (string, bool) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	now := time.Now()
+	for range randomkeyTries {
+		key, v, ok := state.Db.Random()
+		if !ok {
+			return "", false
+		}
+		if !v.isExpiredAt(now) {
+			return key, true
+		}
+	}
+	var res string
+	state.Db.Each(func(key string, v DbValue) bool {
+		if v.isExpiredAt(now) {
+			return true
+		}
+		res = key
+		return false
+	})
+	return res, res != ""
+}
+
+// Dbsize returns the number of keys, including those holding values that have expired but have yet to be evicted
+func Dbsize() int64 {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	return int64(state.Db.Len())
+}
+
+// Flushall deletes every key, along with the search indexes. It serves FLUSHALL ASYNC as well, synchronously, as with Del.
+func Flushall() {
+	LockDbMu()
+	defer UnlockDbMu()
+	UnsafeResetDbWithSizeHint(0)
+}
+
+// cloneValue returns a deep copy of v. Compaction rules are not copied along with time series, and the copies of values relying on
+// seeded randomness start over from the seed, so that replicas copying the same value end up with the same copy.
+func cloneValue(v DbValue) DbValue {
+	switch v := v.(type) {
+	case *DbString:
+		w := *v
+		return &w
+	case *DbList:
+		w := &DbList{}
+		for _, elem := range v.Range(0, -1) {
+			w.PushBack(elem)
+		}
+		return w
+	case *DbHash:
+		return &DbHash{pairs: slices.Clone(v.pairs), m: v.m.Clone(), expires: maps.Clone(v.expires), minExpiry: v.minExpiry}
+	case *DbSet:
+		return &DbSet{ints: slices.Clone(v.ints), members: slices.Clone(v.members), index: v.index.Clone()}
+	case *DbZSet:
+		w := NewDbZSet()
+		for _, m := range v.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: -1}) {
+			w.Add(m.Member, m.Score)
+		}
+		return w
+	case *DbStream:
+		return &DbStream{data: slices.Clone(v.data)}
+	case *DbJSON:
+		return &DbJSON{root: cloneJSON(v.root)}
+	case *DbBloom:
+		w := *v
+		w.filters = make([]*bloomFilter, len(v.filters))
+		for i, f := range v.filters {
+			g := *f
+			g.bits = slices.Clone(f.bits)
+			w.filters[i] = &g
+		}
+		return &w
+	case *DbCuckoo:
+		w := *v
+		w.filters = make([]*cuckooFilter, len(v.filters))
+		for i, f := range v.filters {
+			g := *f
+			g.slots = slices.Clone(f.slots)
+			w.filters[i] = &g
+		}
+		return &w
+	case *DbCMS:
+		w := *v
+		w.counters = slices.Clone(v.counters)
+		return &w
+	case *DbTopK:
+		w := *v
+		w.buckets = slices.Clone(v.buckets)
+		w.heap = slices.Clone(v.heap)
+		w.rand = rand.New(rand.NewSource(topkRandSeed))
+		return &w
+	case *DbTDigest:
+		w := *v
+		w.merged = slices.Clone(v.merged)
+		w.unmerged = slices.Clone(v.unmerged)
+		return &w
+	case *DbTimeSeries:
+		w := *v
+		w.chunks = make([]tsChunk, len(v.chunks))
+		for i, c := range v.chunks {
+			switch c := c.(type) {
+			case *tsRawChunk:
+				w.chunks[i] = &tsRawChunk{s: slices.Clone(c.s)}
+			case *tsCompressedChunk:
+				d := *c
+				d.data = slices.Clone(c.data)
+				w.chunks[i] = &d
+			}
+		}
+		w.labels = slices.Clone(v.labels)
+		w.rules, w.srcKey = nil, ""
+		return &w
+	case *DbVectorSet:
+		w := *v
+		w.nodes = make(map[string]*vsetNode, len(v.nodes))
+		for element, n := range v.nodes {
+			m := *n
+			w.nodes[element] = &m
+		}
+		for _, m := range w.nodes {
+			links := make([][]*vsetNode, len(m.links))
+			for i, layer := range m.links {
+				links[i] = make([]*vsetNode, len(layer))
+				for j, n := range layer {
+					links[i][j] = w.nodes[n.element]
+				}
+			}
+			m.links = links
+		}
+		if v.entry != nil {
+			w.entry = w.nodes[v.entry.element]
+		}
+		w.rand = rand.New(rand.NewSource(vsetRandSeed))
+		return &w
+	}
+	return v
+}
+
+// cloneJSON returns a deep copy of a JSON value
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case *jsonObject:
+		w := &jsonObject{keys: slices.Clone(v.keys), values: make(map[string]any, len(v.values))}
+		for key, value := range v.values {
+			w.values[key] = cloneJSON(value)
+		}
+		return w
+	case *jsonArray:
+		w := &jsonArray{elems: make([]any, len(v.elems))}
+		for i, elem := range v.elems {
+			w.elems[i] = cloneJSON(elem)
+		}
+		return w
+	}
+	return v
+}
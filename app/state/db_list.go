@@ -0,0 +1,625 @@
+package state
+
+import (
+	"errors"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// listChunkSize is the maximum number of elements held by a single chunk of a DbList.
+const listChunkSize = 128
+
+var (
+	ErrorIndexOutOfRange = errors.New("ERR index out of range")
+)
+
+type listChunk struct {
+	prev  *listChunk
+	next  *listChunk
+	elems []string
+}
+
+// DbList is a doubly-linked list of chunks, each holding at most listChunkSize elements.
+// Pushes and pops at either end only ever touch a single chunk, so they are O(1) regardless of the length of the list,
+// while operations in the middle of the list only need to shift elements within a single chunk.
+type DbList struct {
+	dbExpiry
+	head   *listChunk
+	tail   *listChunk
+	length int
+}
+
+var _ DbValue = (*DbList)(nil)
+
+func (v *DbList) Type() string {
+	return "list"
+}
+
+func (v *DbList) Len() int {
+	return v.length
+}
+
+func (v *DbList) PushFront(elem string) {
+	if v.head == nil || len(v.head.elems) >= listChunkSize {
+		c := &listChunk{next: v.head, elems: make([]string, 0, listChunkSize)}
+		if v.head != nil {
+			v.head.prev = c
+		} else {
+			v.tail = c
+		}
+		v.head = c
+	}
+	c := v.head
+	c.elems = append(c.elems, "")
+	copy(c.elems[1:], c.elems)
+	c.elems[0] = elem
+	v.length++
+}
+
+func (v *DbList) PushBack(elem string) {
+	if v.tail == nil || len(v.tail.elems) >= listChunkSize {
+		c := &listChunk{prev: v.tail, elems: make([]string, 0, listChunkSize)}
+		if v.tail != nil {
+			v.tail.next = c
+		} else {
+			v.head = c
+		}
+		v.tail = c
+	}
+	v.tail.elems = append(v.tail.elems, elem)
+	v.length++
+}
+
+func (v *DbList) PopFront() (string, bool) {
+	if v.length == 0 {
+		return "", false
+	}
+	c := v.head
+	elem := c.elems[0]
+	// the popped slot is cleared so that the backing array does not keep the element reachable
+	c.elems[0] = ""
+	c.elems = c.elems[1:]
+	v.length--
+	if len(c.elems) == 0 {
+		v.unlink(c)
+	}
+	return elem, true
+}
+
+func (v *DbList) PopBack() (string, bool) {
+	if v.length == 0 {
+		return "", false
+	}
+	c := v.tail
+	elem := c.elems[len(c.elems)-1]
+	c.elems[len(c.elems)-1] = ""
+	c.elems = c.elems[:len(c.elems)-1]
+	v.length--
+	if len(c.elems) == 0 {
+		v.unlink(c)
+	}
+	return elem, true
+}
+
+func (v *DbList) unlink(c *listChunk) {
+	if c.prev != nil {
+		c.prev.next = c.next
+	} else {
+		v.head = c.next
+	}
+	if c.next != nil {
+		c.next.prev = c.prev
+	} else {
+		v.tail = c.prev
+	}
+}
+
+// locate returns the chunk containing the i-th element and the offset of the element in the chunk, walking from whichever end is closer.
+// i must satisfy 0 <= i < v.Len().
+func (v *DbList) locate(i int) (*listChunk, int) {
+	if i < v.length/2 {
+		c := v.head
+		for i >= len(c.elems) {
+			i -= len(c.elems)
+			c = c.next
+		}
+		return c, i
+	}
+	i = v.length - 1 - i
+	c := v.tail
+	for i >= len(c.elems) {
+		i -= len(c.elems)
+		c = c.prev
+	}
+	return c, len(c.elems) - 1 - i
+}
+
+// insertAt inserts elem at offset j of chunk c, splitting the chunk if it overflows.
+func (v *DbList) insertAt(c *listChunk, j int, elem string) {
+	c.elems = append(c.elems, "")
+	copy(c.elems[j+1:], c.elems[j:])
+	c.elems[j] = elem
+	v.length++
+	if len(c.elems) <= listChunkSize {
+		return
+	}
+	half := len(c.elems) / 2
+	n := &listChunk{prev: c, next: c.next, elems: make([]string, len(c.elems)-half, listChunkSize)}
+	copy(n.elems, c.elems[half:])
+	clear(c.elems[half:])
+	c.elems = c.elems[:half]
+	if c.next != nil {
+		c.next.prev = n
+	} else {
+		v.tail = n
+	}
+	c.next = n
+}
+
+// Index returns the i-th element, with negative indices counting from the tail.
+func (v *DbList) Index(i int) (string, bool) {
+	if i < 0 {
+		i += v.length
+	}
+	if i < 0 || i >= v.length {
+		return "", false
+	}
+	c, j := v.locate(i)
+	return c.elems[j], true
+}
+
+// Set replaces the i-th element, with negative indices counting from the tail.
+func (v *DbList) Set(i int, elem string) bool {
+	if i < 0 {
+		i += v.length
+	}
+	if i < 0 || i >= v.length {
+		return false
+	}
+	c, j := v.locate(i)
+	c.elems[j] = elem
+	return true
+}
+
+// Range returns the elements between the inclusive indices start and stop, with negative indices counting from the tail.
+func (v *DbList) Range(start, stop int) []string {
+	start, stop, ok := normalizeRange(start, stop, v.length)
+	if !ok {
+		return []string{}
+	}
+	res := make([]string, 0, stop-start+1)
+	c, j := v.locate(start)
+	for len(res) < cap(res) {
+		if j >= len(c.elems) {
+			c, j = c.next, 0
+			continue
+		}
+		res = append(res, c.elems[j])
+		j++
+	}
+	return res
+}
+
+// Insert inserts elem immediately before or after the first occurrence of pivot from the head.
+// It returns false if pivot is not found.
+func (v *DbList) Insert(pivot, elem string, before bool) bool {
+	for c := v.head; c != nil; c = c.next {
+		for j, e := range c.elems {
+			if e != pivot {
+				continue
+			}
+			if !before {
+				j++
+			}
+			v.insertAt(c, j, elem)
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes up to count occurrences of elem, starting from the head if count is positive and from the tail if it is negative.
+// All occurrences are removed if count is 0. It returns the number of elements removed.
+func (v *DbList) Remove(count int, elem string) int {
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	removed := 0
+	if count == 0 {
+		for c := v.head; c != nil; {
+			next := c.next
+			removed += v.filterChunk(c, elem, 0, false)
+			c = next
+		}
+	} else if count > 0 {
+		for c := v.head; c != nil && removed < limit; {
+			next := c.next
+			removed += v.filterChunk(c, elem, limit-removed, false)
+			c = next
+		}
+	} else {
+		for c := v.tail; c != nil && removed < limit; {
+			prev := c.prev
+			removed += v.filterChunk(c, elem, limit-removed, true)
+			c = prev
+		}
+	}
+	return removed
+}
+
+// filterChunk removes up to limit (or all, if limit is 0) occurrences of elem from c, scanning backwards if reverse is set.
+func (v *DbList) filterChunk(c *listChunk, elem string, limit int, reverse bool) int {
+	removed := 0
+	if reverse {
+		w := len(c.elems)
+		for r := len(c.elems) - 1; r >= 0; r-- {
+			if c.elems[r] == elem && (limit == 0 || removed < limit) {
+				removed++
+				continue
+			}
+			w--
+			c.elems[w] = c.elems[r]
+		}
+		clear(c.elems[:w])
+		c.elems = c.elems[w:]
+	} else {
+		w := 0
+		for _, e := range c.elems {
+			if e == elem && (limit == 0 || removed < limit) {
+				removed++
+				continue
+			}
+			c.elems[w] = e
+			w++
+		}
+		clear(c.elems[w:])
+		c.elems = c.elems[:w]
+	}
+	v.length -= removed
+	if len(c.elems) == 0 {
+		v.unlink(c)
+	}
+	return removed
+}
+
+// Trim retains only the elements between the inclusive indices start and stop, with negative indices counting from the tail.
+func (v *DbList) Trim(start, stop int) {
+	start, stop, ok := normalizeRange(start, stop, v.length)
+	if !ok {
+		v.head, v.tail, v.length = nil, nil, 0
+		return
+	}
+	for n := v.length - 1 - stop; n > 0; n-- {
+		v.PopBack()
+	}
+	for n := start; n > 0; n-- {
+		v.PopFront()
+	}
+}
+
+// Pos returns the indices of up to count (or all, if count is 0) occurrences of elem, skipping the first |rank|-1 matches
+// and scanning from the tail if rank is negative. At most maxlen elements are compared, unless maxlen is 0.
+func (v *DbList) Pos(elem string, rank, count, maxlen int) []int {
+	res := make([]int, 0)
+	skip := rank - 1
+	if rank < 0 {
+		skip = -rank - 1
+	}
+	scanned := 0
+	match := func(i int, e string) bool {
+		if maxlen != 0 && scanned >= maxlen {
+			return false
+		}
+		scanned++
+		if e == elem {
+			if skip > 0 {
+				skip--
+			} else {
+				res = append(res, i)
+			}
+		}
+		return count == 0 || len(res) < count
+	}
+	if rank > 0 {
+		i := 0
+		for c := v.head; c != nil; c = c.next {
+			for _, e := range c.elems {
+				if !match(i, e) {
+					return res
+				}
+				i++
+			}
+		}
+	} else {
+		i := v.length - 1
+		for c := v.tail; c != nil; c = c.prev {
+			for j := len(c.elems) - 1; j >= 0; j-- {
+				if !match(i, c.elems[j]) {
+					return res
+				}
+				i--
+			}
+		}
+	}
+	return res
+}
+
+// normalizeRange resolves negative inclusive indices against length and clamps them to the bounds of a sequence.
+// It returns false if the resulting range is empty.
+func normalizeRange(start, stop, length int) (int, int, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
+
+// unsafeGetList returns the list stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetList(key string) (*DbList, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	l, ok := v.(*DbList)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return l, nil
+}
+
+// List operations
+
+// Push pushes elems onto the head of the list at key if front is set, and onto its tail otherwise, creating the list unless onlyIfExists is set.
+// It returns the length of the list after the push, and the commands effecting the pops of any clients blocked on the list that were served as a result.
+func Push(key string, elems []string, front, onlyIfExists bool) (int64, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	if l == nil {
+		if onlyIfExists {
+			return 0, nil, nil
+		}
+		l = &DbList{}
+		unsafeSet(key, l)
+	}
+	for _, elem := range elems {
+		if front {
+			l.PushFront(elem)
+		} else {
+			l.PushBack(elem)
+		}
+	}
+	n := int64(l.Len())
+	return n, unsafeServeBlockers(key), nil
+}
+
+// Pop pops up to count elements from the head of the list at key if front is set, and from its tail otherwise.
+// It returns ErrorNone if there is no such list.
+func Pop(key string, front bool, count int64) ([]string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return nil, ErrorNone
+	}
+	return unsafePopList(key, l, front, count), nil
+}
+
+func unsafePopList(key string, l *DbList, front bool, count int64) []string {
+	res := make([]string, 0, min(count, int64(l.Len())))
+	for int64(len(res)) < count {
+		var elem string
+		var ok bool
+		if front {
+			elem, ok = l.PopFront()
+		} else {
+			elem, ok = l.PopBack()
+		}
+		if !ok {
+			break
+		}
+		res = append(res, elem)
+	}
+	if l.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return res
+}
+
+func Lrange(key string, start, stop int64) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return []string{}, nil
+	}
+	return l.Range(clampInt(start), clampInt(stop)), nil
+}
+
+// Lindex returns ErrorNone if there is no such list or index.
+func Lindex(key string, index int64) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return "", err
+	}
+	if l == nil {
+		return "", ErrorNone
+	}
+	elem, ok := l.Index(clampInt(index))
+	if !ok {
+		return "", ErrorNone
+	}
+	return elem, nil
+}
+
+func Lset(key string, index int64, elem string) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		return ErrorNone
+	}
+	if !l.Set(clampInt(index), elem) {
+		return ErrorIndexOutOfRange
+	}
+	return nil
+}
+
+// Linsert returns the length of the list after the insertion, 0 if there is no such list, and -1 if pivot is not found.
+func Linsert(key string, before bool, pivot, elem string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return 0, err
+	}
+	if l == nil {
+		return 0, nil
+	}
+	if !l.Insert(pivot, elem, before) {
+		return -1, nil
+	}
+	return int64(l.Len()), nil
+}
+
+func Lrem(key string, count int64, elem string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return 0, err
+	}
+	if l == nil {
+		return 0, nil
+	}
+	removed := l.Remove(clampInt(count), elem)
+	if l.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return int64(removed), nil
+}
+
+func Ltrim(key string, start, stop int64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return err
+	}
+	if l == nil {
+		return nil
+	}
+	l.Trim(clampInt(start), clampInt(stop))
+	if l.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return nil
+}
+
+func Llen(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return 0, err
+	}
+	if l == nil {
+		return 0, nil
+	}
+	return int64(l.Len()), nil
+}
+
+func Lpos(key, elem string, rank, count, maxlen int64) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	l, err := unsafeGetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		return []int64{}, nil
+	}
+	pos := l.Pos(elem, clampInt(rank), clampInt(count), clampInt(maxlen))
+	res := make([]int64, len(pos))
+	for i, p := range pos {
+		res[i] = int64(p)
+	}
+	return res, nil
+}
+
+// Lmove atomically pops an element from the list at src and pushes it onto the list at dst.
+// It returns ErrorNone if there is no list at src, and otherwise also the commands effecting the pops of any clients blocked on dst that were served as a result.
+func Lmove(src, dst string, srcFront, dstFront bool) (string, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	elem, err := unsafeLmove(src, dst, srcFront, dstFront)
+	if err != nil {
+		return "", nil, err
+	}
+	return elem, unsafeServeBlockers(dst), nil
+}
+
+func unsafeLmove(src, dst string, srcFront, dstFront bool) (string, error) {
+	sl, err := unsafeGetList(src)
+	if err != nil {
+		return "", err
+	}
+	if sl == nil {
+		return "", ErrorNone
+	}
+	if _, err := unsafeGetList(dst); err != nil {
+		return "", err
+	}
+	elems := unsafePopList(src, sl, srcFront, 1)
+	// dst is looked up again since popping may have removed it if it is also src
+	dl, _ := unsafeGetList(dst)
+	if dl == nil {
+		dl = &DbList{}
+		unsafeSet(dst, dl)
+	}
+	if dstFront {
+		dl.PushFront(elems[0])
+	} else {
+		dl.PushBack(elems[0])
+	}
+	return elems[0], nil
+}
+
+// clampInt converts i to an int, saturating at the bounds of int on platforms where it is narrower than int64.
+func clampInt(i int64) int {
+	const maxInt = int64(^uint(0) >> 1)
+	if i > maxInt {
+		return int(maxInt)
+	}
+	if i < -maxInt-1 {
+		return int(-maxInt - 1)
+	}
+	return int(i)
+}
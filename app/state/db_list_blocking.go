@@ -0,0 +1,71 @@
+package state
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// listPopper adapts pop, which pops from the non-empty list l stored at key, into a blockPopper
+func listPopper(pop func(key string, l *DbList) (resp.RESP, []resp.RESP, []string)) blockPopper {
+	return func(key string) (resp.RESP, []resp.RESP, []string, error) {
+		l, err := unsafeGetList(key)
+		if err != nil || l == nil {
+			return nil, nil, nil, err
+		}
+		res, cmds, pushed := pop(key, l)
+		return res, cmds, pushed, nil
+	}
+}
+
+func listPopCommand(key string, front bool, count int64) resp.RESP {
+	name := "RPOP"
+	if front {
+		name = "LPOP"
+	}
+	if count < 0 {
+		return resp.EncodeStringSlice([]string{name, key})
+	}
+	return resp.EncodeStringSlice([]string{name, key, strconv.FormatInt(count, 10)})
+}
+
+func listEndName(front bool) string {
+	if front {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
+// Blpop pops a single element from the first non-empty list among keys, as with BLPOP and BRPOP.
+// It returns nil if no list became non-empty in time.
+func Blpop(keys []string, front bool, timeout time.Duration, block bool) (resp.RESP, error) {
+	return blockingOp(keys, listPopper(func(key string, l *DbList) (resp.RESP, []resp.RESP, []string) {
+		elems := unsafePopList(key, l, front, 1)
+		res := resp.EncodeStringSlice([]string{key, elems[0]})
+		return res, []resp.RESP{listPopCommand(key, front, -1)}, nil
+	}), timeout, block)
+}
+
+// Blmpop pops up to count elements from the first non-empty list among keys, as with BLMPOP and LMPOP.
+// It returns nil if no list became non-empty in time.
+func Blmpop(keys []string, front bool, count int64, timeout time.Duration, block bool) (resp.RESP, error) {
+	return blockingOp(keys, listPopper(func(key string, l *DbList) (resp.RESP, []resp.RESP, []string) {
+		elems := unsafePopList(key, l, front, count)
+		res := &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: key}, resp.EncodeStringSlice(elems)}}
+		return res, []resp.RESP{listPopCommand(key, front, count)}, nil
+	}), timeout, block)
+}
+
+// Blmove atomically pops an element from the list at src and pushes it onto the list at dst, waiting for src to become non-empty as with BLMOVE.
+// It returns nil if src did not become non-empty in time.
+func Blmove(src, dst string, srcFront, dstFront bool, timeout time.Duration, block bool) (resp.RESP, error) {
+	return blockingOp([]string{src}, listPopper(func(key string, l *DbList) (resp.RESP, []resp.RESP, []string) {
+		elem, err := unsafeLmove(key, dst, srcFront, dstFront)
+		if err != nil {
+			return &resp.RESPSimpleError{Value: err.Error()}, nil, nil
+		}
+		cmd := resp.EncodeStringSlice([]string{"LMOVE", key, dst, listEndName(srcFront), listEndName(dstFront)})
+		return &resp.RESPBulkString{Value: elem}, []resp.RESP{cmd}, []string{dst}
+	}), timeout, block)
+}
@@ -0,0 +1,122 @@
+package state
+
+import (
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/utility"
+)
+
+// ScanOptions are the options of SCAN, HSCAN, SSCAN and ZSCAN. Only the elements matching the glob-style pattern Match are returned
+// unless it is empty, and only the keys holding values of type Type unless it is empty, which only SCAN accepts. Count is the number
+// of elements to aim for in each call, which is a hint: compactly encoded values are returned whole, and sparse buckets cut calls short.
+// NoValues requests the fields of a hash without their values.
+type ScanOptions struct {
+	Match    string
+	Count    int64
+	Type     string
+	NoValues bool
+}
+
+func (opts ScanOptions) matches(elem string) bool {
+	return opts.Match == "" || utility.GlobMatch(opts.Match, elem)
+}
+
+// scanDict walks d from cursor, calling f with each entry visited, until at least count entries have been visited,
+// or ten times count buckets if they are sparse. It returns the cursor to continue from, which is 0 once the walk is complete.
+func scanDict[V any](d *dict[V], cursor uint64, count int64, f func(key string, value V)) uint64 {
+	var n int64
+	for buckets := count * 10; ; buckets-- {
+		cursor = d.Scan(cursor, func(key string, value V) {
+			f(key, value)
+			n++
+		})
+		if cursor == 0 || n >= count || buckets <= 1 {
+			return cursor
+		}
+	}
+}
+
+// Scan returns the keys visited walking the keyspace from cursor, along with the cursor to continue from.
+// Every key present for the whole of a walk from cursor 0 to cursor 0 is returned at least once, though keys may be returned more than once.
+func Scan(cursor uint64, opts ScanOptions) (uint64, []string) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	now := time.Now()
+	var keys []string
+	cursor = scanDict(state.Db, cursor, opts.Count, func(key string, v DbValue) {
+		if !v.isExpiredAt(now) && (opts.Type == "" || strings.EqualFold(v.Type(), opts.Type)) && opts.matches(key) {
+			keys = append(keys, key)
+		}
+	})
+	return cursor, keys
+}
+
+// Hscan returns the fields and values, alternately, visited walking the hash at key from cursor, along with the cursor to continue from.
+// Only the fields are returned if opts.NoValues is set. A compactly encoded hash is returned whole, with a cursor of 0.
+func Hscan(key string, cursor uint64, opts ScanOptions) (uint64, []string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	h, err := unsafeGetHash(key)
+	if err != nil || h == nil {
+		return 0, []string{}, err
+	}
+	now := time.Now()
+	res := []string{}
+	add := func(field, value string) {
+		if h.isFieldExpiredAt(field, now) || !opts.matches(field) {
+			return
+		}
+		if res = append(res, field); !opts.NoValues {
+			res = append(res, value)
+		}
+	}
+	if h.isCompact() {
+		for i := 0; i < len(h.pairs); i += 2 {
+			add(h.pairs[i], h.pairs[i+1])
+		}
+		return 0, res, nil
+	}
+	return scanDict(h.m, cursor, opts.Count, add), res, nil
+}
+
+// Sscan returns the members visited walking the set at key from cursor, along with the cursor to continue from.
+// A set of integers is returned whole, with a cursor of 0.
+func Sscan(key string, cursor uint64, opts ScanOptions) (uint64, []string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil || s == nil {
+		return 0, []string{}, err
+	}
+	res := []string{}
+	add := func(member string, _ int) {
+		if opts.matches(member) {
+			res = append(res, member)
+		}
+	}
+	if s.isIntset() {
+		for _, member := range s.Members() {
+			add(member, 0)
+		}
+		return 0, res, nil
+	}
+	return scanDict(s.index, cursor, opts.Count, add), res, nil
+}
+
+// Zscan returns the members and their scores visited walking the sorted set at key from cursor, along with the cursor to continue from
+func Zscan(key string, cursor uint64, opts ScanOptions) (uint64, []ZMember, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return 0, []ZMember{}, err
+	}
+	res := []ZMember{}
+	cursor = scanDict(z.scores, cursor, opts.Count, func(member string, score float64) {
+		if opts.matches(member) {
+			res = append(res, ZMember{Member: member, Score: score})
+		}
+	})
+	return cursor, res, nil
+}
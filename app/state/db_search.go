@@ -0,0 +1,689 @@
+package state
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Types of the fields of a search index
+const (
+	SearchFieldText    = "TEXT"
+	SearchFieldTag     = "TAG"
+	SearchFieldNumeric = "NUMERIC"
+)
+
+var (
+	ErrorSearchIndexExists = errors.New("Index already exists")
+	ErrorSearchNoIndex     = errors.New("Unknown Index name")
+)
+
+func errorSearchNoProperty(name string) error {
+	return fmt.Errorf("Property `%s` not loaded nor in schema", name)
+}
+
+// searchStopwords are the terms left out of the inverted index and ignored in queries
+var searchStopwords = map[string]bool{
+	"a": true, "is": true, "the": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "but": true,
+	"by": true, "for": true, "if": true, "in": true, "into": true, "it": true, "no": true, "not": true, "of": true, "on": true,
+	"or": true, "such": true, "that": true, "their": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true,
+}
+
+// SearchField is a field of the schema of an index. Identifier names the field in the indexed hashes, and Attribute names it in queries.
+type SearchField struct {
+	Identifier string
+	Attribute  string
+	Type       string
+	// Weight scales the scores of matches in a TEXT field
+	Weight float64
+	// Separator splits the value of a TAG field into tags, which are compared case-insensitively unless CaseSensitive is set
+	Separator     byte
+	CaseSensitive bool
+	Sortable      bool
+}
+
+// searchIndex indexes the hashes whose keys start with any of its prefixes. It keeps an inverted index of the terms of TEXT fields,
+// the keys holding each tag of TAG fields, and the values of NUMERIC fields in order.
+//
+// Indexes are kept up to date by the writes to hashes, which call unsafeReindexKey, rather than being replicated themselves,
+// so that replicas maintain the same indexes from the replicated writes.
+type searchIndex struct {
+	name     string
+	prefixes []string
+	fields   []SearchField
+	docs     map[string]*searchDoc
+	maxDocID int64
+	// failures counts the hashes that could not be indexed, because of a NUMERIC field that is not a number
+	failures int64
+	// terms maps each term to the keys of the documents holding it, with its frequency in each TEXT field by attribute
+	terms map[string]map[string]map[string]int
+	// tags maps the attribute of each TAG field to the keys of the documents holding each tag
+	tags map[string]map[string]map[string]struct{}
+	// numbers holds the values of each NUMERIC field by attribute, sorted by value and key
+	numbers map[string][]searchNumber
+}
+
+// searchDoc is an indexed hash, with the values of its indexed fields by attribute. Documents are given increasing ids as they are indexed,
+// which order the results of queries with the same score.
+type searchDoc struct {
+	id int64
+	// hash is the value indexed, which a later write of another type to the key may have replaced
+	hash   *DbHash
+	values map[string]string
+}
+
+type searchNumber struct {
+	value float64
+	key   string
+}
+
+func cmpSearchNumber(a, b searchNumber) int {
+	return cmp.Or(cmp.Compare(a.value, b.value), cmp.Compare(a.key, b.key))
+}
+
+func newSearchIndex(name string, prefixes []string, fields []SearchField) *searchIndex {
+	ix := &searchIndex{
+		name:     name,
+		prefixes: prefixes,
+		fields:   fields,
+		docs:     map[string]*searchDoc{},
+		terms:    map[string]map[string]map[string]int{},
+		tags:     map[string]map[string]map[string]struct{}{},
+		numbers:  map[string][]searchNumber{},
+	}
+	for _, f := range fields {
+		if f.Type == SearchFieldTag {
+			ix.tags[f.Attribute] = map[string]map[string]struct{}{}
+		}
+	}
+	return ix
+}
+
+func (ix *searchIndex) matches(key string) bool {
+	for _, prefix := range ix.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// field returns the field with the given attribute, or nil if there is none
+func (ix *searchIndex) field(attribute string) *SearchField {
+	for i := range ix.fields {
+		if ix.fields[i].Attribute == attribute {
+			return &ix.fields[i]
+		}
+	}
+	return nil
+}
+
+// searchTokenize returns the frequency of each term of a TEXT value, which is split at anything other than letters, digits and underscores,
+// and lowercased. Stopwords are left out, and terms are not stemmed.
+func searchTokenize(s string) map[string]int {
+	res := map[string]int{}
+	for _, term := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		if !searchStopwords[term] {
+			res[term]++
+		}
+	}
+	return res
+}
+
+// tags returns the distinct tags of a value of the TAG field f, with surrounding spaces trimmed
+func (f *SearchField) tags(s string) []string {
+	var res []string
+	for _, tag := range strings.Split(s, string(f.Separator)) {
+		tag = f.normalizeTag(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(res, tag) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+func (f *SearchField) normalizeTag(tag string) string {
+	if f.CaseSensitive {
+		return tag
+	}
+	return strings.ToLower(tag)
+}
+
+// add indexes the hash at key, unless a NUMERIC field is not a number
+func (ix *searchIndex) add(key string, h *DbHash) {
+	values := map[string]string{}
+	for _, f := range ix.fields {
+		value, ok := h.Get(f.Identifier)
+		if !ok {
+			continue
+		}
+		if f.Type == SearchFieldNumeric {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				ix.failures++
+				return
+			}
+		}
+		values[f.Attribute] = value
+	}
+	if len(values) == 0 {
+		return
+	}
+	ix.maxDocID++
+	ix.docs[key] = &searchDoc{id: ix.maxDocID, hash: h, values: values}
+	for _, f := range ix.fields {
+		value, ok := values[f.Attribute]
+		if !ok {
+			continue
+		}
+		switch f.Type {
+		case SearchFieldText:
+			for term, n := range searchTokenize(value) {
+				if ix.terms[term] == nil {
+					ix.terms[term] = map[string]map[string]int{}
+				}
+				if ix.terms[term][key] == nil {
+					ix.terms[term][key] = map[string]int{}
+				}
+				ix.terms[term][key][f.Attribute] = n
+			}
+		case SearchFieldTag:
+			for _, tag := range f.tags(value) {
+				if ix.tags[f.Attribute][tag] == nil {
+					ix.tags[f.Attribute][tag] = map[string]struct{}{}
+				}
+				ix.tags[f.Attribute][tag][key] = struct{}{}
+			}
+		case SearchFieldNumeric:
+			x, _ := strconv.ParseFloat(value, 64)
+			n := searchNumber{value: x, key: key}
+			i, _ := slices.BinarySearchFunc(ix.numbers[f.Attribute], n, cmpSearchNumber)
+			ix.numbers[f.Attribute] = slices.Insert(ix.numbers[f.Attribute], i, n)
+		}
+	}
+}
+
+// remove drops the document of key from the index, if any
+func (ix *searchIndex) remove(key string) {
+	doc, ok := ix.docs[key]
+	if !ok {
+		return
+	}
+	delete(ix.docs, key)
+	for _, f := range ix.fields {
+		value, ok := doc.values[f.Attribute]
+		if !ok {
+			continue
+		}
+		switch f.Type {
+		case SearchFieldText:
+			for term := range searchTokenize(value) {
+				delete(ix.terms[term], key)
+				if len(ix.terms[term]) == 0 {
+					delete(ix.terms, term)
+				}
+			}
+		case SearchFieldTag:
+			for _, tag := range f.tags(value) {
+				delete(ix.tags[f.Attribute][tag], key)
+				if len(ix.tags[f.Attribute][tag]) == 0 {
+					delete(ix.tags[f.Attribute], tag)
+				}
+			}
+		case SearchFieldNumeric:
+			x, _ := strconv.ParseFloat(value, 64)
+			if i, ok := slices.BinarySearchFunc(ix.numbers[f.Attribute], searchNumber{value: x, key: key}, cmpSearchNumber); ok {
+				ix.numbers[f.Attribute] = slices.Delete(ix.numbers[f.Attribute], i, i+1)
+			}
+		}
+	}
+}
+
+// unsafeReindexKey updates the document of key in every index whose prefixes it matches, after the hash at key was written or deleted.
+// The caller must hold DbMu.
+func unsafeReindexKey(key string) {
+	for _, ix := range state.Indexes {
+		if !ix.matches(key) {
+			continue
+		}
+		ix.remove(key)
+		if h, ok := state.Db.Value(key).(*DbHash); ok {
+			ix.add(key, h)
+		}
+	}
+}
+
+// unsafeGetSearchIndex returns the index with the given name, or ErrorSearchNoIndex if there is none. The caller must hold DbMu.
+func unsafeGetSearchIndex(name string) (*searchIndex, error) {
+	ix, ok := state.Indexes[name]
+	if !ok {
+		return nil, ErrorSearchNoIndex
+	}
+	return ix, nil
+}
+
+// searchMatch is a document matching a query
+type searchMatch struct {
+	key   string
+	score float64
+	doc   *searchDoc
+}
+
+// unsafeMatch returns the documents matching query by decreasing score, and in the order they were indexed for equal scores.
+// Documents whose hash has since been replaced by another value, or has logically expired, are left out. The caller must hold DbMu.
+func (ix *searchIndex) unsafeMatch(query string) ([]searchMatch, error) {
+	node, err := parseSearchQuery(ix, query)
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	var res []searchMatch
+	for key, score := range node.eval(ix) {
+		doc := ix.docs[key]
+		if v, ok := unsafeLookup(key, now); !ok || v != doc.hash {
+			continue
+		}
+		res = append(res, searchMatch{key: key, score: score, doc: doc})
+	}
+	slices.SortFunc(res, func(a, b searchMatch) int {
+		return cmp.Or(cmp.Compare(b.score, a.score), cmp.Compare(a.doc.id, b.doc.id))
+	})
+	return res, nil
+}
+
+// cmpSearchValues compares two values of a property numerically if both are numbers, and as lowercased strings otherwise,
+// in descending order if desc is set. Missing values, which are nil, come last either way.
+func cmpSearchValues(a, b *string, desc bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	c := cmp.Compare(strings.ToLower(*a), strings.ToLower(*b))
+	x, errX := strconv.ParseFloat(*a, 64)
+	y, errY := strconv.ParseFloat(*b, 64)
+	if errX == nil && errY == nil {
+		c = cmp.Compare(x, y)
+	}
+	if desc {
+		return -c
+	}
+	return c
+}
+
+// Search operations
+
+// FtCreate creates an index of the hashes whose keys start with any of prefixes, or of every hash if there are none,
+// and indexes the existing hashes in order of key, as with FT.CREATE
+func FtCreate(name string, prefixes []string, fields []SearchField) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := state.Indexes[name]; ok {
+		return ErrorSearchIndexExists
+	}
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	ix := newSearchIndex(name, prefixes, fields)
+	if state.Indexes == nil {
+		state.Indexes = map[string]*searchIndex{}
+	}
+	state.Indexes[name] = ix
+	var keys []string
+	state.Db.Each(func(key string, v DbValue) bool {
+		if _, ok := v.(*DbHash); ok && ix.matches(key) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	slices.Sort(keys)
+	for _, key := range keys {
+		ix.add(key, state.Db.Value(key).(*DbHash))
+	}
+	return nil
+}
+
+// FtDropindex drops an index, also deleting the hashes it indexes if deleteDocs is set, as with FT.DROPINDEX
+func FtDropindex(name string, deleteDocs bool) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	ix, err := unsafeGetSearchIndex(name)
+	if err != nil {
+		return err
+	}
+	delete(state.Indexes, name)
+	if deleteDocs {
+		for key, doc := range ix.docs {
+			if state.Db.Value(key) == doc.hash {
+				unsafeDelete(key, time.Now())
+			}
+		}
+	}
+	return nil
+}
+
+// FtList returns the names of the indexes in order, as with FT._LIST
+func FtList() []string {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	res := make([]string, 0, len(state.Indexes))
+	for name := range state.Indexes {
+		res = append(res, name)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// SearchInfo describes an index, as with FT.INFO
+type SearchInfo struct {
+	Prefixes  []string
+	Fields    []SearchField
+	NumDocs   int64
+	MaxDocID  int64
+	NumTerms  int64
+	NumFailed int64
+}
+
+func FtInfo(name string) (SearchInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	ix, err := unsafeGetSearchIndex(name)
+	if err != nil {
+		return SearchInfo{}, err
+	}
+	return SearchInfo{
+		Prefixes:  ix.prefixes,
+		Fields:    ix.fields,
+		NumDocs:   int64(len(ix.docs)),
+		MaxDocID:  ix.maxDocID,
+		NumTerms:  int64(len(ix.terms)),
+		NumFailed: ix.failures,
+	}, nil
+}
+
+// SearchOptions selects the results of FT.SEARCH. Results are sorted by the property SortBy if set, and Num results are returned from Offset.
+// Return names the fields returned, or is nil to return every field of the hashes.
+type SearchOptions struct {
+	NoContent bool
+	Return    []string
+	SortBy    string
+	SortDesc  bool
+	Offset    int64
+	Num       int64
+}
+
+// SearchResult is a document returned by FT.SEARCH, with its fields and values alternately
+type SearchResult struct {
+	Key    string
+	Score  float64
+	Fields []string
+}
+
+// FtSearch returns the number of documents of the index matching query, and the ones selected by opts, as with FT.SEARCH
+func FtSearch(name, query string, opts SearchOptions) (int64, []SearchResult, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	ix, err := unsafeGetSearchIndex(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	if opts.SortBy != "" && ix.field(opts.SortBy) == nil {
+		return 0, nil, errorSearchNoProperty(opts.SortBy)
+	}
+	matches, err := ix.unsafeMatch(query)
+	if err != nil {
+		return 0, nil, err
+	}
+	if opts.SortBy != "" {
+		slices.SortStableFunc(matches, func(a, b searchMatch) int {
+			return cmpSearchValues(searchDocValue(a.doc, opts.SortBy), searchDocValue(b.doc, opts.SortBy), opts.SortDesc)
+		})
+	}
+	total := int64(len(matches))
+	from := min(opts.Offset, total)
+	matches = matches[from:min(from+opts.Num, total)]
+	res := make([]SearchResult, len(matches))
+	for i, m := range matches {
+		res[i] = SearchResult{Key: m.key, Score: m.score}
+		switch {
+		case opts.NoContent:
+		case opts.Return != nil:
+			res[i].Fields = []string{}
+			for _, name := range opts.Return {
+				identifier := name
+				if f := ix.field(name); f != nil {
+					identifier = f.Identifier
+				}
+				if value, ok := m.doc.hash.Get(identifier); ok {
+					res[i].Fields = append(res[i].Fields, name, value)
+				}
+			}
+		default:
+			res[i].Fields = m.doc.hash.Entries()
+		}
+	}
+	return total, res, nil
+}
+
+func searchDocValue(doc *searchDoc, attribute string) *string {
+	if value, ok := doc.values[attribute]; ok {
+		return &value
+	}
+	return nil
+}
+
+// Kinds of the steps of the pipeline of FT.AGGREGATE
+const (
+	SearchStepGroupBy = iota
+	SearchStepSortBy
+	SearchStepLimit
+)
+
+// SearchStep is a step of the pipeline of FT.AGGREGATE, which groups rows by the properties GroupBy, reducing each group with Reducers,
+// sorts them by SortBy keeping up to Max rows unless it is 0, or keeps Num rows from Offset, depending on its kind
+type SearchStep struct {
+	Kind     int
+	GroupBy  []string
+	Reducers []SearchReducer
+	SortBy   []SearchSortKey
+	Max      int64
+	Offset   int64
+	Num      int64
+}
+
+// SearchReducer reduces the values of Property within a group with Func, which is one of COUNT, COUNT_DISTINCT, SUM, MIN, MAX, AVG and TOLIST,
+// into the property As
+type SearchReducer struct {
+	Func     string
+	Property string
+	As       string
+}
+
+type SearchSortKey struct {
+	Property string
+	Desc     bool
+}
+
+// SearchValue is the value of a property of a row of FT.AGGREGATE, which is a list for TOLIST
+type SearchValue struct {
+	Str    string
+	List   []string
+	IsList bool
+}
+
+// SearchRow is a row of the results of FT.AGGREGATE, with its properties in order
+type SearchRow struct {
+	Properties []string
+	Values     map[string]SearchValue
+}
+
+func (r SearchRow) str(property string) *string {
+	if v, ok := r.Values[property]; ok && !v.IsList {
+		return &v.Str
+	}
+	return nil
+}
+
+// FtAggregate runs the documents of the index matching query through steps, as with FT.AGGREGATE. Each document starts as a row with
+// the fields of load, or every field of its hash if load is ["*"], although steps may refer to any field of the hash.
+// It returns the number of rows before the last LIMIT, and the resulting rows.
+func FtAggregate(name, query string, load []string, steps []SearchStep) (int64, []SearchRow, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	ix, err := unsafeGetSearchIndex(name)
+	if err != nil {
+		return 0, nil, err
+	}
+	matches, err := ix.unsafeMatch(query)
+	if err != nil {
+		return 0, nil, err
+	}
+	rows := make([]SearchRow, len(matches))
+	for i, m := range matches {
+		entries := m.doc.hash.Entries()
+		rows[i] = SearchRow{Properties: []string{}, Values: map[string]SearchValue{}}
+		for j := 0; j < len(entries); j += 2 {
+			rows[i].Values[entries[j]] = SearchValue{Str: entries[j+1]}
+		}
+		for _, f := range ix.fields {
+			if value, ok := m.doc.hash.Get(f.Identifier); ok {
+				rows[i].Values[f.Attribute] = SearchValue{Str: value}
+			}
+		}
+		if len(load) == 1 && load[0] == "*" {
+			for j := 0; j < len(entries); j += 2 {
+				rows[i].Properties = append(rows[i].Properties, entries[j])
+			}
+			continue
+		}
+		for _, property := range load {
+			if _, ok := rows[i].Values[property]; ok {
+				rows[i].Properties = append(rows[i].Properties, property)
+			}
+		}
+	}
+	total := int64(len(rows))
+	for _, step := range steps {
+		switch step.Kind {
+		case SearchStepGroupBy:
+			rows = groupSearchRows(rows, step)
+		case SearchStepSortBy:
+			slices.SortStableFunc(rows, func(a, b SearchRow) int {
+				for _, k := range step.SortBy {
+					if c := cmpSearchValues(a.str(k.Property), b.str(k.Property), k.Desc); c != 0 {
+						return c
+					}
+				}
+				return 0
+			})
+			if step.Max > 0 && int64(len(rows)) > step.Max {
+				rows = rows[:step.Max]
+			}
+		case SearchStepLimit:
+			total = int64(len(rows))
+			from := min(step.Offset, total)
+			rows = rows[from:min(from+step.Num, total)]
+		}
+	}
+	if len(steps) == 0 || steps[len(steps)-1].Kind != SearchStepLimit {
+		total = int64(len(rows))
+	}
+	return total, rows, nil
+}
+
+// groupSearchRows groups rows by the properties of step, in order of the first row of each group, and reduces each group into a row
+func groupSearchRows(rows []SearchRow, step SearchStep) []SearchRow {
+	var keys []string
+	groups := map[string][]SearchRow{}
+	for _, r := range rows {
+		parts := make([]string, len(step.GroupBy))
+		for i, property := range step.GroupBy {
+			if s := r.str(property); s != nil {
+				parts[i] = *s
+			}
+		}
+		key := strings.Join(parts, "\x00")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+	res := make([]SearchRow, len(keys))
+	for i, key := range keys {
+		group := groups[key]
+		row := SearchRow{Properties: slices.Clone(step.GroupBy), Values: map[string]SearchValue{}}
+		for _, property := range step.GroupBy {
+			if s := group[0].str(property); s != nil {
+				row.Values[property] = SearchValue{Str: *s}
+			}
+		}
+		for _, r := range step.Reducers {
+			row.Properties = append(row.Properties, r.As)
+			row.Values[r.As] = reduceSearchRows(group, r)
+		}
+		res[i] = row
+	}
+	return res
+}
+
+func reduceSearchRows(group []SearchRow, r SearchReducer) SearchValue {
+	if r.Func == "COUNT" {
+		return SearchValue{Str: strconv.Itoa(len(group))}
+	}
+	var values []string
+	var numbers []float64
+	for _, row := range group {
+		s := row.str(r.Property)
+		if s == nil {
+			continue
+		}
+		values = append(values, *s)
+		if x, err := strconv.ParseFloat(*s, 64); err == nil {
+			numbers = append(numbers, x)
+		}
+	}
+	switch r.Func {
+	case "COUNT_DISTINCT":
+		slices.Sort(values)
+		return SearchValue{Str: strconv.Itoa(len(slices.Compact(values)))}
+	case "TOLIST":
+		distinct := []string{}
+		for _, s := range values {
+			if !slices.Contains(distinct, s) {
+				distinct = append(distinct, s)
+			}
+		}
+		return SearchValue{List: distinct, IsList: true}
+	case "SUM":
+		return SearchValue{Str: formatFloat(tsSum(numbers))}
+	case "AVG":
+		if len(numbers) == 0 {
+			return SearchValue{Str: "nan"}
+		}
+		return SearchValue{Str: formatFloat(tsSum(numbers) / float64(len(numbers)))}
+	case "MIN":
+		if len(numbers) == 0 {
+			return SearchValue{Str: "inf"}
+		}
+		return SearchValue{Str: formatFloat(slices.Min(numbers))}
+	case "MAX":
+		if len(numbers) == 0 {
+			return SearchValue{Str: "-inf"}
+		}
+		return SearchValue{Str: formatFloat(slices.Max(numbers))}
+	}
+	return SearchValue{}
+}
@@ -0,0 +1,414 @@
+package state
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// searchNode is a node of a parsed query, which evaluates to the keys of the matching documents with their scores
+type searchNode interface {
+	eval(ix *searchIndex) map[string]float64
+}
+
+// searchAll matches every document, as with *
+type searchAll struct{}
+
+// searchTerm matches the documents holding term, or a term starting with it if prefix is set, in any TEXT field,
+// or only in the field with the given attribute if set. Matches are scored by TF-IDF, scaled by the weight of the field.
+type searchTerm struct {
+	term      string
+	prefix    bool
+	attribute string
+}
+
+// searchTags matches the documents holding any of tags in the TAG field with the given attribute, as with @field:{a | b}
+type searchTags struct {
+	attribute string
+	tags      []string
+}
+
+// searchRange matches the documents whose NUMERIC field with the given attribute is within a range, as with @field:[min max]
+type searchRange struct {
+	attribute        string
+	min, max         float64
+	minExcl, maxExcl bool
+}
+
+// searchAnd matches the documents matching all of its nodes, summing their scores
+type searchAnd []searchNode
+
+// searchOr matches the documents matching any of its nodes, summing their scores
+type searchOr []searchNode
+
+// searchNot matches the documents not matching its node
+type searchNot struct {
+	node searchNode
+}
+
+func (n searchAll) eval(ix *searchIndex) map[string]float64 {
+	res := make(map[string]float64, len(ix.docs))
+	for key := range ix.docs {
+		res[key] = 0
+	}
+	return res
+}
+
+func (n searchTerm) eval(ix *searchIndex) map[string]float64 {
+	res := map[string]float64{}
+	for term, docs := range ix.terms {
+		if term != n.term && !(n.prefix && strings.HasPrefix(term, n.term)) {
+			continue
+		}
+		idf := math.Log1p(float64(len(ix.docs)) / float64(len(docs)))
+		for key, freqs := range docs {
+			for attribute, freq := range freqs {
+				if n.attribute == "" || attribute == n.attribute {
+					res[key] += float64(freq) * idf * ix.field(attribute).Weight
+				}
+			}
+		}
+	}
+	return res
+}
+
+func (n searchTags) eval(ix *searchIndex) map[string]float64 {
+	res := map[string]float64{}
+	for _, tag := range n.tags {
+		for key := range ix.tags[n.attribute][tag] {
+			res[key] = 0
+		}
+	}
+	return res
+}
+
+func (n searchRange) eval(ix *searchIndex) map[string]float64 {
+	res := map[string]float64{}
+	numbers := ix.numbers[n.attribute]
+	i, _ := slices.BinarySearchFunc(numbers, n.min, func(x searchNumber, min float64) int {
+		if x.value < min || n.minExcl && x.value == min {
+			return -1
+		}
+		return 1
+	})
+	for ; i < len(numbers) && (numbers[i].value < n.max || !n.maxExcl && numbers[i].value == n.max); i++ {
+		res[numbers[i].key] = 0
+	}
+	return res
+}
+
+func (n searchAnd) eval(ix *searchIndex) map[string]float64 {
+	res := n[0].eval(ix)
+	for _, node := range n[1:] {
+		other := node.eval(ix)
+		for key, score := range res {
+			if s, ok := other[key]; ok {
+				res[key] = score + s
+			} else {
+				delete(res, key)
+			}
+		}
+	}
+	return res
+}
+
+func (n searchOr) eval(ix *searchIndex) map[string]float64 {
+	res := map[string]float64{}
+	for _, node := range n {
+		for key, score := range node.eval(ix) {
+			res[key] += score
+		}
+	}
+	return res
+}
+
+func (n searchNot) eval(ix *searchIndex) map[string]float64 {
+	excluded := n.node.eval(ix)
+	res := map[string]float64{}
+	for key := range ix.docs {
+		if _, ok := excluded[key]; !ok {
+			res[key] = 0
+		}
+	}
+	return res
+}
+
+// searchParser parses the query language of FT.SEARCH and FT.AGGREGATE:
+//
+//	union     = intersect { "|" intersect }
+//	intersect = unary { unary }
+//	unary     = "-" unary | "(" union ")" | "@" attribute ":" field | "*" | term
+//	field     = "{" tag { "|" tag } "}" | "[" bound bound "]" | unary
+//
+// Terms are made of letters, digits and underscores, or characters escaped with a backslash, and match a prefix if followed by *.
+// Bounds may be excluded with a leading (, and be -inf or +inf. Other characters separate terms.
+type searchParser struct {
+	ix  *searchIndex
+	s   string
+	pos int
+}
+
+// parseSearchQuery parses a query, which may be nil if it only holds stopwords and so matches nothing
+func parseSearchQuery(ix *searchIndex, s string) (searchNode, error) {
+	p := &searchParser{ix: ix, s: s}
+	node, err := p.union("")
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return nil, p.errorf()
+	}
+	return node, nil
+}
+
+func (p *searchParser) errorf() error {
+	near := p.s[min(p.pos, len(p.s)):]
+	if i := strings.IndexAny(near, " \t"); i > 0 {
+		near = near[:i]
+	}
+	return fmt.Errorf("Syntax error at offset %d near %s", p.pos, near)
+}
+
+func isSearchTermRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// skipSpace skips spaces and other characters that separate terms
+func (p *searchParser) skipSpace() {
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if isSearchTermRune(r) || strings.ContainsRune(`()|{}[]@-*\`, r) {
+			return
+		}
+		p.pos += size
+	}
+}
+
+func (p *searchParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *searchParser) union(attribute string) (searchNode, error) {
+	var nodes searchOr
+	for {
+		node, err := p.intersect(attribute)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+		if p.skipSpace(); p.peek() != '|' {
+			break
+		}
+		p.pos++
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+// intersect parses at least one unary node, returning nil if all of them only hold stopwords
+func (p *searchParser) intersect(attribute string) (searchNode, error) {
+	var nodes searchAnd
+	parsed := false
+	for {
+		p.skipSpace()
+		if c := p.peek(); c == 0 || c == '|' || c == ')' {
+			break
+		}
+		node, err := p.unary(attribute)
+		if err != nil {
+			return nil, err
+		}
+		parsed = true
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	if !parsed {
+		return nil, p.errorf()
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, nil
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *searchParser) unary(attribute string) (searchNode, error) {
+	p.skipSpace()
+	switch p.peek() {
+	case '-':
+		p.pos++
+		node, err := p.unary(attribute)
+		if err != nil || node == nil {
+			return nil, err
+		}
+		return searchNot{node: node}, nil
+	case '(':
+		p.pos++
+		node, err := p.union(attribute)
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.peek() != ')' {
+			return nil, p.errorf()
+		}
+		p.pos++
+		return node, nil
+	case '@':
+		return p.field()
+	case '*':
+		p.pos++
+		return searchAll{}, nil
+	}
+	term, ok := p.term()
+	if !ok {
+		return nil, p.errorf()
+	}
+	prefix := p.peek() == '*'
+	if prefix {
+		p.pos++
+	}
+	term = strings.ToLower(term)
+	if !prefix && searchStopwords[term] {
+		return nil, nil
+	}
+	return searchTerm{term: term, prefix: prefix, attribute: attribute}, nil
+}
+
+// term reads a term, unescaping escaped characters
+func (p *searchParser) term() (string, bool) {
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		if r == '\\' && p.pos+1 < len(p.s) {
+			r, size = utf8.DecodeRuneInString(p.s[p.pos+1:])
+			size++
+		} else if !isSearchTermRune(r) {
+			break
+		}
+		b.WriteRune(r)
+		p.pos += size
+	}
+	return b.String(), b.Len() > 0
+}
+
+// field parses a condition on a field, starting at its @
+func (p *searchParser) field() (searchNode, error) {
+	start := p.pos
+	p.pos++
+	name, ok := p.term()
+	if !ok || p.peek() != ':' {
+		return nil, p.errorf()
+	}
+	p.pos++
+	f := p.ix.field(name)
+	if f == nil {
+		return nil, fmt.Errorf("Unknown field at offset %d near %s", start, name)
+	}
+	p.skipSpace()
+	switch p.peek() {
+	case '{':
+		if f.Type != SearchFieldTag {
+			return nil, p.errorf()
+		}
+		p.pos++
+		return p.tags(f)
+	case '[':
+		if f.Type != SearchFieldNumeric {
+			return nil, p.errorf()
+		}
+		p.pos++
+		return p.numericRange(f)
+	}
+	if f.Type != SearchFieldText {
+		return nil, p.errorf()
+	}
+	return p.unary(f.Attribute)
+}
+
+// tags parses the tags of a TAG field up to the closing }, which are separated by | and may contain escaped characters
+func (p *searchParser) tags(f *SearchField) (searchNode, error) {
+	node := searchTags{attribute: f.Attribute}
+	var b strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return nil, p.errorf()
+		}
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.s):
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+			continue
+		case c != '|' && c != '}':
+			b.WriteByte(c)
+			continue
+		}
+		if tag := f.normalizeTag(strings.TrimSpace(b.String())); tag != "" {
+			node.tags = append(node.tags, tag)
+		}
+		b.Reset()
+		if c == '}' {
+			break
+		}
+	}
+	if len(node.tags) == 0 {
+		return nil, p.errorf()
+	}
+	return node, nil
+}
+
+// numericRange parses the bounds of a NUMERIC field up to the closing ]
+func (p *searchParser) numericRange(f *SearchField) (searchNode, error) {
+	node := searchRange{attribute: f.Attribute}
+	bound := func() (float64, bool, error) {
+		for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+			p.pos++
+		}
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] != ' ' && p.s[p.pos] != ']' {
+			p.pos++
+		}
+		s := p.s[start:p.pos]
+		excl := strings.HasPrefix(s, "(")
+		s = strings.TrimPrefix(s, "(")
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(x) {
+			p.pos = start
+			return 0, false, p.errorf()
+		}
+		return x, excl, nil
+	}
+	var err error
+	if node.min, node.minExcl, err = bound(); err != nil {
+		return nil, err
+	}
+	if node.max, node.maxExcl, err = bound(); err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.peek() != ']' {
+		return nil, p.errorf()
+	}
+	p.pos++
+	return node, nil
+}
@@ -0,0 +1,459 @@
+package state
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// setMaxIntsetEntries is the number of members beyond which a DbSet is converted from its integer encoding to a hash set
+const setMaxIntsetEntries = 512
+
+// SetOp is an operation over multiple sets
+type SetOp int
+
+const (
+	SetOpInter SetOp = iota
+	SetOpUnion
+	SetOpDiff
+)
+
+// DbSet stores sets whose members are all integers compactly as a sorted slice of integers, which is binary searched.
+// It converts itself to a hash set once a non-integer member is added, or it grows past setMaxIntsetEntries members.
+// The hash set keeps its members in a dense slice alongside an index into it, so that random members can be picked and removed in O(1).
+type DbSet struct {
+	dbExpiry
+	// ints is nil once the set has been converted to a hash set
+	ints    []int64
+	members []string
+	index   *dict[int]
+}
+
+var _ DbValue = (*DbSet)(nil)
+
+func NewDbSet() *DbSet {
+	return &DbSet{ints: make([]int64, 0, 8)}
+}
+
+func (v *DbSet) Type() string {
+	return "set"
+}
+
+func (v *DbSet) isIntset() bool {
+	return v.index == nil
+}
+
+func (v *DbSet) Len() int {
+	if v.isIntset() {
+		return len(v.ints)
+	}
+	return len(v.members)
+}
+
+// parseSetInt parses member as an integer only if it is in canonical form, so that the member round-trips through the integer encoding
+func parseSetInt(member string) (int64, bool) {
+	i, err := strconv.ParseInt(member, 10, 64)
+	if err != nil || strconv.FormatInt(i, 10) != member {
+		return 0, false
+	}
+	return i, true
+}
+
+func (v *DbSet) Has(member string) bool {
+	if !v.isIntset() {
+		_, ok := v.index.Get(member)
+		return ok
+	}
+	i, ok := parseSetInt(member)
+	if !ok {
+		return false
+	}
+	_, found := slices.BinarySearch(v.ints, i)
+	return found
+}
+
+// Add adds member, returning true if it is new
+func (v *DbSet) Add(member string) bool {
+	if v.isIntset() {
+		i, ok := parseSetInt(member)
+		if ok {
+			j, found := slices.BinarySearch(v.ints, i)
+			if found {
+				return false
+			}
+			if len(v.ints) < setMaxIntsetEntries {
+				v.ints = slices.Insert(v.ints, j, i)
+				return true
+			}
+		}
+		v.convert()
+	}
+	if _, ok := v.index.Get(member); ok {
+		return false
+	}
+	v.index.Set(member, len(v.members))
+	v.members = append(v.members, member)
+	return true
+}
+
+func (v *DbSet) convert() {
+	v.members = make([]string, len(v.ints), len(v.ints)+1)
+	v.index = newDict[int](len(v.ints) + 1)
+	for j, i := range v.ints {
+		v.members[j] = strconv.FormatInt(i, 10)
+		v.index.Set(v.members[j], j)
+	}
+	v.ints = nil
+}
+
+// Remove removes member, returning true if it was present
+func (v *DbSet) Remove(member string) bool {
+	if v.isIntset() {
+		i, ok := parseSetInt(member)
+		if !ok {
+			return false
+		}
+		j, found := slices.BinarySearch(v.ints, i)
+		if found {
+			v.ints = slices.Delete(v.ints, j, j+1)
+		}
+		return found
+	}
+	j, ok := v.index.Get(member)
+	if !ok {
+		return false
+	}
+	v.removeAt(j)
+	return true
+}
+
+// removeAt removes the j-th member of a hash set by moving the last member into its place
+func (v *DbSet) removeAt(j int) {
+	last := len(v.members) - 1
+	v.index.Delete(v.members[j])
+	if j != last {
+		v.members[j] = v.members[last]
+		v.index.Set(v.members[j], j)
+	}
+	v.members[last] = ""
+	v.members = v.members[:last]
+}
+
+func (v *DbSet) at(j int) string {
+	if v.isIntset() {
+		return strconv.FormatInt(v.ints[j], 10)
+	}
+	return v.members[j]
+}
+
+func (v *DbSet) Members() []string {
+	if !v.isIntset() {
+		return slices.Clone(v.members)
+	}
+	members := make([]string, len(v.ints))
+	for j, i := range v.ints {
+		members[j] = strconv.FormatInt(i, 10)
+	}
+	return members
+}
+
+// Random returns up to count distinct random members, or exactly -count members that may repeat if count is negative
+func (v *DbSet) Random(count int64) []string {
+	n := int64(v.Len())
+	if n == 0 {
+		return []string{}
+	}
+	if count < 0 {
+		var res []string
+		for ; count < 0; count++ {
+			res = append(res, v.at(rand.Intn(int(n))))
+		}
+		return res
+	}
+	if count >= n {
+		return v.Members()
+	}
+	if count*4 > n {
+		// partial Fisher-Yates shuffle when a large proportion of the set is requested
+		members := v.Members()
+		for i := int64(0); i < count; i++ {
+			j := i + rand.Int63n(n-i)
+			members[i], members[j] = members[j], members[i]
+		}
+		return members[:count]
+	}
+	picked := make(map[int]bool, count)
+	res := make([]string, 0, count)
+	for int64(len(res)) < count {
+		j := rand.Intn(int(n))
+		if !picked[j] {
+			picked[j] = true
+			res = append(res, v.at(j))
+		}
+	}
+	return res
+}
+
+// Pop removes and returns up to count random members
+func (v *DbSet) Pop(count int64) []string {
+	res := make([]string, 0, min(count, int64(v.Len())))
+	for int64(len(res)) < count && v.Len() > 0 {
+		j := rand.Intn(v.Len())
+		res = append(res, v.at(j))
+		if v.isIntset() {
+			v.ints = slices.Delete(v.ints, j, j+1)
+		} else {
+			v.removeAt(j)
+		}
+	}
+	return res
+}
+
+// unsafeGetSet returns the set stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetSet(key string) (*DbSet, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	s, ok := v.(*DbSet)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return s, nil
+}
+
+// unsafeSetAlgebra computes op over the sets at keys, with missing keys taken to be empty sets. The caller must hold DbMu.
+func unsafeSetAlgebra(keys []string, op SetOp) (*DbSet, error) {
+	sets := make([]*DbSet, len(keys))
+	for i, key := range keys {
+		s, err := unsafeGetSet(key)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			s = NewDbSet()
+		}
+		sets[i] = s
+	}
+	res := NewDbSet()
+	switch op {
+	case SetOpInter:
+		// iterate over the smallest set, checking membership in the rest
+		others := slices.Clone(sets)
+		slices.SortFunc(others, func(a, b *DbSet) int { return a.Len() - b.Len() })
+		for _, m := range others[0].Members() {
+			if !slices.ContainsFunc(others[1:], func(s *DbSet) bool { return !s.Has(m) }) {
+				res.Add(m)
+			}
+		}
+	case SetOpUnion:
+		for _, s := range sets {
+			for _, m := range s.Members() {
+				res.Add(m)
+			}
+		}
+	case SetOpDiff:
+		for _, m := range sets[0].Members() {
+			if !slices.ContainsFunc(sets[1:], func(s *DbSet) bool { return s.Has(m) }) {
+				res.Add(m)
+			}
+		}
+	}
+	return res, nil
+}
+
+// Set operations
+
+func Sadd(key string, members []string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil {
+		return 0, err
+	}
+	if s == nil {
+		s = NewDbSet()
+		unsafeSet(key, s)
+	}
+	var added int64
+	for _, m := range members {
+		if s.Add(m) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+func Srem(key string, members []string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, m := range members {
+		if s.Remove(m) {
+			removed++
+		}
+	}
+	if s.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return removed, nil
+}
+
+func Smembers(key string) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return []string{}, nil
+	}
+	return s.Members(), nil
+}
+
+func Smismember(key string, members []string) ([]bool, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]bool, len(members))
+	for i, m := range members {
+		res[i] = s != nil && s.Has(m)
+	}
+	return res, nil
+}
+
+func Scard(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil || s == nil {
+		return 0, err
+	}
+	return int64(s.Len()), nil
+}
+
+// SetAlgebra computes op over the sets at keys, as with SINTER, SUNION and SDIFF
+func SetAlgebra(keys []string, op SetOp) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	res, err := unsafeSetAlgebra(keys, op)
+	if err != nil {
+		return nil, err
+	}
+	return res.Members(), nil
+}
+
+// SetAlgebraStore computes op over the sets at keys and stores the result at dst, as with SINTERSTORE, SUNIONSTORE and SDIFFSTORE.
+// It returns the size of the result.
+func SetAlgebraStore(dst string, keys []string, op SetOp) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	res, err := unsafeSetAlgebra(keys, op)
+	if err != nil {
+		return 0, err
+	}
+	if res.Len() == 0 {
+		unsafeDelete(dst, time.Now())
+	} else {
+		unsafeSet(dst, res)
+	}
+	return int64(res.Len()), nil
+}
+
+// Sintercard returns the size of the intersection of the sets at keys, counting no further than limit unless it is 0
+func Sintercard(keys []string, limit int64) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	sets := make([]*DbSet, len(keys))
+	for i, key := range keys {
+		s, err := unsafeGetSet(key)
+		if err != nil {
+			return 0, err
+		}
+		if s == nil {
+			s = NewDbSet()
+		}
+		sets[i] = s
+	}
+	slices.SortFunc(sets, func(a, b *DbSet) int { return a.Len() - b.Len() })
+	var n int64
+	for _, m := range sets[0].Members() {
+		if limit != 0 && n >= limit {
+			break
+		}
+		if !slices.ContainsFunc(sets[1:], func(s *DbSet) bool { return !s.Has(m) }) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Srandmember returns ErrorNone if there is no such set.
+func Srandmember(key string, count int64) ([]string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, ErrorNone
+	}
+	return s.Random(count), nil
+}
+
+// Spop returns ErrorNone if there is no such set.
+func Spop(key string, count int64) ([]string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	s, err := unsafeGetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, ErrorNone
+	}
+	res := s.Pop(count)
+	if s.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return res, nil
+}
+
+func Smove(src, dst, member string) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	ss, err := unsafeGetSet(src)
+	if err != nil {
+		return false, err
+	}
+	ds, err := unsafeGetSet(dst)
+	if err != nil {
+		return false, err
+	}
+	if ss == nil || !ss.Has(member) {
+		return false, nil
+	}
+	if src == dst {
+		return true, nil
+	}
+	ss.Remove(member)
+	if ss.Len() == 0 {
+		unsafeDelete(src, time.Now())
+	}
+	if ds == nil {
+		ds = NewDbSet()
+		unsafeSet(dst, ds)
+	}
+	ds.Add(member)
+	return true, nil
+}
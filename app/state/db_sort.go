@@ -0,0 +1,186 @@
+package state
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+var ErrorSortNotFloat = errors.New("ERR One or more scores can't be converted into double")
+
+// SortOptions are the options of SORT. By is the pattern of the keys holding the weights to sort by, which sorts by the elements
+// themselves if empty, and leaves the elements in their stored order if it has no *. Offset and Count (unlimited if negative) limit
+// the elements returned. Get holds the patterns of the keys holding the values to return for each element in its stead.
+// Alpha sorts lexicographically rather than numerically.
+type SortOptions struct {
+	By     string
+	Offset int64
+	Count  int64
+	Get    []string
+	Desc   bool
+	Alpha  bool
+}
+
+// unsafeSortLookup substitutes elem for the first * of pattern, and returns the string at the resulting key, or the value of the field
+// of the hash at that key if the pattern ends in ->field. The pattern # stands for elem itself. It returns nil if there is no such value.
+// The caller must hold DbMu.
+func unsafeSortLookup(pattern, elem string, now time.Time) *string {
+	if pattern == "#" {
+		return &elem
+	}
+	star := strings.IndexByte(pattern, '*')
+	if star < 0 {
+		return nil
+	}
+	key, field := pattern[:star]+elem+pattern[star+1:], ""
+	if arrow := strings.Index(pattern[star+1:], "->"); arrow >= 0 && star+arrow+3 < len(pattern) {
+		key, field = pattern[:star]+elem+pattern[star+1:star+1+arrow], pattern[star+arrow+3:]
+	}
+	v, ok := unsafeLookup(key, now)
+	if !ok {
+		return nil
+	}
+	switch w := v.(type) {
+	case *DbString:
+		if field == "" {
+			return &w.string
+		}
+	case *DbHash:
+		if value, ok := w.Get(field); ok && field != "" {
+			return &value
+		}
+	}
+	return nil
+}
+
+// unsafeSort returns the values selected by opts of the sorted list, set or sorted set at key, which are nil where a GET pattern
+// finds no value. The caller must hold DbMu.
+func unsafeSort(key string, opts SortOptions) ([]*string, error) {
+	now := time.Now()
+	v, ok := unsafeLookup(key, now)
+	var elems []string
+	if ok {
+		switch w := v.(type) {
+		case *DbList:
+			elems = w.Range(0, -1)
+		case *DbSet:
+			elems = w.Members()
+		case *DbZSet:
+			for _, m := range w.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: -1}) {
+				elems = append(elems, m.Member)
+			}
+		default:
+			return nil, ErrorWrongType
+		}
+	}
+	if opts.By == "" || strings.IndexByte(opts.By, '*') >= 0 {
+		type weighted struct {
+			elem   string
+			weight *string
+			score  float64
+		}
+		items := make([]weighted, len(elems))
+		for i, elem := range elems {
+			items[i] = weighted{elem: elem, weight: &elems[i]}
+			if opts.By != "" {
+				items[i].weight = unsafeSortLookup(opts.By, elem, now)
+			}
+			if opts.Alpha || items[i].weight == nil {
+				continue
+			}
+			score, err := strconv.ParseFloat(strings.TrimSpace(*items[i].weight), 64)
+			if err != nil {
+				return nil, ErrorSortNotFloat
+			}
+			items[i].score = score
+		}
+		// elements of equal weight are ordered by themselves, so that the result is deterministic
+		slices.SortFunc(items, func(a, b weighted) int {
+			c := cmp.Compare(a.score, b.score)
+			if opts.Alpha {
+				switch {
+				case a.weight == nil || b.weight == nil:
+					c = cmpBool(a.weight != nil, b.weight != nil)
+				default:
+					c = strings.Compare(*a.weight, *b.weight)
+				}
+			}
+			if c == 0 {
+				c = strings.Compare(a.elem, b.elem)
+			}
+			if opts.Desc {
+				return -c
+			}
+			return c
+		})
+		for i, item := range items {
+			elems[i] = item.elem
+		}
+	} else if opts.Desc {
+		slices.Reverse(elems)
+	}
+	start := min(max(opts.Offset, 0), int64(len(elems)))
+	end := int64(len(elems))
+	if opts.Count >= 0 {
+		end = min(start+opts.Count, end)
+	}
+	elems = elems[start:end]
+	var res []*string
+	for i, elem := range elems {
+		if len(opts.Get) == 0 {
+			res = append(res, &elems[i])
+		}
+		for _, pattern := range opts.Get {
+			res = append(res, unsafeSortLookup(pattern, elem, now))
+		}
+	}
+	return res, nil
+}
+
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// Sort returns the values selected by opts of the sorted list, set or sorted set at key, which are nil where a GET pattern finds no value
+func Sort(key string, opts SortOptions) ([]*string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	return unsafeSort(key, opts)
+}
+
+// SortStore stores the values selected by opts of the sorted list, set or sorted set at key as a list at dst, replacing any existing value,
+// with values that a GET pattern finds no value for stored as empty strings. It returns the values stored, and the commands effecting
+// the pops of any clients blocked on dst that were served as a result.
+func SortStore(key, dst string, opts SortOptions) ([]string, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	values, err := unsafeSort(key, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := make([]string, len(values))
+	l := &DbList{}
+	for i, v := range values {
+		if v != nil {
+			res[i] = *v
+		}
+		l.PushBack(res[i])
+	}
+	if len(res) == 0 {
+		unsafeDelete(dst, time.Now())
+		return res, nil, nil
+	}
+	unsafeSet(dst, l)
+	return res, unsafeServeBlockers(dst), nil
+}
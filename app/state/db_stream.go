@@ -93,6 +93,7 @@ func (e DbStreamEntry) Id() string {
 }
 
 type DbStream struct {
+	dbExpiry
 	data []DbStreamEntry
 }
 
@@ -179,12 +180,12 @@ func Xadd(key string, id string, fields []string) (string, error) {
 		return "", err
 	}
 	LockDbMu()
-	v, ok := state.Db[key]
+	v, ok := unsafeLookup(key, time.Now())
 	if !ok {
 		v = &DbStream{data: []DbStreamEntry{
 			{ms: 0, seq: 0, fields: nil},
 		}}
-		state.Db[key] = v
+		unsafeSet(key, v)
 	}
 	stream, ok := v.(*DbStream)
 	if !ok {
@@ -197,26 +198,8 @@ func Xadd(key string, id string, fields []string) (string, error) {
 		return "", err
 	}
 	stream.data = append(stream.data, e)
-	ss := stream.EncodeSlice(len(stream.data)-1, len(stream.data))
-	streamBlockListenersMu.Lock()
-	skv := make([]resp.RESP, 2)
-	skv[0] = &resp.RESPBulkString{Value: key}
-	skv[1] = ss
-	skvr := &resp.RESPArray{Value: skv}
-	sssa := &resp.RESPArray{Value: []resp.RESP{skvr}}
+	unsafeServeStreamReaders(key)
 	UnlockDbMu()
-	listeners, ok := streamBlockListeners[key]
-	if ok {
-		for listener := range listeners {
-			listener.l.Lock()
-			if listener.res == nullListenerRes {
-				listener.res = sssa
-			}
-			listener.cond.Broadcast()
-			listener.l.Unlock()
-		}
-	}
-	streamBlockListenersMu.Unlock()
 	return e.Id(), nil
 }
 
@@ -238,7 +221,7 @@ func Xrange(key, start, end string) (resp.RESP, error) {
 	}
 	RLockDbMu()
 	defer RUnlockDbMu()
-	v, ok := state.Db[key]
+	v, ok := unsafeLookup(key, time.Now())
 	if !ok {
 		return nil, ErrorNone
 	}
@@ -278,7 +261,7 @@ func Xread(kids []string, blockTimeout time.Duration) (resp.RESP, error) {
 	sss := make([]resp.RESP, 0, len(keys))
 	RLockDbMu()
 	for i, key := range keys {
-		v, ok := state.Db[key]
+		v, ok := unsafeLookup(key, time.Now())
 		if !ok {
 			return nil, ErrorNone
 		}
@@ -307,8 +290,16 @@ func Xread(kids []string, blockTimeout time.Duration) (resp.RESP, error) {
 		RUnlockDbMu()
 		return sssa, nil
 	}
-	// block
+	// block, reading each key from the entry following the last one at the time of blocking if its ID is $
 	listener := newStreamBlockListener(keys)
+	for i, key := range keys {
+		listener.from[key] = [2]int64{mss[i], seqs[i]}
+		if mss[i] == -1 {
+			stream := state.Db.Value(key).(*DbStream)
+			last := stream.data[len(stream.data)-1]
+			listener.from[key] = [2]int64{last.ms, last.seq + 1}
+		}
+	}
 	listener.l.Lock()
 	streamBlockListenersMu.Lock()
 	for _, key := range keys {
@@ -335,6 +326,8 @@ type streamBlockListener struct {
 	l    *sync.Mutex
 	cond *sync.Cond
 	keys []string
+	// from holds the ID of the first entry to read from each key, with the sequence number incremented as with parseStreamEntryXreadId
+	from map[string][2]int64
 	res  resp.RESP
 }
 
@@ -344,6 +337,7 @@ func newStreamBlockListener(keys []string) *streamBlockListener {
 		l:    l,
 		cond: sync.NewCond(l),
 		keys: keys,
+		from: make(map[string][2]int64, len(keys)),
 		res:  nullListenerRes,
 	}
 }
@@ -353,3 +347,29 @@ var streamBlockListeners = make(map[string]map[*streamBlockListener]bool)
 var streamBlockListenersMu sync.Mutex
 
 var nullListenerRes = &resp.RESPNull{CompatibilityFlag: 1}
+
+// unsafeServeStreamReaders serves the clients blocked reading from key with the entries of the stream at key from the ones they read from,
+// if there are any. Since the stream may have been replaced while they were blocked, e.g. by RENAME, they may be served entries that were
+// not added by XADD, and may not be served ones that were. The caller must hold DbMu.
+func unsafeServeStreamReaders(key string) {
+	stream, ok := state.Db.Value(key).(*DbStream)
+	if !ok {
+		return
+	}
+	streamBlockListenersMu.Lock()
+	defer streamBlockListenersMu.Unlock()
+	for listener := range streamBlockListeners[key] {
+		from := listener.from[key]
+		i := stream.SearchGreaterOrEqual(from[0], from[1])
+		if i >= stream.Len() {
+			continue
+		}
+		skv := &resp.RESPArray{Value: []resp.RESP{&resp.RESPBulkString{Value: key}, stream.EncodeSlice(i, -1)}}
+		listener.l.Lock()
+		if listener.res == nullListenerRes {
+			listener.res = &resp.RESPArray{Value: []resp.RESP{skv}}
+		}
+		listener.cond.Broadcast()
+		listener.l.Unlock()
+	}
+}
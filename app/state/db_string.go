@@ -3,6 +3,10 @@ package state
 import (
 	"errors"
 	"log"
+	"math"
+	"math/big"
+	"math/bits"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -12,124 +16,759 @@ var (
 )
 
 type DbString struct {
+	dbExpiry
 	string
-	expiresAt time.Time
 }
 
 var _ DbValue = (*DbString)(nil)
-var _ DefinitelyExpirer = (*DbString)(nil)
 
 func (v *DbString) Type() string {
 	return "string"
 }
 
-func (v *DbString) IsDefinitelyExpiredAt(t time.Time) bool {
-	return !IsReplica() && !v.expiresAt.IsZero() && v.expiresAt.Before(t)
+// SetOptions are the options of SET. Cond is one of "" (always set), "NX" (the key does not exist), "XX" (the key exists)
+// and "IFEQ" (the key holds a string equal to IfEq). The key is set to expire at At unless it is zero, or retains the expiry
+// of the string previously at the key if KeepTTL is set. Get requests the string previously at the key.
+type SetOptions struct {
+	Cond    string
+	IfEq    string
+	At      time.Time
+	KeepTTL bool
+	Get     bool
 }
 
-func Set(key, value string, px int64) error {
-	// zero time means no expiry
-	var expiresAt time.Time
-	if px != -1 {
-		expiresAt = time.Now().Add(time.Duration(px) * time.Millisecond)
+// Set sets key to value subject to opts, and returns whether it did, along with the string previously at key if opts.Get is set.
+// The previous value must be a string if opts.Get is set or opts.Cond is "IFEQ".
+func Set(key, value string, opts SetOptions) (*string, bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	v, exists := unsafeLookup(key, time.Now())
+	w, isString := v.(*DbString)
+	if exists && !isString && (opts.Get || opts.Cond == "IFEQ") {
+		return nil, false, ErrorWrongType
+	}
+	var prev *string
+	if opts.Get && exists {
+		prev = &w.string
+	}
+	switch opts.Cond {
+	case "NX":
+		if exists {
+			return prev, false, nil
+		}
+	case "XX":
+		if !exists {
+			return prev, false, nil
+		}
+	case "IFEQ":
+		if !exists || w.string != opts.IfEq {
+			return prev, false, nil
+		}
 	}
+	expiresAt := opts.At
+	if opts.KeepTTL && exists {
+		expiresAt = v.ExpiresAt()
+	}
+	UnsafeSet(key, value, expiresAt)
+	return prev, true, nil
+}
+
+// SetAt sets key to value, expiring at expiresAt unless it is zero
+func SetAt(key, value string, expiresAt time.Time) {
 	LockDbMu()
 	UnsafeSet(key, value, expiresAt)
 	UnlockDbMu()
-	return nil
 }
 
 func Incr(key string) (int64, error) {
-	LockDbMu()
-	defer UnlockDbMu()
-	v, ok := state.Db[key]
+	return Incrby(key, 1)
+}
+
+func Get(key string) (string, error) {
+	RLockDbMu()
+	v, ok := state.Db.Get(key)
+	RUnlockDbMu()
 	if !ok {
-		state.Db[key] = &DbString{string: "1", expiresAt: time.Time{}}
-		return 1, nil
+		return "", ErrorNone
+	}
+	if v.isExpiredAt(time.Now()) {
+		go TryEvictExpiredKey(key)
+		return "", ErrorNone
 	}
 	w, ok := v.(*DbString)
 	if !ok {
-		return 0, ErrorNotInteger
+		return "", ErrorWrongType
+	}
+	return w.string, nil
+}
+
+// StringMaxSize is the largest size that APPEND and SETRANGE may grow a string to
+const StringMaxSize = 512 << 20
+
+var (
+	ErrorStringTooLong = errors.New("ERR string exceeds maximum allowed size (proto-max-bulk-len)")
+	ErrorNotFloat      = errors.New("ERR value is not a valid float")
+)
+
+// Append appends value to the string at key, creating it if needed, and returns the length of the result
+func Append(key, value string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return 0, err
+	}
+	if w == nil {
+		UnsafeSet(key, value, time.Time{})
+		return int64(len(value)), nil
+	}
+	if len(w.string)+len(value) > StringMaxSize {
+		return 0, ErrorStringTooLong
+	}
+	UnsafeSet(key, w.string+value, w.expiresAt)
+	return int64(len(w.string) + len(value)), nil
+}
+
+func Strlen(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil || w == nil {
+		return 0, err
+	}
+	return int64(len(w.string)), nil
+}
+
+// Getrange returns the substring of the string at key between the offsets start and end inclusive, where negative offsets
+// count back from the end of the string
+func Getrange(key string, start, end int64) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil || w == nil {
+		return "", err
+	}
+	n := int64(len(w.string))
+	if start < 0 && end < 0 && start > end {
+		return "", nil
+	}
+	if start < 0 {
+		start += n
 	}
-	if !w.expiresAt.IsZero() && w.expiresAt.Before(time.Now()) {
-		state.Db[key] = &DbString{string: "1", expiresAt: time.Time{}}
-		return 1, nil
+	if end < 0 {
+		end += n
 	}
-	i, err := strconv.ParseInt(w.string, 10, 64)
+	start, end = max(start, 0), min(max(end, 0), n-1)
+	if start > end {
+		return "", nil
+	}
+	return w.string[start : end+1], nil
+}
+
+// Setrange overwrites the string at key with value from offset on, zero-padding the string as needed, and returns its length.
+// A missing key is left alone if value is empty.
+func Setrange(key string, offset int64, value string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
 	if err != nil {
-		return 0, ErrorNotInteger
+		return 0, err
 	}
-	i++
-	UnsafeSet(key, strconv.FormatInt(i, 10), w.expiresAt)
-	return i, nil
+	var current string
+	var expiresAt time.Time
+	if w != nil {
+		current, expiresAt = w.string, w.expiresAt
+	}
+	if value == "" {
+		return int64(len(current)), nil
+	}
+	if offset > StringMaxSize-int64(len(value)) {
+		return 0, ErrorStringTooLong
+	}
+	b := []byte(current)
+	if need := int(offset) + len(value); len(b) < need {
+		b = append(b, make([]byte, need-len(b))...)
+	}
+	copy(b[offset:], value)
+	UnsafeSet(key, string(b), expiresAt)
+	return int64(len(b)), nil
 }
 
-func Get(key string) (string, error) {
+// Mget returns the strings at keys, which are nil for keys that are missing or do not hold strings
+func Mget(keys []string) []*string {
 	RLockDbMu()
-	v, ok := state.Db[key]
-	RUnlockDbMu()
-	if !ok {
+	defer RUnlockDbMu()
+	values := make([]*string, len(keys))
+	for i, key := range keys {
+		if w, err := unsafeGetString(key); err == nil && w != nil {
+			values[i] = &w.string
+		}
+	}
+	return values
+}
+
+// Mset sets the given alternating keys and values, clearing their expiry
+func Mset(pairs []string) {
+	LockDbMu()
+	defer UnlockDbMu()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		UnsafeSet(pairs[i], pairs[i+1], time.Time{})
+	}
+}
+
+// Msetnx sets the given alternating keys and values if none of the keys exist, and returns whether it did
+func Msetnx(pairs []string) bool {
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if _, ok := unsafeLookup(pairs[i], now); ok {
+			return false
+		}
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		UnsafeSet(pairs[i], pairs[i+1], time.Time{})
+	}
+	return true
+}
+
+// Setnx sets key to value if it does not exist, and returns whether it did
+func Setnx(key, value string) bool {
+	return Msetnx([]string{key, value})
+}
+
+// Getdel deletes the string at key and returns it, or returns ErrorNone if there is none
+func Getdel(key string) (string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return "", err
+	}
+	if w == nil {
 		return "", ErrorNone
 	}
-	w, ok := v.(*DbString)
-	if !ok {
-		return "", ErrorWrongType
+	unsafeDelete(key, time.Now())
+	return w.string, nil
+}
+
+// Getex returns the string at key, and then sets it to expire at at if expire is set, or clears its expiry if persist is set.
+// An expiry that is not in the future deletes the key. It returns ErrorNone if there is no string at key.
+func Getex(key string, at time.Time, expire, persist bool) (string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return "", err
 	}
-	if !w.expiresAt.IsZero() && w.expiresAt.Before(time.Now()) {
-		go TryEvictExpiredKey(key)
+	if w == nil {
 		return "", ErrorNone
 	}
+	switch {
+	case expire && !at.After(time.Now()):
+		unsafeDelete(key, time.Now())
+	case expire:
+		UnsafeSet(key, w.string, at)
+	case persist:
+		UnsafeSet(key, w.string, time.Time{})
+	}
 	return w.string, nil
 }
 
+// Getset sets key to value, clearing its expiry, and returns the string previously at key, or nil if there was none
+func Getset(key, value string) (*string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return nil, err
+	}
+	UnsafeSet(key, value, time.Time{})
+	if w == nil {
+		return nil, nil
+	}
+	return &w.string, nil
+}
+
+// Incrby increments the integer at key by by, treating a missing key as 0, and returns the result. The expiry of the key is retained.
+func Incrby(key string, by int64) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return 0, err
+	}
+	var i int64
+	var expiresAt time.Time
+	if w != nil {
+		if i, err = strconv.ParseInt(w.string, 10, 64); err != nil {
+			return 0, ErrorNotInteger
+		}
+		expiresAt = w.expiresAt
+	}
+	if by > 0 && i > math.MaxInt64-by || by < 0 && i < math.MinInt64-by {
+		return 0, ErrorOverflow
+	}
+	i += by
+	UnsafeSet(key, strconv.FormatInt(i, 10), expiresAt)
+	return i, nil
+}
+
+// Incrbyfloat increments the float at key by by, treating a missing key as 0, and returns the result as it was stored.
+// The expiry of the key is retained.
+func Incrbyfloat(key string, by float64) (string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return "", err
+	}
+	var f float64
+	var expiresAt time.Time
+	if w != nil {
+		f, err = strconv.ParseFloat(w.string, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", ErrorNotFloat
+		}
+		expiresAt = w.expiresAt
+	}
+	f += by
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrorNaNOrInfinity
+	}
+	s := formatFloat(f)
+	UnsafeSet(key, s, expiresAt)
+	return s, nil
+}
+
 func TryEvictExpiredKey(key string) {
 	LockDbMu()
 	defer UnlockDbMu()
-	v, ok := state.Db[key]
+	v, ok := state.Db.Get(key)
 	if !ok {
 		return
 	}
-	if w, ok := v.(DefinitelyExpirer); ok && w.IsDefinitelyExpiredAt(time.Now()) {
-		delete(state.Db, key)
+	if now := time.Now(); v.IsDefinitelyExpiredAt(now) {
+		unsafeDelete(key, now)
 	}
 }
 
+// Tuning of the sweeps of SyncTryEvictExpiredKeysSweep and SyncTryEvictExpiredHashFieldsSweep
+const (
+	// evictionSweepMapSizeThreshold is the number of keys in a map below which we will not bother to sweep for expired keys.
+	evictionSweepMapSizeThreshold = 1000
+	// evictionSweepCountPerAcquisition is the number of keys we check for expiration each time we acquire the lock
+	evictionSweepCountPerAcquisition = 100
+	// evictionSweepSleepPerAcquisitionInMs is the number of milliseconds we sleep after each acquisition of the lock.
+	evictionSweepSleepPerAcquisition = 10 * time.Millisecond
+)
+
 // syncTryEvictExpiredKeys is a helper function for daemons to evict expired keys from all maps. It is expected to run for a long time.
 func SyncTryEvictExpiredKeysSweep() {
-	const (
-		// evictionSweepMapSizeThreshold is the number of keys in a map below which we will not bother to sweep for expired keys.
-		evictionSweepMapSizeThreshold = 1000
-		// evictionSweepCountPerAcquisition is the number of keys we check for expiration each time we acquire the lock
-		evictionSweepCountPerAcquisition = 100
-		// evictionSweepSleepPerAcquisitionInMs is the number of milliseconds we sleep after each acquisition of the lock.
-		evictionSweepSleepPerAcquisition = 10 * time.Millisecond
-	)
-
 	if IsReplica() {
 		log.Println("SyncTryEvictExpiredKeysSweep: not a master, skipping")
 		return
 	}
 	log.Println("SyncTryEvictExpiredKeysSweep: started")
 	RLockDbMu()
-	if len(state.Db) < evictionSweepMapSizeThreshold {
+	size := state.Db.Len()
+	RUnlockDbMu()
+	if size < evictionSweepMapSizeThreshold {
 		return
 	}
-	RUnlockDbMu()
-	now := time.Now()
+	// the keyspace is walked with a cursor, which remains valid across acquisitions of the lock while it is modified
+	var cursor uint64
+	for {
+		now := time.Now()
+		var expired []string
+		LockDbMu()
+		for i := 0; i < evictionSweepCountPerAcquisition; {
+			cursor = state.Db.Scan(cursor, func(k string, v DbValue) {
+				if v.IsDefinitelyExpiredAt(now) {
+					expired = append(expired, k)
+				}
+				i++
+			})
+			if cursor == 0 {
+				break
+			}
+		}
+		for _, k := range expired {
+			unsafeDelete(k, now)
+		}
+		UnlockDbMu()
+		if cursor == 0 {
+			return
+		}
+		time.Sleep(evictionSweepSleepPerAcquisition)
+	}
+}
+
+// unsafeGetString returns the string stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetString(key string) (*DbString, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	w, ok := v.(*DbString)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return w, nil
+}
+
+// Bitmap operations. Bits are numbered from the most significant bit of the first byte of the string.
+
+// BitMaxOffset is the largest bit offset that SETBIT may set, which limits strings to 512MB
+const BitMaxOffset = 1<<32 - 1
+
+// BitOp is a bitwise operation over multiple strings
+type BitOp int
+
+const (
+	BitOpAnd BitOp = iota
+	BitOpOr
+	BitOpXor
+	BitOpNot
+)
+
+// BitRange is a range of a string given by the offsets of its first and last bytes, or of its first and last bits if Bit is set.
+// Negative offsets count back from the end of the string.
+type BitRange struct {
+	Start, End int64
+	Bit        bool
+}
+
+// bits returns the offsets of the first and last bits of the range in a string of n bytes, or false if the range is empty
+func (r BitRange) bits(n int) (int64, int64, bool) {
+	length := int64(n)
+	if r.Bit {
+		length *= 8
+	}
+	start, end := r.Start, r.End
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	start, end = max(start, 0), max(end, 0)
+	end = min(end, length-1)
+	if start > end {
+		return 0, 0, false
+	}
+	if !r.Bit {
+		start, end = start*8, end*8+7
+	}
+	return start, end, true
+}
+
+func bitAt(s string, offset int64) byte {
+	if offset/8 >= int64(len(s)) {
+		return 0
+	}
+	return (s[offset/8] >> (7 - offset%8)) & 1
+}
+
+// Setbit sets the bit at offset of the string at key to bit, zero-extending the string as needed, and returns the previous bit
+func Setbit(key string, offset int64, bit byte) (byte, error) {
 	LockDbMu()
-	i := 0
-	for k, v := range state.Db {
-		if w, ok := v.(DefinitelyExpirer); ok && w.IsDefinitelyExpiredAt(now) {
-			delete(state.Db, k)
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return 0, err
+	}
+	var value string
+	var expiresAt time.Time
+	if w != nil {
+		value, expiresAt = w.string, w.expiresAt
+	}
+	prev := bitAt(value, offset)
+	b := []byte(value)
+	if need := int(offset/8) + 1; len(b) < need {
+		b = append(b, make([]byte, need-len(b))...)
+	}
+	if bit == 1 {
+		b[offset/8] |= 1 << (7 - offset%8)
+	} else {
+		b[offset/8] &^= 1 << (7 - offset%8)
+	}
+	UnsafeSet(key, string(b), expiresAt)
+	return prev, nil
+}
+
+func Getbit(key string, offset int64) (byte, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil || w == nil {
+		return 0, err
+	}
+	return bitAt(w.string, offset), nil
+}
+
+// Bitcount counts the set bits of the string at key within r, or within the whole string if r is nil
+func Bitcount(key string, r *BitRange) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil || w == nil {
+		return 0, err
+	}
+	s := w.string
+	if r == nil {
+		r = &BitRange{Start: 0, End: -1}
+	}
+	start, end, ok := r.bits(len(s))
+	if !ok {
+		return 0, nil
+	}
+	var n int64
+	for i := start / 8; i <= end/8; i++ {
+		b := s[i]
+		if i == start/8 {
+			b &= 0xff >> (start % 8)
+		}
+		if i == end/8 {
+			b &= 0xff << (7 - end%8)
+		}
+		n += int64(bits.OnesCount8(b))
+	}
+	return n, nil
+}
+
+// Bitpos returns the offset of the first bit within r of the string at key that is equal to bit, or -1 if there is none.
+// When looking for a clear bit and the range extends to the end of the string without hasEnd being set, the string is taken to be padded
+// with clear bits, as with BITPOS.
+func Bitpos(key string, bit byte, r BitRange, hasEnd bool) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return 0, err
+	}
+	if w == nil {
+		if bit == 0 {
+			return 0, nil
+		}
+		return -1, nil
+	}
+	s := w.string
+	start, end, ok := r.bits(len(s))
+	if !ok {
+		return -1, nil
+	}
+	// whole bytes consisting entirely of bits other than the one sought are skipped
+	skip := byte(0)
+	if bit == 0 {
+		skip = 0xff
+	}
+	for i := start; i <= end; {
+		if i%8 == 0 && i+7 <= end && s[i/8] == skip {
+			i += 8
+			continue
+		}
+		if bitAt(s, i) == bit {
+			return i, nil
 		}
 		i++
-		if i >= evictionSweepCountPerAcquisition {
-			UnlockDbMu()
-			i = 0
-			time.Sleep(evictionSweepSleepPerAcquisition)
-			now = time.Now()
-			LockDbMu()
+	}
+	if bit == 0 && !hasEnd {
+		return int64(len(s)) * 8, nil
+	}
+	return -1, nil
+}
+
+// Bitop stores the result of op over the strings at keys at dst, with shorter strings zero-padded, and returns the length of the result.
+// BitOpNot takes a single key.
+func Bitop(op BitOp, dst string, keys []string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	values := make([]string, len(keys))
+	n := 0
+	for i, key := range keys {
+		w, err := unsafeGetString(key)
+		if err != nil {
+			return 0, err
+		}
+		if w != nil {
+			values[i] = w.string
+			n = max(n, len(w.string))
 		}
 	}
-	UnlockDbMu()
+	if n == 0 {
+		unsafeDelete(dst, time.Now())
+		return 0, nil
+	}
+	res := make([]byte, n)
+	copy(res, values[0])
+	if op == BitOpNot {
+		for i := range res {
+			res[i] = ^res[i]
+		}
+	}
+	for _, v := range values[1:] {
+		for i := range res {
+			var b byte
+			if i < len(v) {
+				b = v[i]
+			}
+			switch op {
+			case BitOpAnd:
+				res[i] &= b
+			case BitOpOr:
+				res[i] |= b
+			case BitOpXor:
+				res[i] ^= b
+			}
+		}
+	}
+	UnsafeSet(dst, string(res), time.Time{})
+	return int64(n), nil
+}
+
+// BitfieldKind is the kind of a BITFIELD subcommand
+type BitfieldKind int
+
+const (
+	BitfieldGet BitfieldKind = iota
+	BitfieldSet
+	BitfieldIncrby
+)
+
+// BitfieldOverflow is the behaviour of BITFIELD writes that overflow their field
+type BitfieldOverflow int
+
+const (
+	BitfieldWrap BitfieldOverflow = iota
+	BitfieldSat
+	BitfieldFail
+)
+
+// BitfieldOp is a BITFIELD subcommand on the integer field of Width bits at bit Offset, which is signed if Signed is set.
+// Value is the value to set or the increment, and Overflow is the behaviour should the result not fit the field.
+type BitfieldOp struct {
+	Kind     BitfieldKind
+	Signed   bool
+	Width    int
+	Offset   int64
+	Value    int64
+	Overflow BitfieldOverflow
+}
+
+// fieldBounds returns the smallest and largest values of the field of op
+func (op BitfieldOp) fieldBounds() (*big.Int, *big.Int) {
+	if op.Signed {
+		lo := new(big.Int).Lsh(big.NewInt(1), uint(op.Width-1))
+		return new(big.Int).Neg(lo), lo.Sub(lo, big.NewInt(1))
+	}
+	hi := new(big.Int).Lsh(big.NewInt(1), uint(op.Width))
+	return big.NewInt(0), hi.Sub(hi, big.NewInt(1))
+}
+
+// fit fits v into the field of op according to its overflow behaviour, returning false if it does not fit and the behaviour is BitfieldFail
+func (op BitfieldOp) fit(v *big.Int) (int64, bool) {
+	lo, hi := op.fieldBounds()
+	if v.Cmp(lo) >= 0 && v.Cmp(hi) <= 0 {
+		return v.Int64(), true
+	}
+	switch op.Overflow {
+	case BitfieldSat:
+		if v.Cmp(lo) < 0 {
+			return lo.Int64(), true
+		}
+		return hi.Int64(), true
+	case BitfieldWrap:
+		// reduce modulo 2^width into the range [lo, lo+2^width)
+		size := new(big.Int).Lsh(big.NewInt(1), uint(op.Width))
+		w := new(big.Int).Sub(v, lo)
+		w.Mod(w, size)
+		return w.Add(w, lo).Int64(), true
+	}
+	return 0, false
+}
+
+func getBitfield(b []byte, op BitfieldOp) int64 {
+	var u uint64
+	for i := int64(0); i < int64(op.Width); i++ {
+		offset := op.Offset + i
+		var bit uint64
+		if offset/8 < int64(len(b)) {
+			bit = uint64(b[offset/8]>>(7-offset%8)) & 1
+		}
+		u = u<<1 | bit
+	}
+	if op.Signed && op.Width < 64 && u>>(op.Width-1) == 1 {
+		// sign extension
+		u |= ^uint64(0) << op.Width
+	}
+	return int64(u)
+}
+
+func setBitfield(b []byte, op BitfieldOp, v int64) {
+	u := uint64(v)
+	for i := int64(op.Width) - 1; i >= 0; i-- {
+		offset := op.Offset + i
+		if u&1 == 1 {
+			b[offset/8] |= 1 << (7 - offset%8)
+		} else {
+			b[offset/8] &^= 1 << (7 - offset%8)
+		}
+		u >>= 1
+	}
+}
+
+// Bitfield performs ops on the string at key in order, returning the result of each op, which is nil for writes that failed due to overflow.
+// It also returns the writes that were performed, each rewritten as a BitfieldSet of the value written, which reproduce the result of ops.
+func Bitfield(key string, ops []BitfieldOp) ([]*int64, []BitfieldOp, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	var b []byte
+	var expiresAt time.Time
+	if w != nil {
+		b, expiresAt = []byte(w.string), w.expiresAt
+	}
+	res := make([]*int64, len(ops))
+	var writes []BitfieldOp
+	for i, op := range ops {
+		current := getBitfield(b, op)
+		if op.Kind == BitfieldGet {
+			res[i] = &current
+			continue
+		}
+		v := big.NewInt(op.Value)
+		if op.Kind == BitfieldIncrby {
+			v.Add(v, big.NewInt(current))
+		}
+		value, ok := op.fit(v)
+		if !ok {
+			continue
+		}
+		if need := int(op.Offset+int64(op.Width)-1)/8 + 1; len(b) < need {
+			b = append(b, make([]byte, need-len(b))...)
+		}
+		setBitfield(b, op, value)
+		if op.Kind == BitfieldSet {
+			res[i] = &current
+		} else {
+			res[i] = &value
+		}
+		op.Kind, op.Value = BitfieldSet, value
+		writes = append(writes, op)
+	}
+	if len(writes) > 0 {
+		UnsafeSet(key, string(b), expiresAt)
+	}
+	// writes that are entirely overwritten by a later write to the same field are dropped
+	var final []BitfieldOp
+	for i, op := range writes {
+		if !slices.ContainsFunc(writes[i+1:], func(later BitfieldOp) bool { return later.Offset == op.Offset && later.Width == op.Width }) {
+			final = append(final, op)
+		}
+	}
+	return res, final, nil
 }
@@ -0,0 +1,72 @@
+package state
+
+import "errors"
+
+var ErrorLcsTooLarge = errors.New("ERR Insufficient memory, transient memory for LCS exceeds proto-max-bulk-len")
+
+// LcsMatch is a run of consecutive characters of the longest common subsequence of two strings, given by the offsets of its first
+// and last characters in each string
+type LcsMatch struct {
+	A, B [2]int64
+	Len  int64
+}
+
+// Lcs returns the longest common subsequence of the strings at key1 and key2, treating missing keys as empty strings,
+// along with its matches from the last to the first
+func Lcs(key1, key2 string) (string, []LcsMatch, error) {
+	RLockDbMu()
+	var values [2]string
+	for i, key := range []string{key1, key2} {
+		w, err := unsafeGetString(key)
+		if err != nil {
+			RUnlockDbMu()
+			return "", nil, err
+		}
+		if w != nil {
+			values[i] = w.string
+		}
+	}
+	RUnlockDbMu()
+	a, b := values[0], values[1]
+	if int64(len(a)+1)*int64(len(b)+1) > StringMaxSize/4 {
+		return "", nil, ErrorLcsTooLarge
+	}
+	// dp[i*(len(b)+1)+j] is the length of the longest common subsequence of a[:i] and b[:j]
+	width := len(b) + 1
+	dp := make([]uint32, (len(a)+1)*width)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i*width+j] = dp[(i-1)*width+j-1] + 1
+			} else {
+				dp[i*width+j] = max(dp[(i-1)*width+j], dp[i*width+j-1])
+			}
+		}
+	}
+	// walk back from the end, collecting the subsequence and the runs of characters that are consecutive in both strings
+	res := make([]byte, dp[len(a)*width+len(b)])
+	var matches []LcsMatch
+	var m *LcsMatch
+	for i, j, k := len(a), len(b), len(res); i > 0 && j > 0; {
+		if a[i-1] == b[j-1] {
+			k--
+			res[k] = a[i-1]
+			i, j = i-1, j-1
+			if m != nil && m.A[0] == int64(i+1) && m.B[0] == int64(j+1) {
+				m.A[0], m.B[0] = int64(i), int64(j)
+				m.Len++
+				continue
+			}
+			matches = append(matches, LcsMatch{A: [2]int64{int64(i), int64(i)}, B: [2]int64{int64(j), int64(j)}, Len: 1})
+			m = &matches[len(matches)-1]
+			continue
+		}
+		m = nil
+		if dp[(i-1)*width+j] > dp[i*width+j-1] {
+			i--
+		} else {
+			j--
+		}
+	}
+	return string(res), matches, nil
+}
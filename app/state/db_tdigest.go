@@ -0,0 +1,491 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+)
+
+const TDigestDefaultCompression = 100
+
+var (
+	ErrorTDigestKeyExists = errors.New("T-Digest: key already exists")
+	ErrorTDigestNoKey     = errors.New("T-Digest: key does not exist")
+)
+
+// DbTDigest is a merging t-digest, which summarizes a distribution with centroids sized by the arcsine scale function,
+// so that they are small near the tails. Values are buffered as unmerged centroids until capacity centroids are held,
+// and then merged with the others.
+type DbTDigest struct {
+	dbExpiry
+	compression int64
+	merged      []tdCentroid
+	unmerged    []tdCentroid
+	min         float64
+	max         float64
+	// compressions counts the merges of the unmerged centroids
+	compressions int64
+}
+
+var _ DbValue = (*DbTDigest)(nil)
+
+func (v *DbTDigest) Type() string {
+	return "TDIS-TYPE"
+}
+
+type tdCentroid struct {
+	mean   float64
+	weight float64
+}
+
+func newDbTDigest(compression int64) *DbTDigest {
+	return &DbTDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}
+}
+
+func (v *DbTDigest) capacity() int {
+	return int(6*v.compression + 10)
+}
+
+func tdWeight(cs []tdCentroid) float64 {
+	var w float64
+	for _, c := range cs {
+		w += c.weight
+	}
+	return w
+}
+
+// add buffers a centroid, merging the buffer once full
+func (v *DbTDigest) add(c tdCentroid) {
+	v.unmerged = append(v.unmerged, c)
+	v.min, v.max = min(v.min, c.mean), max(v.max, c.mean)
+	if len(v.merged)+len(v.unmerged) >= v.capacity() {
+		v.compress()
+	}
+}
+
+func (v *DbTDigest) compress() {
+	if len(v.unmerged) == 0 {
+		return
+	}
+	v.merged = v.centroids()
+	v.unmerged = nil
+	v.compressions++
+}
+
+// centroids returns the centroids of the digest merged, without modifying it
+func (v *DbTDigest) centroids() []tdCentroid {
+	if len(v.unmerged) == 0 {
+		return v.merged
+	}
+	all := slices.Concat(v.merged, v.unmerged)
+	slices.SortStableFunc(all, func(a, b tdCentroid) int {
+		return cmpFloat(a.mean, b.mean)
+	})
+	total := tdWeight(all)
+	// k is the arcsine scale function, which only lets a centroid grow while it spans at most 1 on the scale
+	k := func(q float64) float64 {
+		return float64(v.compression) / (2 * math.Pi) * math.Asin(2*min(max(q, 0), 1)-1)
+	}
+	res := []tdCentroid{all[0]}
+	var weightSoFar float64
+	kLeft := k(0)
+	for _, c := range all[1:] {
+		cur := &res[len(res)-1]
+		proposed := cur.weight + c.weight
+		if k((weightSoFar+proposed)/total)-kLeft <= 1 {
+			cur.mean += (c.mean - cur.mean) * c.weight / proposed
+			cur.weight = proposed
+			continue
+		}
+		weightSoFar += cur.weight
+		kLeft = k(weightSoFar / total)
+		res = append(res, c)
+	}
+	return res
+}
+
+// tdWeightedAverage interpolates between x1 and x2, staying within them despite rounding
+func tdWeightedAverage(x1, w1, x2, w2 float64) float64 {
+	lo, hi := min(x1, x2), max(x1, x2)
+	return min(max((x1*w1+x2*w2)/(w1+w2), lo), hi)
+}
+
+// quantile returns the estimated value at quantile q of the centroids cs, interpolating between the centers of centroids
+// and treating centroids of weight 1 as exact values
+func (v *DbTDigest) quantile(cs []tdCentroid, q float64) float64 {
+	n := len(cs)
+	switch n {
+	case 0:
+		return math.NaN()
+	case 1:
+		return cs[0].mean
+	}
+	total := tdWeight(cs)
+	index := q * total
+	if index < 1 {
+		return v.min
+	}
+	if first := cs[0]; first.weight > 1 && index < first.weight/2 {
+		return v.min + (index-1)/(first.weight/2-1)*(first.mean-v.min)
+	}
+	if index > total-1 {
+		return v.max
+	}
+	last := cs[n-1]
+	if last.weight > 1 && total-index <= last.weight/2 {
+		return v.max - (total-index-1)/(last.weight/2-1)*(v.max-last.mean)
+	}
+	weightSoFar := cs[0].weight / 2
+	for i := range n - 1 {
+		dw := (cs[i].weight + cs[i+1].weight) / 2
+		if weightSoFar+dw > index {
+			var leftUnit, rightUnit float64
+			if cs[i].weight == 1 {
+				if index-weightSoFar < 0.5 {
+					return cs[i].mean
+				}
+				leftUnit = 0.5
+			}
+			if cs[i+1].weight == 1 {
+				if weightSoFar+dw-index <= 0.5 {
+					return cs[i+1].mean
+				}
+				rightUnit = 0.5
+			}
+			z1 := index - weightSoFar - leftUnit
+			z2 := weightSoFar + dw - index - rightUnit
+			return tdWeightedAverage(cs[i].mean, z2, cs[i+1].mean, z1)
+		}
+		weightSoFar += dw
+	}
+	z1 := index - total - last.weight/2
+	z2 := last.weight/2 - z1
+	return tdWeightedAverage(last.mean, z1, v.max, z2)
+}
+
+// cdf returns the estimated fraction of values below x, counting half of those equal to it
+func (v *DbTDigest) cdf(cs []tdCentroid, x float64) float64 {
+	n := len(cs)
+	switch {
+	case n == 0:
+		return math.NaN()
+	case x < v.min:
+		return 0
+	case x > v.max:
+		return 1
+	case n == 1:
+		return 0.5
+	}
+	total := tdWeight(cs)
+	first, last := cs[0], cs[n-1]
+	if x < first.mean {
+		if first.mean-v.min <= 0 {
+			return 0
+		}
+		if x == v.min {
+			return 0.5 / total
+		}
+		return (1 + (x-v.min)/(first.mean-v.min)*(first.weight/2-1)) / total
+	}
+	if x > last.mean {
+		if v.max-last.mean <= 0 {
+			return 1
+		}
+		if x == v.max {
+			return 1 - 0.5/total
+		}
+		return 1 - (1+(v.max-x)/(v.max-last.mean)*(last.weight/2-1))/total
+	}
+	var weightSoFar float64
+	for i := 0; i < n-1; i++ {
+		c, next := cs[i], cs[i+1]
+		switch {
+		case c.mean == x:
+			var dw float64
+			for ; i < n && cs[i].mean == x; i++ {
+				dw += cs[i].weight
+			}
+			return (weightSoFar + dw/2) / total
+		case x < next.mean:
+			if next.mean-c.mean <= 0 {
+				return (weightSoFar + (c.weight+next.weight)/2) / total
+			}
+			var leftExcluded, rightExcluded float64
+			if c.weight == 1 {
+				if next.weight == 1 {
+					return (weightSoFar + 1) / total
+				}
+				leftExcluded = 0.5
+			} else if next.weight == 1 {
+				rightExcluded = 0.5
+			}
+			dw := (c.weight+next.weight)/2 - leftExcluded - rightExcluded
+			base := weightSoFar + c.weight/2 + leftExcluded
+			return (base + dw*(x-c.mean)/(next.mean-c.mean)) / total
+		}
+		weightSoFar += c.weight
+	}
+	return 1 - 0.5/total
+}
+
+// unsafeGetTDigest returns the digest stored at key, or ErrorTDigestNoKey if there is none. The caller must hold DbMu.
+func unsafeGetTDigest(key string) (*DbTDigest, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, ErrorTDigestNoKey
+	}
+	td, ok := v.(*DbTDigest)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return td, nil
+}
+
+// t-digest operations
+
+// TdigestCreate creates an empty digest at key, as with TDIGEST.CREATE
+func TdigestCreate(key string, compression int64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorTDigestKeyExists
+	}
+	unsafeSet(key, newDbTDigest(compression))
+	return nil
+}
+
+// TdigestReset empties the digest at key, keeping its compression, as with TDIGEST.RESET
+func TdigestReset(key string) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return err
+	}
+	*td = *newDbTDigest(td.compression)
+	return nil
+}
+
+// TdigestAdd adds values to the digest at key, as with TDIGEST.ADD
+func TdigestAdd(key string, values []float64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return err
+	}
+	for _, x := range values {
+		td.add(tdCentroid{mean: x, weight: 1})
+	}
+	return nil
+}
+
+// TdigestMerge stores the merge of the digests at sources in dest, as with TDIGEST.MERGE. The current values of dest are kept
+// unless override is set. If compression is 0, it is that of dest if kept and otherwise the largest of the sources.
+func TdigestMerge(dest string, sources []string, compression int64, override bool) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	tds := make([]*DbTDigest, 0, len(sources)+1)
+	var maxCompression int64
+	for _, key := range sources {
+		td, err := unsafeGetTDigest(key)
+		if err != nil {
+			return err
+		}
+		tds = append(tds, td)
+		maxCompression = max(maxCompression, td.compression)
+	}
+	td, err := unsafeGetTDigest(dest)
+	if err == ErrorWrongType {
+		return err
+	}
+	if err == nil && !override {
+		tds = append(tds, td)
+		maxCompression = td.compression
+	}
+	if compression == 0 {
+		compression = maxCompression
+	}
+	res := newDbTDigest(compression)
+	for _, td := range tds {
+		for _, c := range td.centroids() {
+			res.add(c)
+		}
+		res.min, res.max = min(res.min, td.min), max(res.max, td.max)
+	}
+	res.compress()
+	unsafeSet(dest, res)
+	return nil
+}
+
+// TdigestSummary returns the smallest and largest values added to the digest at key, which are NaN if it is empty,
+// as with TDIGEST.MIN and TDIGEST.MAX
+func TdigestSummary(key string) (float64, float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(td.merged)+len(td.unmerged) == 0 {
+		return math.NaN(), math.NaN(), nil
+	}
+	return td.min, td.max, nil
+}
+
+// TdigestQuantile returns the estimated value at each quantile, or NaN if the digest is empty, as with TDIGEST.QUANTILE
+func TdigestQuantile(key string, quantiles []float64) ([]float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return nil, err
+	}
+	cs := td.centroids()
+	res := make([]float64, len(quantiles))
+	for i, q := range quantiles {
+		res[i] = td.quantile(cs, q)
+	}
+	return res, nil
+}
+
+// TdigestCdf returns the estimated fraction of values below each value, counting half of those equal to it,
+// or NaN if the digest is empty, as with TDIGEST.CDF
+func TdigestCdf(key string, values []float64) ([]float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return nil, err
+	}
+	cs := td.centroids()
+	res := make([]float64, len(values))
+	for i, x := range values {
+		res[i] = td.cdf(cs, x)
+	}
+	return res, nil
+}
+
+// TdigestRank returns the estimated number of values below each value, counting half of those equal to it, or above it if rev is set,
+// as with TDIGEST.RANK and TDIGEST.REVRANK. The rank is -1 for values beyond every added value, and -2 if the digest is empty.
+func TdigestRank(key string, values []float64, rev bool) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return nil, err
+	}
+	cs := td.centroids()
+	total := tdWeight(cs)
+	res := make([]int64, len(values))
+	for i, x := range values {
+		switch {
+		case len(cs) == 0:
+			res[i] = -2
+		case rev && x > td.max, !rev && x < td.min:
+			res[i] = -1
+		case rev && x < td.min, !rev && x > td.max:
+			res[i] = int64(total)
+		case rev:
+			res[i] = int64(math.Ceil(total - td.cdf(cs, x)*total - 0.5))
+		default:
+			res[i] = int64(math.Ceil(td.cdf(cs, x)*total - 0.5))
+		}
+	}
+	return res, nil
+}
+
+// TdigestByrank returns the estimated value with each rank, counting from the largest value if rev is set,
+// as with TDIGEST.BYRANK and TDIGEST.BYREVRANK. The value is infinite for ranks beyond the number of values,
+// and NaN if the digest is empty.
+func TdigestByrank(key string, ranks []int64, rev bool) ([]float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return nil, err
+	}
+	cs := td.centroids()
+	total := tdWeight(cs)
+	res := make([]float64, len(ranks))
+	for i, r := range ranks {
+		switch {
+		case len(cs) == 0:
+			res[i] = math.NaN()
+		case float64(r) >= total && rev:
+			res[i] = math.Inf(-1)
+		case float64(r) >= total:
+			res[i] = math.Inf(1)
+		case rev:
+			res[i] = td.quantile(cs, (total-1-float64(r))/total)
+		default:
+			res[i] = td.quantile(cs, float64(r)/total)
+		}
+	}
+	return res, nil
+}
+
+// TdigestTrimmedMean returns the estimated mean of the values between the quantiles low and high, as with TDIGEST.TRIMMED_MEAN
+func TdigestTrimmedMean(key string, low, high float64) (float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return 0, err
+	}
+	cs := td.centroids()
+	total := tdWeight(cs)
+	lowWeight, highWeight := math.Floor(total*low), math.Ceil(total*high)
+	var weightSoFar, sum, count float64
+	for _, c := range cs {
+		// only count the part of the centroid between the cuts
+		w := c.weight - max(0, lowWeight-weightSoFar) - max(0, weightSoFar+c.weight-highWeight)
+		if w > 0 {
+			sum += c.mean * w
+			count += w
+		}
+		weightSoFar += c.weight
+		if weightSoFar >= highWeight {
+			break
+		}
+	}
+	if count == 0 {
+		return math.NaN(), nil
+	}
+	return sum / count, nil
+}
+
+// TDigestInfo describes a digest, as with TDIGEST.INFO
+type TDigestInfo struct {
+	Compression    int64
+	Capacity       int64
+	MergedNodes    int64
+	UnmergedNodes  int64
+	MergedWeight   int64
+	UnmergedWeight int64
+	Observations   int64
+	Compressions   int64
+	MemoryUsage    int64
+}
+
+func TdigestInfo(key string) (TDigestInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	td, err := unsafeGetTDigest(key)
+	if err != nil {
+		return TDigestInfo{}, err
+	}
+	info := TDigestInfo{
+		Compression:    td.compression,
+		Capacity:       int64(td.capacity()),
+		MergedNodes:    int64(len(td.merged)),
+		UnmergedNodes:  int64(len(td.unmerged)),
+		MergedWeight:   int64(tdWeight(td.merged)),
+		UnmergedWeight: int64(tdWeight(td.unmerged)),
+		Compressions:   td.compressions,
+		MemoryUsage:    int64(td.capacity()) * 16,
+	}
+	info.Observations = info.MergedWeight + info.UnmergedWeight
+	return info, nil
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+var ErrorThrottleRange = errors.New("ERR rate limit parameters out of range")
+
+// ThrottleResult is the outcome of a request to a rate limiter. Limit is the number of requests allowed in a burst, and Remaining
+// the number of those still allowed right after this one. RetryAfter is how long until the request would be allowed, which is -1
+// if it was allowed or can never be, and ResetAfter how long until the limiter is back to its initial state.
+// If the state of the limiter was written, Value and ExpiresAt hold what was stored at the key.
+type ThrottleResult struct {
+	Limited    bool
+	Limit      int64
+	Remaining  int64
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+	Written    bool
+	Value      string
+	ExpiresAt  time.Time
+}
+
+// Throttle applies the generic cell rate algorithm to a request of quantity units against a limiter allowing count units
+// per period seconds, with bursts of up to maxBurst+1 units. Units are emitted one every period/count, and the limiter keeps
+// the theoretical arrival time (TAT) at which all the units allowed so far would have been emitted. A request is allowed
+// if the TAT it would move to is no further ahead of now than the burst allows.
+// The TAT is stored at key as a string of Unix nanoseconds which expires once it has passed, when the limiter is fresh again.
+// The expiry is rounded up to the millisecond, so that it can be replicated exactly.
+func Throttle(key string, maxBurst, count, period, quantity int64) (ThrottleResult, error) {
+	if float64(period)*float64(time.Second)*float64(max(maxBurst+1, quantity))/float64(count) >= math.MaxInt64/2 {
+		return ThrottleResult{}, ErrorThrottleRange
+	}
+	emission := time.Duration(period) * time.Second / time.Duration(count)
+	tolerance := emission * time.Duration(maxBurst+1)
+	increment := emission * time.Duration(quantity)
+
+	LockDbMu()
+	defer UnlockDbMu()
+	now := time.Now()
+	w, err := unsafeGetString(key)
+	if err != nil {
+		return ThrottleResult{}, err
+	}
+	tat := now
+	if w != nil {
+		ns, err := strconv.ParseInt(w.string, 10, 64)
+		if err != nil {
+			return ThrottleResult{}, ErrorNotInteger
+		}
+		if t := time.Unix(0, ns); t.After(now) {
+			tat = t
+		}
+	}
+	res := ThrottleResult{Limit: maxBurst + 1, RetryAfter: -1}
+	next := tat.Add(increment)
+	if diff := now.Sub(next.Add(-tolerance)); diff < 0 {
+		res.Limited = true
+		if increment <= tolerance {
+			res.RetryAfter = -diff
+		}
+		next = tat
+	} else if quantity > 0 {
+		res.Written = true
+		res.Value = strconv.FormatInt(next.UnixNano(), 10)
+		res.ExpiresAt = time.UnixMilli((next.UnixNano() + int64(time.Millisecond) - 1) / int64(time.Millisecond))
+		UnsafeSet(key, res.Value, res.ExpiresAt)
+	}
+	res.ResetAfter = next.Sub(now)
+	if left := tolerance - res.ResetAfter; left > -emission {
+		res.Remaining = max(int64(left/emission), 0)
+	}
+	return res, nil
+}
@@ -0,0 +1,473 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+const TSDefaultChunkSize = 4096
+
+// Duplicate policies, which decide how a sample is merged into one with the same timestamp
+const (
+	TSDuplicateBlock = "BLOCK"
+	TSDuplicateFirst = "FIRST"
+	TSDuplicateLast  = "LAST"
+	TSDuplicateMin   = "MIN"
+	TSDuplicateMax   = "MAX"
+	TSDuplicateSum   = "SUM"
+)
+
+var (
+	ErrorTSKeyExists    = errors.New("TSDB: key already exists")
+	ErrorTSNoKey        = errors.New("TSDB: the key does not exist")
+	ErrorTSDuplicate    = errors.New("TSDB: Error at upsert, update is not supported when DUPLICATE_POLICY is set to BLOCK mode")
+	ErrorTSOld          = errors.New("TSDB: Timestamp is older than retention")
+	ErrorTSSameKey      = errors.New("TSDB: the source key and destination key should be different")
+	ErrorTSDestHasSrc   = errors.New("TSDB: the destination key already has a src rule")
+	ErrorTSDestHasRules = errors.New("TSDB: the destination key already has a dst rule")
+	ErrorTSSrcHasSrc    = errors.New("TSDB: the source key already has a source rule")
+	ErrorTSNoRule       = errors.New("TSDB: compaction rule does not exist")
+)
+
+// DbTimeSeries is a time series of samples stored in chunks by increasing timestamp, which may be compressed.
+// Samples older than the retention period before the last one are dropped a chunk at a time, and hidden from queries until then.
+// Each compaction rule aggregates the samples of a bucket into a destination series once a sample is added to a later bucket.
+type DbTimeSeries struct {
+	dbExpiry
+	chunks     []tsChunk
+	retention  int64
+	chunkSize  int
+	compressed bool
+	// duplicatePolicy is empty for the default, BLOCK
+	duplicatePolicy string
+	labels          []TSLabel
+	rules           []*tsRule
+	// srcKey is the key of the series with the rule compacting into this one, if any
+	srcKey string
+}
+
+var _ DbValue = (*DbTimeSeries)(nil)
+
+func (v *DbTimeSeries) Type() string {
+	return "TSDB-TYPE"
+}
+
+type TSLabel struct {
+	Name  string
+	Value string
+}
+
+// TSOptions are the parameters of a new series. The chunks are compressed unless Uncompressed is set.
+// The retention period is unlimited if 0.
+type TSOptions struct {
+	Retention       int64
+	ChunkSize       int
+	Uncompressed    bool
+	DuplicatePolicy string
+	Labels          []TSLabel
+}
+
+// TSAggregation aggregates samples by buckets of Bucket milliseconds, aligned to start at Align modulo Bucket
+type TSAggregation struct {
+	Aggregator string
+	Bucket     int64
+	Align      int64
+}
+
+// bucketStart returns the start of the bucket of ts
+func (a TSAggregation) bucketStart(ts int64) int64 {
+	offset := (ts - a.Align) % a.Bucket
+	if offset < 0 {
+		offset += a.Bucket
+	}
+	return ts - offset
+}
+
+// tsRule compacts samples into the series at dest. open is set once a sample was added, in the bucket starting at start.
+type tsRule struct {
+	dest  string
+	agg   TSAggregation
+	start int64
+	open  bool
+}
+
+func newDbTimeSeries(opts TSOptions) *DbTimeSeries {
+	return &DbTimeSeries{
+		retention:       opts.Retention,
+		chunkSize:       opts.ChunkSize,
+		compressed:      !opts.Uncompressed,
+		duplicatePolicy: opts.DuplicatePolicy,
+		labels:          opts.Labels,
+	}
+}
+
+func (v *DbTimeSeries) newChunk() tsChunk {
+	if v.compressed {
+		return &tsCompressedChunk{}
+	}
+	return &tsRawChunk{}
+}
+
+// encode stores samples in as many chunks as needed
+func (v *DbTimeSeries) encode(samples []TSSample) []tsChunk {
+	var res []tsChunk
+	for _, s := range samples {
+		if len(res) == 0 || !res[len(res)-1].append(s, v.chunkSize) {
+			c := v.newChunk()
+			c.append(s, v.chunkSize)
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func (v *DbTimeSeries) lastSample() (TSSample, bool) {
+	if len(v.chunks) == 0 {
+		return TSSample{}, false
+	}
+	s := v.chunks[len(v.chunks)-1].samples()
+	return s[len(s)-1], true
+}
+
+// minTimestamp returns the timestamp of the oldest sample within the retention period
+func (v *DbTimeSeries) minTimestamp() int64 {
+	if v.retention == 0 || len(v.chunks) == 0 {
+		return math.MinInt64
+	}
+	return v.chunks[len(v.chunks)-1].last() - v.retention
+}
+
+// samplesIn returns the samples with timestamps within from and to, inclusive
+func (v *DbTimeSeries) samplesIn(from, to int64) []TSSample {
+	from = max(from, v.minTimestamp())
+	var res []TSSample
+	for _, c := range v.chunks {
+		if c.last() < from || c.first() > to {
+			continue
+		}
+		for _, s := range c.samples() {
+			if s.Timestamp >= from && s.Timestamp <= to {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
+
+func (v *DbTimeSeries) totalSamples() int64 {
+	var n int64
+	for _, c := range v.chunks {
+		n += int64(c.len())
+	}
+	return n
+}
+
+// add adds a sample, merging it by policy into one with the same timestamp
+func (v *DbTimeSeries) add(s TSSample, policy string) error {
+	n := len(v.chunks)
+	if n > 0 && v.retention > 0 && s.Timestamp < v.chunks[n-1].last()-v.retention {
+		return ErrorTSOld
+	}
+	if n == 0 || s.Timestamp > v.chunks[n-1].last() {
+		if n == 0 || !v.chunks[n-1].append(s, v.chunkSize) {
+			v.chunks = append(v.chunks, v.encode([]TSSample{s})...)
+		}
+		v.trim()
+		return nil
+	}
+	k := sort.Search(len(v.chunks), func(i int) bool { return v.chunks[i].last() >= s.Timestamp })
+	samples := slices.Clone(v.chunks[k].samples())
+	i := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp >= s.Timestamp })
+	if i < len(samples) && samples[i].Timestamp == s.Timestamp {
+		old := &samples[i]
+		switch policy {
+		case TSDuplicateFirst:
+			return nil
+		case TSDuplicateLast:
+			old.Value = s.Value
+		case TSDuplicateMin:
+			old.Value = min(old.Value, s.Value)
+		case TSDuplicateMax:
+			old.Value = max(old.Value, s.Value)
+		case TSDuplicateSum:
+			old.Value += s.Value
+		default:
+			return ErrorTSDuplicate
+		}
+	} else {
+		samples = slices.Insert(samples, i, s)
+	}
+	v.chunks = slices.Concat(v.chunks[:k], v.encode(samples), v.chunks[k+1:])
+	return nil
+}
+
+// trim drops the chunks whose samples are all beyond the retention period
+func (v *DbTimeSeries) trim() {
+	minTs := v.minTimestamp()
+	n := 0
+	for n < len(v.chunks)-1 && v.chunks[n].last() < minTs {
+		n++
+	}
+	v.chunks = v.chunks[n:]
+}
+
+// unsafeGetTimeSeries returns the series stored at key, or ErrorTSNoKey if there is none. The caller must hold DbMu.
+func unsafeGetTimeSeries(key string) (*DbTimeSeries, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, ErrorTSNoKey
+	}
+	ts, ok := v.(*DbTimeSeries)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return ts, nil
+}
+
+// unsafeAddTimeSeries adds a sample to a series and applies its compaction rules. The caller must hold DbMu.
+func unsafeAddTimeSeries(v *DbTimeSeries, s TSSample, policy string) error {
+	if err := v.add(s, policy); err != nil {
+		return err
+	}
+	for _, r := range v.rules {
+		start := r.agg.bucketStart(s.Timestamp)
+		switch {
+		case !r.open:
+			r.start, r.open = start, true
+		case start > r.start:
+			// the open bucket is complete
+			unsafeCompact(v, r, r.start)
+			r.start = start
+		case start < r.start:
+			// a complete bucket changed
+			unsafeCompact(v, r, start)
+		}
+	}
+	return nil
+}
+
+// unsafeCompact aggregates the samples of the bucket starting at start into the destination of the rule, if it still exists.
+// The caller must hold DbMu.
+func unsafeCompact(v *DbTimeSeries, r *tsRule, start int64) {
+	dest, err := unsafeGetTimeSeries(r.dest)
+	if err != nil {
+		return
+	}
+	if s, ok := v.aggregateBucket(r.agg, start); ok {
+		unsafeAddTimeSeries(dest, s, TSDuplicateLast)
+	}
+}
+
+// aggregateBucket returns the aggregation of the bucket starting at start, if it has samples
+func (v *DbTimeSeries) aggregateBucket(agg TSAggregation, start int64) (TSSample, bool) {
+	samples := v.samplesIn(start, start+agg.Bucket-1)
+	if len(samples) == 0 {
+		return TSSample{}, false
+	}
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = s.Value
+	}
+	return TSSample{Timestamp: start, Value: tsAggregators[agg.Aggregator](values)}, true
+}
+
+// latest returns the aggregation of the open bucket of the rule compacting into the series, if any
+func (v *DbTimeSeries) latest(key string) (TSSample, bool) {
+	if v.srcKey == "" {
+		return TSSample{}, false
+	}
+	src, err := unsafeGetTimeSeries(v.srcKey)
+	if err != nil {
+		return TSSample{}, false
+	}
+	for _, r := range src.rules {
+		if r.dest == key && r.open {
+			return src.aggregateBucket(r.agg, r.start)
+		}
+	}
+	return TSSample{}, false
+}
+
+// Time series operations
+
+// TsCreate creates an empty series at key, as with TS.CREATE
+func TsCreate(key string, opts TSOptions) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorTSKeyExists
+	}
+	unsafeSet(key, newDbTimeSeries(opts))
+	return nil
+}
+
+// TsAdd adds a sample to the series at key, as with TS.ADD and TS.MADD. A sample with the same timestamp as an existing one
+// is merged by policy if set, and otherwise by the duplicate policy of the series. If the series does not exist,
+// it is created with opts, unless opts is nil.
+func TsAdd(key string, s TSSample, policy string, opts *TSOptions) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	v, err := unsafeGetTimeSeries(key)
+	if err == ErrorTSNoKey && opts != nil {
+		v, err = newDbTimeSeries(*opts), nil
+		unsafeSet(key, v)
+	}
+	if err != nil {
+		return err
+	}
+	if policy == "" {
+		policy = v.duplicatePolicy
+	}
+	return unsafeAddTimeSeries(v, s, policy)
+}
+
+// TsGet returns the last sample of the series at key, if any, as with TS.GET.
+// If latest is set, that of a compacted series may be the aggregation of the open bucket of its source.
+func TsGet(key string, latest bool) (*TSSample, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	v, err := unsafeGetTimeSeries(key)
+	if err != nil {
+		return nil, err
+	}
+	if latest {
+		if s, ok := v.latest(key); ok {
+			return &s, nil
+		}
+	}
+	if s, ok := v.lastSample(); ok {
+		return &s, nil
+	}
+	return nil, nil
+}
+
+// TsCreaterule adds a rule compacting the series at src into that at dest, as with TS.CREATERULE.
+// Compacted series cannot be compacted further.
+func TsCreaterule(src, dest string, agg TSAggregation) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if src == dest {
+		return ErrorTSSameKey
+	}
+	s, err := unsafeGetTimeSeries(src)
+	if err != nil {
+		return err
+	}
+	d, err := unsafeGetTimeSeries(dest)
+	if err != nil {
+		return err
+	}
+	switch {
+	case d.srcKey != "":
+		return ErrorTSDestHasSrc
+	case len(d.rules) > 0:
+		return ErrorTSDestHasRules
+	case s.srcKey != "":
+		return ErrorTSSrcHasSrc
+	}
+	s.rules = append(s.rules, &tsRule{dest: dest, agg: agg})
+	d.srcKey = src
+	return nil
+}
+
+// TsDeleterule removes the rule compacting the series at src into that at dest, as with TS.DELETERULE
+func TsDeleterule(src, dest string) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	s, err := unsafeGetTimeSeries(src)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(s.rules, func(r *tsRule) bool { return r.dest == dest })
+	if i < 0 {
+		return ErrorTSNoRule
+	}
+	s.rules = slices.Delete(s.rules, i, i+1)
+	if d, err := unsafeGetTimeSeries(dest); err == nil && d.srcKey == src {
+		d.srcKey = ""
+	}
+	return nil
+}
+
+// unsafeDetach removes the compaction rules into and out of the series v, which has been deleted from key. The caller must hold DbMu.
+func (v *DbTimeSeries) unsafeDetach(key string) {
+	for _, r := range v.rules {
+		if d, ok := state.Db.Value(r.dest).(*DbTimeSeries); ok && d.srcKey == key {
+			d.srcKey = ""
+		}
+	}
+	if s, ok := state.Db.Value(v.srcKey).(*DbTimeSeries); ok {
+		s.rules = slices.DeleteFunc(s.rules, func(r *tsRule) bool { return r.dest == key })
+	}
+}
+
+// unsafeRename updates the compaction rules into and out of the series v, which has been renamed from src to dst. The caller must hold DbMu.
+func (v *DbTimeSeries) unsafeRename(src, dst string) {
+	for _, r := range v.rules {
+		if d, ok := state.Db.Value(r.dest).(*DbTimeSeries); ok && d.srcKey == src {
+			d.srcKey = dst
+		}
+	}
+	if s, ok := state.Db.Value(v.srcKey).(*DbTimeSeries); ok {
+		for _, r := range s.rules {
+			if r.dest == src {
+				r.dest = dst
+			}
+		}
+	}
+}
+
+// TSRule describes a compaction rule, as listed by TS.INFO
+type TSRule struct {
+	Dest string
+	TSAggregation
+}
+
+// TSInfo describes a series, as with TS.INFO
+type TSInfo struct {
+	TotalSamples    int64
+	MemoryUsage     int64
+	FirstTimestamp  int64
+	LastTimestamp   int64
+	Retention       int64
+	ChunkCount      int64
+	ChunkSize       int64
+	Compressed      bool
+	DuplicatePolicy string
+	Labels          []TSLabel
+	SrcKey          string
+	Rules           []TSRule
+}
+
+func TsInfo(key string) (TSInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	v, err := unsafeGetTimeSeries(key)
+	if err != nil {
+		return TSInfo{}, err
+	}
+	info := TSInfo{
+		TotalSamples:    v.totalSamples(),
+		Retention:       v.retention,
+		ChunkCount:      int64(len(v.chunks)),
+		ChunkSize:       int64(v.chunkSize),
+		Compressed:      v.compressed,
+		DuplicatePolicy: v.duplicatePolicy,
+		Labels:          v.labels,
+		SrcKey:          v.srcKey,
+	}
+	for _, c := range v.chunks {
+		info.MemoryUsage += int64(c.bytes())
+	}
+	if len(v.chunks) > 0 {
+		info.FirstTimestamp = v.chunks[0].first()
+		info.LastTimestamp = v.chunks[len(v.chunks)-1].last()
+	}
+	for _, r := range v.rules {
+		info.Rules = append(info.Rules, TSRule{Dest: r.dest, TSAggregation: r.agg})
+	}
+	return info, nil
+}
@@ -0,0 +1,228 @@
+package state
+
+import (
+	"math"
+	"math/bits"
+)
+
+// TSSample is a sample of a time series, with a timestamp in milliseconds
+type TSSample struct {
+	Timestamp int64
+	Value     float64
+}
+
+// tsChunk stores consecutive samples of a time series by increasing timestamp, within a size in bytes
+type tsChunk interface {
+	// append adds a sample later than the last one, returning false if the chunk would grow beyond size bytes
+	append(s TSSample, size int) bool
+	samples() []TSSample
+	first() int64
+	last() int64
+	len() int
+	bytes() int
+}
+
+// tsRawChunk stores samples as they are, in 16 bytes each
+type tsRawChunk struct {
+	s []TSSample
+}
+
+var _ tsChunk = (*tsRawChunk)(nil)
+
+func (c *tsRawChunk) append(s TSSample, size int) bool {
+	if (len(c.s)+1)*16 > size {
+		return false
+	}
+	c.s = append(c.s, s)
+	return true
+}
+
+func (c *tsRawChunk) samples() []TSSample {
+	return c.s
+}
+
+func (c *tsRawChunk) first() int64 {
+	return c.s[0].Timestamp
+}
+
+func (c *tsRawChunk) last() int64 {
+	return c.s[len(c.s)-1].Timestamp
+}
+
+func (c *tsRawChunk) len() int {
+	return len(c.s)
+}
+
+func (c *tsRawChunk) bytes() int {
+	return len(c.s) * 16
+}
+
+// tsCompressedChunk stores samples as in Gorilla: the timestamp and value of the first sample are stored in full,
+// and each later timestamp as the difference between its delta from the previous one and the previous delta, in a variable number of bits.
+// Each later value is xored with the previous one, and only the bits between the leading and trailing zeros of the result are stored,
+// reusing the previous such window if they fit in it.
+type tsCompressedChunk struct {
+	data []byte
+	// nbits is the number of bits used in data
+	nbits     int
+	n         int
+	firstTs   int64
+	lastTs    int64
+	lastDelta int64
+	lastValue uint64
+	// leading and trailing delimit the last window of meaningful xored bits, if hasWindow is set
+	leading   int
+	trailing  int
+	hasWindow bool
+}
+
+var _ tsChunk = (*tsCompressedChunk)(nil)
+
+// tsDodEncodings lists the number of bits of each encoding of a delta of deltas, after a prefix of as many 1 bits as its index and a 0 bit,
+// except for the last one, which takes the whole 64 bits after a prefix of 1 bits only
+var tsDodEncodings = []int{0, 7, 9, 12, 32, 64}
+
+func (c *tsCompressedChunk) write(v uint64, width int) {
+	for width > 0 {
+		if c.nbits%8 == 0 {
+			c.data = append(c.data, 0)
+		}
+		free := 8 - c.nbits%8
+		k := min(free, width)
+		b := (v >> (width - k)) & (1<<k - 1)
+		c.data[len(c.data)-1] |= byte(b << (free - k))
+		c.nbits += k
+		width -= k
+	}
+}
+
+// truncate discards the bits from the nth on
+func (c *tsCompressedChunk) truncate(n int) {
+	c.nbits = n
+	c.data = c.data[:(n+7)/8]
+	if n%8 != 0 {
+		c.data[len(c.data)-1] &= ^byte(0) << (8 - n%8)
+	}
+}
+
+func (c *tsCompressedChunk) append(s TSSample, size int) bool {
+	saved := *c
+	value := math.Float64bits(s.Value)
+	if c.n == 0 {
+		c.write(uint64(s.Timestamp), 64)
+		c.write(value, 64)
+		c.firstTs = s.Timestamp
+	} else {
+		delta := s.Timestamp - c.lastTs
+		c.writeDod(delta - c.lastDelta)
+		c.lastDelta = delta
+		c.writeXor(value ^ c.lastValue)
+	}
+	if len(c.data) > size {
+		nbits := saved.nbits
+		*c = saved
+		c.truncate(nbits)
+		return false
+	}
+	c.n++
+	c.lastTs = s.Timestamp
+	c.lastValue = value
+	return true
+}
+
+func (c *tsCompressedChunk) writeDod(dod int64) {
+	for i, width := range tsDodEncodings {
+		last := i == len(tsDodEncodings)-1
+		if !last && (width == 0 && dod != 0 || width > 0 && (dod < -1<<(width-1) || dod >= 1<<(width-1))) {
+			continue
+		}
+		c.write(1<<i-1, i)
+		if !last {
+			c.write(0, 1)
+		}
+		c.write(uint64(dod), width)
+		return
+	}
+}
+
+func (c *tsCompressedChunk) writeXor(x uint64) {
+	if x == 0 {
+		c.write(0, 1)
+		return
+	}
+	leading, trailing := bits.LeadingZeros64(x), bits.TrailingZeros64(x)
+	if c.hasWindow && leading >= c.leading && trailing >= c.trailing {
+		c.write(0b10, 2)
+		c.write(x>>c.trailing, 64-c.leading-c.trailing)
+		return
+	}
+	c.write(0b11, 2)
+	c.write(uint64(leading), 6)
+	c.write(uint64(64-leading-trailing-1), 6)
+	c.write(x>>trailing, 64-leading-trailing)
+	c.leading, c.trailing, c.hasWindow = leading, trailing, true
+}
+
+func (c *tsCompressedChunk) samples() []TSSample {
+	res := make([]TSSample, 0, c.n)
+	pos := 0
+	read := func(width int) uint64 {
+		var v uint64
+		for width > 0 {
+			off := pos % 8
+			k := min(8-off, width)
+			b := uint64(c.data[pos/8]>>(8-off-k)) & (1<<k - 1)
+			v = v<<k | b
+			pos += k
+			width -= k
+		}
+		return v
+	}
+	var ts, delta int64
+	var value uint64
+	var leading, trailing int
+	for i := range c.n {
+		if i == 0 {
+			ts, value = int64(read(64)), read(64)
+			res = append(res, TSSample{Timestamp: ts, Value: math.Float64frombits(value)})
+			continue
+		}
+		prefix := 0
+		for prefix < len(tsDodEncodings)-1 && read(1) == 1 {
+			prefix++
+		}
+		width := tsDodEncodings[prefix]
+		dod := int64(read(width))
+		if width > 0 && width < 64 {
+			// sign-extend
+			dod = dod << (64 - width) >> (64 - width)
+		}
+		delta += dod
+		ts += delta
+		if read(1) == 1 {
+			if read(1) == 1 {
+				leading = int(read(6))
+				trailing = 64 - leading - int(read(6)) - 1
+			}
+			value ^= read(64-leading-trailing) << trailing
+		}
+		res = append(res, TSSample{Timestamp: ts, Value: math.Float64frombits(value)})
+	}
+	return res
+}
+
+func (c *tsCompressedChunk) first() int64 {
+	return c.firstTs
+}
+
+func (c *tsCompressedChunk) last() int64 {
+	return c.lastTs
+}
+
+func (c *tsCompressedChunk) len() int {
+	return c.n
+}
+
+func (c *tsCompressedChunk) bytes() int {
+	return len(c.data)
+}
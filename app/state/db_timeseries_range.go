@@ -0,0 +1,297 @@
+package state
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tsAggregators aggregate the values of a bucket, in order of timestamp
+var tsAggregators = map[string]func(values []float64) float64{
+	"avg": func(values []float64) float64 {
+		return tsSum(values) / float64(len(values))
+	},
+	"sum": tsSum,
+	"min": func(values []float64) float64 {
+		return slices.Min(values)
+	},
+	"max": func(values []float64) float64 {
+		return slices.Max(values)
+	},
+	"range": func(values []float64) float64 {
+		return slices.Max(values) - slices.Min(values)
+	},
+	"count": func(values []float64) float64 {
+		return float64(len(values))
+	},
+	"first": func(values []float64) float64 {
+		return values[0]
+	},
+	"last": func(values []float64) float64 {
+		return values[len(values)-1]
+	},
+	"var.p": func(values []float64) float64 {
+		return tsVariance(values, false)
+	},
+	"var.s": func(values []float64) float64 {
+		return tsVariance(values, true)
+	},
+	"std.p": func(values []float64) float64 {
+		return math.Sqrt(tsVariance(values, false))
+	},
+	"std.s": func(values []float64) float64 {
+		return math.Sqrt(tsVariance(values, true))
+	},
+}
+
+// IsTSAggregator returns whether name is a lowercase aggregator name
+func IsTSAggregator(name string) bool {
+	_, ok := tsAggregators[name]
+	return ok
+}
+
+func tsSum(values []float64) float64 {
+	var sum float64
+	for _, x := range values {
+		sum += x
+	}
+	return sum
+}
+
+// tsVariance returns the population variance of values, or the sample variance if sample is set, which is 0 for a single value
+func tsVariance(values []float64, sample bool) float64 {
+	n := float64(len(values))
+	if sample && n == 1 {
+		return 0
+	}
+	mean := tsSum(values) / n
+	var ss float64
+	for _, x := range values {
+		ss += (x - mean) * (x - mean)
+	}
+	if sample {
+		return ss / (n - 1)
+	}
+	return ss / n
+}
+
+// Reported timestamps of aggregation buckets
+const (
+	TSBucketStart = iota
+	TSBucketEnd
+	TSBucketMid
+)
+
+// TSRangeSpec selects the samples of a series from From to To, inclusive, as with TS.RANGE. If Latest is set, the aggregation of the open bucket
+// of the source of a compacted series is included. Samples may be filtered by timestamp and value before being aggregated,
+// and up to Count samples are returned unless it is 0, from the last one if Rev is set.
+type TSRangeSpec struct {
+	From             int64
+	To               int64
+	Latest           bool
+	FilterTimestamps []int64
+	FilterValue      bool
+	MinValue         float64
+	MaxValue         float64
+	Count            int64
+	Aggregation      *TSAggregation
+	BucketTimestamp  int
+	// Empty includes empty buckets between the first and last samples
+	Empty bool
+	Rev   bool
+}
+
+// rangeSamples returns the samples of the series at key selected by spec
+func (v *DbTimeSeries) rangeSamples(key string, spec TSRangeSpec) []TSSample {
+	samples := v.samplesIn(spec.From, spec.To)
+	if spec.Latest {
+		if s, ok := v.latest(key); ok && s.Timestamp >= spec.From && s.Timestamp <= spec.To &&
+			(len(samples) == 0 || s.Timestamp > samples[len(samples)-1].Timestamp) {
+			samples = append(samples, s)
+		}
+	}
+	if spec.FilterTimestamps != nil || spec.FilterValue {
+		samples = slices.DeleteFunc(samples, func(s TSSample) bool {
+			return spec.FilterTimestamps != nil && !slices.Contains(spec.FilterTimestamps, s.Timestamp) ||
+				spec.FilterValue && (s.Value < spec.MinValue || s.Value > spec.MaxValue)
+		})
+	}
+	if spec.Aggregation != nil {
+		samples = aggregateSamples(samples, spec)
+	}
+	if spec.Rev {
+		slices.Reverse(samples)
+	}
+	if spec.Count > 0 && int64(len(samples)) > spec.Count {
+		samples = samples[:spec.Count]
+	}
+	return samples
+}
+
+// aggregateSamples aggregates samples by bucket, reporting empty buckets as 0 for sum and count, as the last value before them for last,
+// and as NaN otherwise
+func aggregateSamples(samples []TSSample, spec TSRangeSpec) []TSSample {
+	agg := spec.Aggregation
+	f := tsAggregators[agg.Aggregator]
+	bucketTimestamp := func(start int64) int64 {
+		switch spec.BucketTimestamp {
+		case TSBucketEnd:
+			return start + agg.Bucket
+		case TSBucketMid:
+			return start + agg.Bucket/2
+		}
+		return start
+	}
+	var res []TSSample
+	var prevStart int64
+	for i := 0; i < len(samples); {
+		start := agg.bucketStart(samples[i].Timestamp)
+		if spec.Empty && len(res) > 0 {
+			var empty float64
+			switch agg.Aggregator {
+			case "sum", "count":
+			case "last":
+				empty = samples[i-1].Value
+			default:
+				empty = math.NaN()
+			}
+			for t := prevStart + agg.Bucket; t < start; t += agg.Bucket {
+				res = append(res, TSSample{Timestamp: bucketTimestamp(t), Value: empty})
+			}
+		}
+		var values []float64
+		for ; i < len(samples) && samples[i].Timestamp-start < agg.Bucket; i++ {
+			values = append(values, samples[i].Value)
+		}
+		res = append(res, TSSample{Timestamp: bucketTimestamp(start), Value: f(values)})
+		prevStart = start
+	}
+	return res
+}
+
+// TSFilter matches series whose label Label has one of Values, or none of them if Negate is set.
+// A missing label has the empty value.
+type TSFilter struct {
+	Label  string
+	Values []string
+	Negate bool
+}
+
+func (f TSFilter) match(labels []TSLabel) bool {
+	var value string
+	for _, l := range labels {
+		if l.Name == f.Label {
+			value = l.Value
+		}
+	}
+	return slices.Contains(f.Values, value) != f.Negate
+}
+
+// TSGroupBy groups series by the value of a label, reducing the values of the series of each group at each timestamp with Reducer,
+// which is an aggregator
+type TSGroupBy struct {
+	Label   string
+	Reducer string
+}
+
+// TSSeries is the result of a query of a series, or of a group of series
+type TSSeries struct {
+	Key     string
+	Labels  []TSLabel
+	Samples []TSSample
+}
+
+// TsRange returns the samples of the series at key selected by spec, as with TS.RANGE and TS.REVRANGE
+func TsRange(key string, spec TSRangeSpec) ([]TSSample, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	v, err := unsafeGetTimeSeries(key)
+	if err != nil {
+		return nil, err
+	}
+	return v.rangeSamples(key, spec), nil
+}
+
+// TsMrange returns the samples selected by spec of every series matching all of filters, by key, as with TS.MRANGE and TS.MREVRANGE.
+// If groupBy is set, the series are grouped instead, in order of label value.
+func TsMrange(filters []TSFilter, spec TSRangeSpec, groupBy *TSGroupBy) []TSSeries {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	var res []TSSeries
+	now := time.Now()
+	state.Db.Each(func(key string, dv DbValue) bool {
+		if v, ok := dv.(*DbTimeSeries); ok && !v.isExpiredAt(now) && tsMatch(v.labels, filters) {
+			res = append(res, TSSeries{Key: key, Labels: v.labels})
+		}
+		return true
+	})
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	if groupBy == nil {
+		for i := range res {
+			v := state.Db.Value(res[i].Key).(*DbTimeSeries)
+			res[i].Samples = v.rangeSamples(res[i].Key, spec)
+		}
+		return res
+	}
+	groupSpec := spec
+	groupSpec.Rev, groupSpec.Count = false, 0
+	groups := map[string][]TSSeries{}
+	for _, s := range res {
+		i := slices.IndexFunc(s.Labels, func(l TSLabel) bool { return l.Name == groupBy.Label })
+		if i < 0 {
+			continue
+		}
+		v := state.Db.Value(s.Key).(*DbTimeSeries)
+		s.Samples = v.rangeSamples(s.Key, groupSpec)
+		groups[s.Labels[i].Value] = append(groups[s.Labels[i].Value], s)
+	}
+	res = res[:0]
+	for value, series := range groups {
+		res = append(res, reduceSeries(groupBy, value, series, spec))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+func tsMatch(labels []TSLabel, filters []TSFilter) bool {
+	for _, f := range filters {
+		if !f.match(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// reduceSeries reduces a group of series with the same value of the label of groupBy
+func reduceSeries(groupBy *TSGroupBy, value string, series []TSSeries, spec TSRangeSpec) TSSeries {
+	values := map[int64][]float64{}
+	keys := make([]string, len(series))
+	for i, s := range series {
+		keys[i] = s.Key
+		for _, sample := range s.Samples {
+			values[sample.Timestamp] = append(values[sample.Timestamp], sample.Value)
+		}
+	}
+	samples := make([]TSSample, 0, len(values))
+	for ts, vs := range values {
+		samples = append(samples, TSSample{Timestamp: ts, Value: tsAggregators[groupBy.Reducer](vs)})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Timestamp < samples[j].Timestamp })
+	if spec.Rev {
+		slices.Reverse(samples)
+	}
+	if spec.Count > 0 && int64(len(samples)) > spec.Count {
+		samples = samples[:spec.Count]
+	}
+	return TSSeries{
+		Key: groupBy.Label + "=" + value,
+		Labels: []TSLabel{
+			{Name: groupBy.Label, Value: value},
+			{Name: "__reducer__", Value: groupBy.Reducer},
+			{Name: "__source__", Value: strings.Join(keys, ",")},
+		},
+		Samples: samples,
+	}
+}
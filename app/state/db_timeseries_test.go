@@ -0,0 +1,81 @@
+package state
+
+import (
+	"testing"
+)
+
+// setUpCompaction creates the series src and dst with a compaction rule from src into dst
+func setUpCompaction(t *testing.T) {
+	t.Helper()
+	UnsafeResetDbWithSizeHint(0)
+	for _, key := range []string{"src", "dst"} {
+		if err := TsCreate(key, TSOptions{}); err != nil {
+			t.Fatalf("TsCreate(%q): %v", key, err)
+		}
+	}
+	if err := TsCreaterule("src", "dst", TSAggregation{Aggregator: "avg", Bucket: 1000}); err != nil {
+		t.Fatalf("TsCreaterule: %v", err)
+	}
+}
+
+func TestOverwritingCompactionKeysDetachesRules(t *testing.T) {
+	tests := []struct {
+		name string
+		// overwrite replaces the series at key with a value of another type
+		overwrite func(t *testing.T, key string)
+	}{
+		{"SET", func(t *testing.T, key string) {
+			if _, _, err := Set(key, "hello", SetOptions{}); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+		}},
+		{"SET then GETDEL", func(t *testing.T, key string) {
+			if _, _, err := Set(key, "hello", SetOptions{}); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if _, err := Getdel(key); err != nil {
+				t.Fatalf("Getdel: %v", err)
+			}
+		}},
+		{"SORT STORE", func(t *testing.T, key string) {
+			if _, _, err := Push("list", []string{"2", "1"}, false, false); err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+			if _, _, err := SortStore("list", key, SortOptions{}); err != nil {
+				t.Fatalf("SortStore: %v", err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" dst", func(t *testing.T) {
+			setUpCompaction(t)
+			tt.overwrite(t, "dst")
+			info, err := TsInfo("src")
+			if err != nil {
+				t.Fatalf("TsInfo(src): %v", err)
+			}
+			if len(info.Rules) != 0 {
+				t.Errorf("src still has rules %v", info.Rules)
+			}
+			// a new series at dst must not be compacted into from src
+			Del([]string{"dst"})
+			if err := TsCreate("dst", TSOptions{}); err != nil {
+				t.Fatalf("TsCreate(dst): %v", err)
+			}
+			if info, _ := TsInfo("dst"); info.SrcKey != "" {
+				t.Errorf("new dst has source key %q", info.SrcKey)
+			}
+		})
+		t.Run(tt.name+" src", func(t *testing.T) {
+			setUpCompaction(t)
+			tt.overwrite(t, "src")
+			info, err := TsInfo("dst")
+			if err != nil {
+				t.Fatalf("TsInfo(dst): %v", err)
+			}
+			if info.SrcKey != "" {
+				t.Errorf("dst still has source key %q", info.SrcKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package state
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const (
+	TopKDefaultWidth = 8
+	TopKDefaultDepth = 7
+	TopKDefaultDecay = 0.9
+	// topkDecayTableSize is the number of precomputed powers of the decay, beyond which the last one is used
+	topkDecayTableSize = 256
+	// topkFingerprintSeed seeds the hash of fingerprints, which differs from those of the rows
+	topkFingerprintSeed = 1919
+	// topkRandSeed seeds the decay of every filter alike, so that replicas adding the same items end up with the same filter
+	topkRandSeed = 0x1e3779b97f4a7c15
+)
+
+var (
+	ErrorTopKKeyExists = errors.New("TopK: key already exists")
+	ErrorTopKNoKey     = errors.New("TopK: key does not exist")
+	ErrorTopKTooLarge  = errors.New("TopK: width/depth too large")
+)
+
+// topkMaxBuckets bounds the number of buckets of a filter
+const topkMaxBuckets = 1 << 26
+
+// DbTopK tracks the k items with the highest counts with HeavyKeeper, a sketch of depth rows of width buckets,
+// each holding the fingerprint of an item and its count. An item colliding with another one in a bucket decays its count
+// with a probability of decay to the power of the count, and takes the bucket over once it reaches 0.
+// The k items with the highest estimates are kept in a min-heap.
+type DbTopK struct {
+	dbExpiry
+	k          int64
+	width      int64
+	depth      int64
+	decay      float64
+	decayTable [topkDecayTableSize]float64
+	buckets    []topkBucket
+	heap       topkHeap
+	rand       *rand.Rand
+}
+
+var _ DbValue = (*DbTopK)(nil)
+
+func (v *DbTopK) Type() string {
+	return "TopK-TYPE"
+}
+
+type topkBucket struct {
+	fp    uint32
+	count int64
+}
+
+// TopKItem is an item tracked by a Top-K filter with its estimated count
+type TopKItem struct {
+	Item  string
+	Count int64
+}
+
+// topkHeap is a min-heap of the tracked items, filled with empty items of count 0 until k items have been seen
+type topkHeap []TopKItem
+
+var _ heap.Interface = (*topkHeap)(nil)
+
+func (h topkHeap) Len() int           { return len(h) }
+func (h topkHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h topkHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *topkHeap) Push(x any)        { *h = append(*h, x.(TopKItem)) }
+func (h *topkHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (h topkHeap) find(item string) int {
+	for i, it := range h {
+		if it.Count > 0 && it.Item == item {
+			return i
+		}
+	}
+	return -1
+}
+
+func newDbTopK(k, width, depth int64, decay float64) (*DbTopK, error) {
+	if width > topkMaxBuckets/depth || k > topkMaxBuckets {
+		return nil, ErrorTopKTooLarge
+	}
+	v := &DbTopK{
+		k:       k,
+		width:   width,
+		depth:   depth,
+		decay:   decay,
+		buckets: make([]topkBucket, width*depth),
+		heap:    make(topkHeap, k),
+		rand:    rand.New(rand.NewSource(topkRandSeed)),
+	}
+	for i := range v.decayTable {
+		v.decayTable[i] = math.Pow(decay, float64(i))
+	}
+	return v, nil
+}
+
+func topkFingerprint(item string) uint32 {
+	return uint32(murmurHash64A(item, topkFingerprintSeed))
+}
+
+func (v *DbTopK) bucket(item string, row int64) *topkBucket {
+	return &v.buckets[row*v.width+int64(murmurHash64A(item, uint64(row))%uint64(v.width))]
+}
+
+// incrby increments the count of item, returning the item expelled from the heap if any
+func (v *DbTopK) incrby(item string, incr int64) (string, bool) {
+	fp := topkFingerprint(item)
+	var maxCount int64
+	for row := range v.depth {
+		b := v.bucket(item, row)
+		switch {
+		case b.count == 0:
+			b.fp, b.count = fp, incr
+		case b.fp == fp:
+			b.count += incr
+		default:
+			for n := incr; n > 0; n-- {
+				if v.rand.Float64() < v.decayTable[min(b.count, topkDecayTableSize-1)] {
+					b.count--
+					if b.count == 0 {
+						b.fp, b.count = fp, n
+						break
+					}
+				}
+			}
+		}
+		if b.fp == fp {
+			maxCount = max(maxCount, b.count)
+		}
+	}
+	if maxCount == 0 || maxCount < v.heap[0].Count {
+		return "", false
+	}
+	if i := v.heap.find(item); i >= 0 {
+		v.heap[i].Count = maxCount
+		heap.Fix(&v.heap, i)
+		return "", false
+	}
+	expelled := v.heap[0]
+	v.heap[0] = TopKItem{Item: item, Count: maxCount}
+	heap.Fix(&v.heap, 0)
+	return expelled.Item, expelled.Count > 0
+}
+
+// count returns the estimated count of item
+func (v *DbTopK) count(item string) int64 {
+	fp := topkFingerprint(item)
+	var res int64
+	for row := range v.depth {
+		if b := v.bucket(item, row); b.fp == fp {
+			res = max(res, b.count)
+		}
+	}
+	return res
+}
+
+// unsafeGetTopK returns the filter stored at key, or ErrorTopKNoKey if there is none. The caller must hold DbMu.
+func unsafeGetTopK(key string) (*DbTopK, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, ErrorTopKNoKey
+	}
+	topk, ok := v.(*DbTopK)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return topk, nil
+}
+
+// Top-K operations
+
+// TopkReserve creates an empty filter at key, as with TOPK.RESERVE
+func TopkReserve(key string, k, width, depth int64, decay float64) error {
+	LockDbMu()
+	defer UnlockDbMu()
+	if _, ok := unsafeLookup(key, time.Now()); ok {
+		return ErrorTopKKeyExists
+	}
+	topk, err := newDbTopK(k, width, depth, decay)
+	if err != nil {
+		return err
+	}
+	unsafeSet(key, topk)
+	return nil
+}
+
+// TopkIncrby increments the count of each item by the increment at the same index, as with TOPK.ADD and TOPK.INCRBY.
+// It returns the item expelled from the top k by each one, or nil.
+func TopkIncrby(key string, items []string, increments []int64) ([]*string, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	topk, err := unsafeGetTopK(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*string, len(items))
+	for i, item := range items {
+		if expelled, ok := topk.incrby(item, increments[i]); ok {
+			res[i] = &expelled
+		}
+	}
+	return res, nil
+}
+
+// TopkQuery returns whether each item is in the top k, as with TOPK.QUERY
+func TopkQuery(key string, items []string) ([]bool, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	topk, err := unsafeGetTopK(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]bool, len(items))
+	for i, item := range items {
+		res[i] = topk.heap.find(item) >= 0
+	}
+	return res, nil
+}
+
+// TopkCount returns the estimated count of each item, as with TOPK.COUNT
+func TopkCount(key string, items []string) ([]int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	topk, err := unsafeGetTopK(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]int64, len(items))
+	for i, item := range items {
+		res[i] = topk.count(item)
+	}
+	return res, nil
+}
+
+// TopkList returns the top k items by decreasing count, as with TOPK.LIST
+func TopkList(key string) ([]TopKItem, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	topk, err := unsafeGetTopK(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]TopKItem, 0, len(topk.heap))
+	for _, it := range topk.heap {
+		if it.Count > 0 {
+			res = append(res, it)
+		}
+	}
+	slices.SortFunc(res, func(a, b TopKItem) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Item, b.Item))
+	})
+	return res, nil
+}
+
+// TopKInfo describes a filter, as with TOPK.INFO
+type TopKInfo struct {
+	K     int64
+	Width int64
+	Depth int64
+	Decay float64
+}
+
+func TopkInfo(key string) (TopKInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	topk, err := unsafeGetTopK(key)
+	if err != nil {
+		return TopKInfo{}, err
+	}
+	return TopKInfo{K: topk.k, Width: topk.width, Depth: topk.depth, Decay: topk.decay}, nil
+}
@@ -0,0 +1,700 @@
+package state
+
+import (
+	"cmp"
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// Quantizations and distance metrics of vector sets
+const (
+	VsetQuantNone = "NOQUANT"
+	VsetQuantQ8   = "Q8"
+	VsetMetricCos = "COSINE"
+	VsetMetricL2  = "L2"
+)
+
+const (
+	VsetDefaultM  = 16
+	VsetDefaultEF = 200
+	VsetMaxM      = 4096
+	// vsetMaxLevel bounds the layers of the graph
+	vsetMaxLevel = 16
+	// vsetRandSeed seeds the levels and projections of every set alike, so that replicas adding the same elements build the same graph
+	vsetRandSeed = 0x2545f4914f6cdd1d
+	// vsetQ8Range is the largest magnitude of a component quantized to int8
+	vsetQ8Range = 127
+)
+
+var (
+	ErrorVsetQuantMismatch  = errors.New("ERR asked quantization mismatch with existing vector set")
+	ErrorVsetMetricMismatch = errors.New("ERR asked distance metric mismatch with existing vector set")
+	ErrorVsetNoElement      = errors.New("ERR element not found in set")
+)
+
+func errorVsetDimMismatch(got, want int) error {
+	return fmt.Errorf("ERR Vector dimension mismatch - got %d but set has %d", got, want)
+}
+
+// DbVectorSet is a set of elements with vectors, indexed by a Hierarchical Navigable Small World graph for approximate nearest neighbour search.
+// Each node takes part in the layers from 0 up to a random level, linking to at most m nodes per layer, or 2m in layer 0. Links are kept
+// symmetric, so that removing a node only has to visit its neighbours, which are then linked to one another to repair the graph.
+//
+// Vectors are normalized for the cosine metric, retaining their norm, and may be projected to fewer dimensions with a random matrix first.
+// They are stored as float32, or as int8 scaled by the largest magnitude of the vector with Q8.
+// Levels and projections come from a generator seeded alike for every set, so that replicas adding the same elements build the same graph.
+type DbVectorSet struct {
+	dbExpiry
+	quant  string
+	metric string
+	// inputDim is the dimension of the vectors added, which are projected to dim dimensions if projection is set
+	inputDim   int
+	dim        int
+	projection [][]float32
+	m          int
+	ef         int
+	nodes      map[string]*vsetNode
+	entry      *vsetNode
+	maxLevel   int
+	rand       *rand.Rand
+}
+
+var _ DbValue = (*DbVectorSet)(nil)
+
+func (v *DbVectorSet) Type() string {
+	return "vectorset"
+}
+
+// vsetVector holds either float32 components, or int8 components that are multiplied by scale
+type vsetVector struct {
+	f     []float32
+	q     []int8
+	scale float32
+}
+
+func (x vsetVector) at(i int) float32 {
+	if x.q != nil {
+		return float32(x.q[i]) * x.scale
+	}
+	return x.f[i]
+}
+
+type vsetNode struct {
+	element string
+	vec     vsetVector
+	// norm is the norm of the vector before it was normalized, which is 1 for the L2 metric
+	norm float32
+	// attrs is the JSON of the attributes, and parsed is the parsed JSON object, or nil if there are none
+	attrs  string
+	parsed *jsonObject
+	// links holds the neighbours of the node in each layer it takes part in
+	links [][]*vsetNode
+}
+
+// VsetOptions configures the vector set created by VADD. The zero values of Quant and Metric stand for Q8 and COSINE,
+// but are not checked against an existing set.
+type VsetOptions struct {
+	Reduce int
+	Quant  string
+	Metric string
+	M      int
+	EF     int
+}
+
+func newDbVectorSet(inputDim int, opts VsetOptions) *DbVectorSet {
+	v := &DbVectorSet{
+		quant:    cmp.Or(opts.Quant, VsetQuantQ8),
+		metric:   cmp.Or(opts.Metric, VsetMetricCos),
+		inputDim: inputDim,
+		dim:      inputDim,
+		m:        cmp.Or(opts.M, VsetDefaultM),
+		ef:       cmp.Or(opts.EF, VsetDefaultEF),
+		nodes:    map[string]*vsetNode{},
+		rand:     rand.New(rand.NewSource(vsetRandSeed)),
+	}
+	if opts.Reduce > 0 {
+		v.dim = opts.Reduce
+		v.projection = make([][]float32, v.dim)
+		scale := 1 / math.Sqrt(float64(v.dim))
+		for i := range v.projection {
+			v.projection[i] = make([]float32, inputDim)
+			for j := range v.projection[i] {
+				v.projection[i][j] = float32(v.rand.NormFloat64() * scale)
+			}
+		}
+	}
+	return v
+}
+
+// prepare projects and normalizes an input vector as stored, returning its norm
+func (v *DbVectorSet) prepare(input []float32) ([]float32, float32) {
+	vec := input
+	if v.projection != nil {
+		vec = make([]float32, v.dim)
+		for i, row := range v.projection {
+			for j, x := range input {
+				vec[i] += row[j] * x
+			}
+		}
+	}
+	if v.metric != VsetMetricCos {
+		return vec, 1
+	}
+	var ss float64
+	for _, x := range vec {
+		ss += float64(x) * float64(x)
+	}
+	norm := float32(math.Sqrt(ss))
+	res := make([]float32, len(vec))
+	for i, x := range vec {
+		if norm > 0 {
+			res[i] = x / norm
+		}
+	}
+	return res, norm
+}
+
+// quantize stores a prepared vector as configured
+func (v *DbVectorSet) quantize(vec []float32) vsetVector {
+	if v.quant != VsetQuantQ8 {
+		return vsetVector{f: vec}
+	}
+	var maxAbs float32
+	for _, x := range vec {
+		maxAbs = max(maxAbs, float32(math.Abs(float64(x))))
+	}
+	res := vsetVector{q: make([]int8, len(vec)), scale: maxAbs / vsetQ8Range}
+	for i, x := range vec {
+		if res.scale > 0 {
+			res.q[i] = int8(math.Round(float64(x / res.scale)))
+		}
+	}
+	return res
+}
+
+// distance returns the cosine distance between normalized vectors, which is in [0, 2], or the euclidean distance
+func (v *DbVectorSet) distance(a, b vsetVector) float64 {
+	var d float64
+	for i := range v.dim {
+		x, y := float64(a.at(i)), float64(b.at(i))
+		if v.metric == VsetMetricCos {
+			d += x * y
+		} else {
+			d += (x - y) * (x - y)
+		}
+	}
+	if v.metric == VsetMetricCos {
+		return max(0, 1-d)
+	}
+	return math.Sqrt(d)
+}
+
+// similarity converts a distance into a score, which is 1 for identical vectors and decreases towards 0
+func (v *DbVectorSet) similarity(d float64) float64 {
+	if v.metric == VsetMetricCos {
+		return 1 - d/2
+	}
+	return 1 / (1 + d)
+}
+
+func (v *DbVectorSet) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * v.m
+	}
+	return v.m
+}
+
+// randomLevel draws the top layer of a new node, with the probability of each further layer being 1/m
+func (v *DbVectorSet) randomLevel() int {
+	level := 0
+	for level < vsetMaxLevel && v.rand.Float64() < 1/float64(v.m) {
+		level++
+	}
+	return level
+}
+
+type vsetCandidate struct {
+	node *vsetNode
+	dist float64
+}
+
+func cmpVsetCandidates(a, b vsetCandidate) int {
+	return cmp.Or(cmp.Compare(a.dist, b.dist), cmp.Compare(a.node.element, b.node.element))
+}
+
+// vsetQueue is a heap of candidates, by increasing distance, or decreasing distance if far is set
+type vsetQueue struct {
+	items []vsetCandidate
+	far   bool
+}
+
+var _ heap.Interface = (*vsetQueue)(nil)
+
+func (q *vsetQueue) Len() int { return len(q.items) }
+func (q *vsetQueue) Less(i, j int) bool {
+	return (cmpVsetCandidates(q.items[i], q.items[j]) < 0) != q.far
+}
+func (q *vsetQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *vsetQueue) Push(x any)    { q.items = append(q.items, x.(vsetCandidate)) }
+func (q *vsetQueue) Pop() any {
+	x := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return x
+}
+
+// searchLayer returns up to ef nodes of a layer closest to query, by increasing distance, searching greedily from entries.
+// Only nodes accepted by filter are returned, but the search goes through every node, visiting at most maxVisits nodes unless it is 0.
+func (v *DbVectorSet) searchLayer(query vsetVector, entries []*vsetNode, ef, level int, filter vsetExpr, maxVisits int) []vsetCandidate {
+	accept := func(n *vsetNode) bool {
+		return filter == nil || n.parsed != nil && vsetTest(filter, n.parsed)
+	}
+	visited := map[*vsetNode]bool{}
+	candidates := &vsetQueue{}
+	results := &vsetQueue{far: true}
+	for _, e := range entries {
+		visited[e] = true
+		c := vsetCandidate{node: e, dist: v.distance(query, e.vec)}
+		heap.Push(candidates, c)
+		if accept(e) {
+			heap.Push(results, c)
+		}
+	}
+search:
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(vsetCandidate)
+		if results.Len() >= ef && c.dist > results.items[0].dist {
+			break
+		}
+		for _, n := range c.node.links[level] {
+			if visited[n] {
+				continue
+			}
+			if maxVisits > 0 && len(visited) >= maxVisits {
+				break search
+			}
+			visited[n] = true
+			nc := vsetCandidate{node: n, dist: v.distance(query, n.vec)}
+			if results.Len() < ef || nc.dist < results.items[0].dist {
+				heap.Push(candidates, nc)
+				if accept(n) {
+					heap.Push(results, nc)
+					if results.Len() > ef {
+						heap.Pop(results)
+					}
+				}
+			}
+		}
+	}
+	slices.SortFunc(results.items, cmpVsetCandidates)
+	return results.items
+}
+
+// descend searches greedily for the node closest to query from the top layer down to the layer above level
+func (v *DbVectorSet) descend(query vsetVector, level int) *vsetNode {
+	cur := v.entry
+	for l := v.maxLevel; l > level; l-- {
+		cur = v.searchLayer(query, []*vsetNode{cur}, 1, l, nil, 0)[0].node
+	}
+	return cur
+}
+
+// link links a and b in a layer, pruning the farthest neighbours of either if it has too many
+func (v *DbVectorSet) link(a, b *vsetNode, level int) {
+	a.links[level] = append(a.links[level], b)
+	b.links[level] = append(b.links[level], a)
+	for _, n := range []*vsetNode{a, b} {
+		if len(n.links[level]) <= v.maxLinks(level) {
+			continue
+		}
+		slices.SortFunc(n.links[level], func(x, y *vsetNode) int {
+			return cmpVsetCandidates(vsetCandidate{node: x, dist: v.distance(n.vec, x.vec)}, vsetCandidate{node: y, dist: v.distance(n.vec, y.vec)})
+		})
+		pruned := n.links[level][v.maxLinks(level)]
+		n.links[level] = n.links[level][:v.maxLinks(level)]
+		pruned.unlink(n, level)
+	}
+}
+
+func (n *vsetNode) unlink(other *vsetNode, level int) {
+	n.links[level] = slices.DeleteFunc(n.links[level], func(x *vsetNode) bool { return x == other })
+}
+
+func (v *DbVectorSet) insert(n *vsetNode) {
+	level := v.randomLevel()
+	n.links = make([][]*vsetNode, level+1)
+	v.nodes[n.element] = n
+	if v.entry == nil {
+		v.entry, v.maxLevel = n, level
+		return
+	}
+	cur := v.descend(n.vec, level)
+	for l := min(level, v.maxLevel); l >= 0; l-- {
+		candidates := v.searchLayer(n.vec, []*vsetNode{cur}, v.ef, l, nil, 0)
+		for _, c := range candidates[:min(len(candidates), v.m)] {
+			v.link(n, c.node, l)
+		}
+		cur = candidates[0].node
+	}
+	if level > v.maxLevel {
+		v.entry, v.maxLevel = n, level
+	}
+}
+
+// remove removes a node, linking its former neighbours in each layer to one another, closest first, while they have room
+func (v *DbVectorSet) remove(n *vsetNode) {
+	delete(v.nodes, n.element)
+	for l, neighbours := range n.links {
+		for _, a := range neighbours {
+			a.unlink(n, l)
+		}
+		for _, a := range neighbours {
+			others := make([]vsetCandidate, 0, len(neighbours))
+			for _, b := range neighbours {
+				if b != a && !slices.Contains(a.links[l], b) {
+					others = append(others, vsetCandidate{node: b, dist: v.distance(a.vec, b.vec)})
+				}
+			}
+			slices.SortFunc(others, cmpVsetCandidates)
+			for _, c := range others {
+				if len(a.links[l]) >= v.maxLinks(l) {
+					break
+				}
+				if len(c.node.links[l]) < v.maxLinks(l) {
+					v.link(a, c.node, l)
+				}
+			}
+		}
+	}
+	if v.entry != n {
+		return
+	}
+	v.entry, v.maxLevel = nil, 0
+	for _, other := range v.nodes {
+		if v.entry == nil || len(other.links)-1 > v.maxLevel ||
+			len(other.links)-1 == v.maxLevel && other.element < v.entry.element {
+			v.entry, v.maxLevel = other, len(other.links)-1
+		}
+	}
+}
+
+// search returns up to count elements closest to query, exploring ef candidates. With a filter, only elements whose attributes
+// match it are returned, visiting at most filterEF nodes. If truth is set, every element is compared instead.
+func (v *DbVectorSet) search(query vsetVector, count, ef int, filter vsetExpr, filterEF int, truth bool) []vsetCandidate {
+	var res []vsetCandidate
+	switch {
+	case v.entry == nil:
+	case truth:
+		for _, n := range v.nodes {
+			if filter == nil || n.parsed != nil && vsetTest(filter, n.parsed) {
+				res = append(res, vsetCandidate{node: n, dist: v.distance(query, n.vec)})
+			}
+		}
+		slices.SortFunc(res, cmpVsetCandidates)
+	default:
+		maxVisits := 0
+		if filter != nil {
+			maxVisits = filterEF
+		}
+		res = v.searchLayer(query, []*vsetNode{v.descend(query, 0)}, max(ef, count), 0, filter, maxVisits)
+	}
+	return res[:min(len(res), count)]
+}
+
+// unsafeGetVset returns the vector set stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetVset(key string) (*DbVectorSet, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	vs, ok := v.(*DbVectorSet)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return vs, nil
+}
+
+// parseVsetAttributes parses the attributes of an element, which must be a JSON object, or empty to clear them
+func parseVsetAttributes(attrs string) (*jsonObject, error) {
+	if attrs == "" {
+		return nil, nil
+	}
+	parsed, err := parseJSON(attrs)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := parsed.(*jsonObject)
+	if !ok {
+		return nil, errors.New("ERR attributes must be a JSON object")
+	}
+	return obj, nil
+}
+
+// Vector set operations
+
+// Vadd adds element with the given vector to the set at key, creating it with opts if there is none, or updates its vector if it exists.
+// The attributes of the element are set if attrs is not nil. It returns whether the element was added.
+func Vadd(key string, vec []float32, element string, attrs *string, opts VsetOptions) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil {
+		return false, err
+	}
+	var parsed *jsonObject
+	if attrs != nil {
+		if parsed, err = parseVsetAttributes(*attrs); err != nil {
+			return false, err
+		}
+	}
+	if vs == nil {
+		vs = newDbVectorSet(len(vec), opts)
+		unsafeSet(key, vs)
+	}
+	switch {
+	case len(vec) != vs.inputDim:
+		return false, errorVsetDimMismatch(len(vec), vs.inputDim)
+	case opts.Reduce > 0 && opts.Reduce != vs.dim:
+		return false, errorVsetDimMismatch(opts.Reduce, vs.dim)
+	case opts.Quant != "" && opts.Quant != vs.quant:
+		return false, ErrorVsetQuantMismatch
+	case opts.Metric != "" && opts.Metric != vs.metric:
+		return false, ErrorVsetMetricMismatch
+	}
+	prepared, norm := vs.prepare(vec)
+	n := &vsetNode{element: element, vec: vs.quantize(prepared), norm: norm}
+	old, exists := vs.nodes[element]
+	if exists {
+		n.attrs, n.parsed = old.attrs, old.parsed
+		vs.remove(old)
+	}
+	if attrs != nil {
+		n.attrs, n.parsed = *attrs, parsed
+	}
+	vs.insert(n)
+	return !exists, nil
+}
+
+// VsimQuery selects the elements returned by VSIM, which are similar to either Element or Vector
+type VsimQuery struct {
+	Element   string
+	Vector    []float32
+	Count     int
+	EF        int
+	Filter    string
+	FilterEF  int
+	Truth     bool
+	WithAttrs bool
+}
+
+// VsimResult is an element similar to the query of VSIM, with its score and attributes, which may be nil
+type VsimResult struct {
+	Element string
+	Score   float64
+	Attrs   *string
+}
+
+// Vsim returns the elements of the set at key most similar to the query, by decreasing similarity
+func Vsim(key string, q VsimQuery) ([]VsimResult, error) {
+	filter, err := compileVsetFilter(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil || vs == nil {
+		return []VsimResult{}, err
+	}
+	var query vsetVector
+	if q.Vector != nil {
+		if len(q.Vector) != vs.inputDim {
+			return nil, errorVsetDimMismatch(len(q.Vector), vs.inputDim)
+		}
+		prepared, _ := vs.prepare(q.Vector)
+		query = vsetVector{f: prepared}
+	} else {
+		n, ok := vs.nodes[q.Element]
+		if !ok {
+			return nil, ErrorVsetNoElement
+		}
+		query = n.vec
+	}
+	candidates := vs.search(query, q.Count, q.EF, filter, q.FilterEF, q.Truth)
+	res := make([]VsimResult, len(candidates))
+	for i, c := range candidates {
+		res[i] = VsimResult{Element: c.node.element, Score: vs.similarity(c.dist)}
+		if c.node.parsed != nil {
+			attrs := c.node.attrs
+			res[i].Attrs = &attrs
+		}
+	}
+	return res, nil
+}
+
+// Vrem removes element from the set at key, deleting the set once it is empty, and returns whether it was removed
+func Vrem(key, element string) (bool, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil || vs == nil {
+		return false, err
+	}
+	n, ok := vs.nodes[element]
+	if !ok {
+		return false, nil
+	}
+	vs.remove(n)
+	if len(vs.nodes) == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return true, nil
+}
+
+func Vcard(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil || vs == nil {
+		return 0, err
+	}
+	return int64(len(vs.nodes)), nil
+}
+
+// Vdim returns the dimension of the vectors of the set at key, after projection. It returns ErrorNone if there is no such set.
+func Vdim(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil {
+		return 0, err
+	}
+	if vs == nil {
+		return 0, ErrorNone
+	}
+	return int64(vs.dim), nil
+}
+
+// VsetEmbedding is the vector of an element as stored, as with VEMB. Vector is scaled back to its original norm,
+// while Raw holds the stored components, which are int8 scaled by Scale with Q8.
+type VsetEmbedding struct {
+	Vector []float32
+	Quant  string
+	Raw    []byte
+	Norm   float32
+	Scale  float32
+}
+
+// Vemb returns the vector of element in the set at key, or ErrorNone if there is no such set or element
+func Vemb(key, element string) (VsetEmbedding, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil {
+		return VsetEmbedding{}, err
+	}
+	if vs == nil {
+		return VsetEmbedding{}, ErrorNone
+	}
+	n, ok := vs.nodes[element]
+	if !ok {
+		return VsetEmbedding{}, ErrorNone
+	}
+	res := VsetEmbedding{Quant: vs.quant, Norm: n.norm, Scale: n.vec.scale}
+	for i := range vs.dim {
+		res.Vector = append(res.Vector, n.vec.at(i)*n.norm)
+	}
+	if n.vec.q != nil {
+		for _, x := range n.vec.q {
+			res.Raw = append(res.Raw, byte(x))
+		}
+	} else {
+		for _, x := range n.vec.f {
+			b := math.Float32bits(x)
+			res.Raw = append(res.Raw, byte(b), byte(b>>8), byte(b>>16), byte(b>>24))
+		}
+	}
+	return res, nil
+}
+
+// Vsetattr sets the attributes of element in the set at key, clearing them if attrs is empty, and returns whether the element exists
+func Vsetattr(key, element, attrs string) (bool, error) {
+	parsed, err := parseVsetAttributes(attrs)
+	if err != nil {
+		return false, err
+	}
+	LockDbMu()
+	defer UnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil || vs == nil {
+		return false, err
+	}
+	n, ok := vs.nodes[element]
+	if !ok {
+		return false, nil
+	}
+	n.attrs, n.parsed = attrs, parsed
+	return true, nil
+}
+
+// Vgetattr returns the attributes of element in the set at key, or ErrorNone if there is no such set or element, or it has no attributes
+func Vgetattr(key, element string) (string, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil {
+		return "", err
+	}
+	if vs == nil {
+		return "", ErrorNone
+	}
+	n, ok := vs.nodes[element]
+	if !ok || n.parsed == nil {
+		return "", ErrorNone
+	}
+	return n.attrs, nil
+}
+
+// VsetInfo describes a vector set, as with VINFO
+type VsetInfo struct {
+	Quant      string
+	Metric     string
+	M          int64
+	Dim        int64
+	InputDim   int64
+	Size       int64
+	MaxLevel   int64
+	AttrsCount int64
+}
+
+// Vinfo returns ErrorNone if there is no such set
+func Vinfo(key string) (VsetInfo, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	vs, err := unsafeGetVset(key)
+	if err != nil {
+		return VsetInfo{}, err
+	}
+	if vs == nil {
+		return VsetInfo{}, ErrorNone
+	}
+	info := VsetInfo{
+		Quant:    vs.quant,
+		Metric:   vs.metric,
+		M:        int64(vs.m),
+		Dim:      int64(vs.dim),
+		InputDim: int64(vs.inputDim),
+		Size:     int64(len(vs.nodes)),
+		MaxLevel: int64(vs.maxLevel),
+	}
+	for _, n := range vs.nodes {
+		if n.parsed != nil {
+			info.AttrsCount++
+		}
+	}
+	return info, nil
+}
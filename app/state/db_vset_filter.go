@@ -0,0 +1,397 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrorVsetFilterSyntax = errors.New("ERR syntax error in FILTER expression")
+
+// vsetExpr is a compiled FILTER expression of VSIM, evaluated against the attributes of an element. It evaluates to a JSON value,
+// or fails if it selects a missing attribute or applies an operator to values of the wrong type, in which case the element is left out.
+type vsetExpr interface {
+	eval(attrs *jsonObject) (any, bool)
+}
+
+type vsetLiteral struct{ value any }
+
+// vsetSelector selects a top-level attribute, as with .name
+type vsetSelector struct{ name string }
+
+// vsetList is a list of expressions, as with [1, "a"], which evaluates to a JSON array
+type vsetList []vsetExpr
+
+type vsetUnary struct {
+	op   string
+	expr vsetExpr
+}
+
+type vsetBinary struct {
+	op          string
+	left, right vsetExpr
+}
+
+// vsetTest reports whether e evaluates to a truthy value: true, a non-zero number, or a non-empty string or array
+func vsetTest(e vsetExpr, attrs *jsonObject) bool {
+	v, ok := e.eval(attrs)
+	if !ok {
+		return false
+	}
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case *jsonArray:
+		return len(v.elems) > 0
+	}
+	x, ok := jsonNumber(v)
+	return ok && x != 0
+}
+
+func (e vsetLiteral) eval(*jsonObject) (any, bool) {
+	return e.value, true
+}
+
+func (e vsetSelector) eval(attrs *jsonObject) (any, bool) {
+	v, ok := attrs.values[e.name]
+	return v, ok
+}
+
+func (e vsetList) eval(attrs *jsonObject) (any, bool) {
+	res := &jsonArray{elems: make([]any, len(e))}
+	for i, elem := range e {
+		v, ok := elem.eval(attrs)
+		if !ok {
+			return nil, false
+		}
+		res.elems[i] = v
+	}
+	return res, true
+}
+
+func (e vsetUnary) eval(attrs *jsonObject) (any, bool) {
+	if e.op == "not" {
+		return !vsetTest(e.expr, attrs), true
+	}
+	v, ok := e.expr.eval(attrs)
+	if !ok {
+		return nil, false
+	}
+	x, ok := jsonNumber(v)
+	return -x, ok
+}
+
+func (e vsetBinary) eval(attrs *jsonObject) (any, bool) {
+	switch e.op {
+	case "and":
+		return vsetTest(e.left, attrs) && vsetTest(e.right, attrs), true
+	case "or":
+		return vsetTest(e.left, attrs) || vsetTest(e.right, attrs), true
+	}
+	left, ok := e.left.eval(attrs)
+	if !ok {
+		return nil, false
+	}
+	right, ok := e.right.eval(attrs)
+	if !ok {
+		return nil, false
+	}
+	if e.op == "in" {
+		switch r := right.(type) {
+		case *jsonArray:
+			for _, elem := range r.elems {
+				if c, ok := compareJSON(left, elem); ok && c == 0 {
+					return true, true
+				}
+			}
+			return false, true
+		case string:
+			s, ok := left.(string)
+			return ok && strings.Contains(r, s), ok
+		}
+		return nil, false
+	}
+	switch e.op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		c, ok := compareJSON(left, right)
+		switch {
+		case e.op == "==":
+			return ok && c == 0, true
+		case e.op == "!=":
+			return !ok || c != 0, true
+		case !ok:
+			return nil, false
+		case e.op == "<":
+			return c < 0, true
+		case e.op == "<=":
+			return c <= 0, true
+		case e.op == ">":
+			return c > 0, true
+		}
+		return c >= 0, true
+	}
+	x, ok := jsonNumber(left)
+	if !ok {
+		return nil, false
+	}
+	y, ok := jsonNumber(right)
+	if !ok {
+		return nil, false
+	}
+	switch e.op {
+	case "+":
+		return x + y, true
+	case "-":
+		return x - y, true
+	case "*":
+		return x * y, true
+	case "/":
+		return x / y, true
+	case "%":
+		return math.Mod(x, y), true
+	case "**":
+		return math.Pow(x, y), true
+	}
+	return nil, false
+}
+
+// vsetFilterCompiler compiles FILTER expressions, in order of increasing precedence:
+//
+//	or             = and { ("or" | "||") and }
+//	and            = not { ("and" | "&&") not }
+//	not            = ("not" | "!") not | comparison
+//	comparison     = additive [ ("==" | "!=" | "<=" | ">=" | "<" | ">" | "in") additive ]
+//	additive       = multiplicative { ("+" | "-") multiplicative }
+//	multiplicative = power { ("*" | "/" | "%") power }
+//	power          = unary [ "**" power ]
+//	unary          = "-" unary | "(" or ")" | "[" [ or { "," or } ] "]" | string | number | true | false | null | "." name
+type vsetFilterCompiler struct {
+	s   string
+	pos int
+}
+
+// compileVsetFilter compiles a FILTER expression, which is nil if s is empty
+func compileVsetFilter(s string) (vsetExpr, error) {
+	if s == "" {
+		return nil, nil
+	}
+	c := &vsetFilterCompiler{s: s}
+	e, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if c.skipSpace(); c.pos < len(c.s) {
+		return nil, ErrorVsetFilterSyntax
+	}
+	return e, nil
+}
+
+func isVsetNameByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func (c *vsetFilterCompiler) skipSpace() {
+	for c.pos < len(c.s) && strings.IndexByte(" \t\n\r", c.s[c.pos]) >= 0 {
+		c.pos++
+	}
+}
+
+// accept consumes the first of ops found after any spaces, where ops made of letters must be whole words
+func (c *vsetFilterCompiler) accept(ops ...string) (string, bool) {
+	c.skipSpace()
+	for _, op := range ops {
+		if !strings.HasPrefix(c.s[c.pos:], op) {
+			continue
+		}
+		end := c.pos + len(op)
+		if isVsetNameByte(op[0]) && end < len(c.s) && isVsetNameByte(c.s[end]) {
+			continue
+		}
+		c.pos = end
+		return op, true
+	}
+	return "", false
+}
+
+// binary parses a left-associative chain of operands separated by ops, where op aliases such as && map to their names
+func (c *vsetFilterCompiler) binary(operand func() (vsetExpr, error), ops map[string]string, order ...string) (vsetExpr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := c.accept(order...)
+		if !ok {
+			return left, nil
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = vsetBinary{op: ops[op], left: left, right: right}
+	}
+}
+
+func (c *vsetFilterCompiler) or() (vsetExpr, error) {
+	return c.binary(c.and, map[string]string{"or": "or", "||": "or"}, "or", "||")
+}
+
+func (c *vsetFilterCompiler) and() (vsetExpr, error) {
+	return c.binary(c.not, map[string]string{"and": "and", "&&": "and"}, "and", "&&")
+}
+
+func (c *vsetFilterCompiler) not() (vsetExpr, error) {
+	c.skipSpace()
+	if !strings.HasPrefix(c.s[c.pos:], "!=") {
+		if _, ok := c.accept("not", "!"); ok {
+			e, err := c.not()
+			if err != nil {
+				return nil, err
+			}
+			return vsetUnary{op: "not", expr: e}, nil
+		}
+	}
+	left, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := c.accept("==", "!=", "<=", ">=", "<", ">", "in")
+	if !ok {
+		return left, nil
+	}
+	right, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+	return vsetBinary{op: op, left: left, right: right}, nil
+}
+
+func (c *vsetFilterCompiler) additive() (vsetExpr, error) {
+	return c.binary(c.multiplicative, map[string]string{"+": "+", "-": "-"}, "+", "-")
+}
+
+func (c *vsetFilterCompiler) multiplicative() (vsetExpr, error) {
+	left, err := c.power()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := c.accept("*", "/", "%")
+		if !ok {
+			return left, nil
+		}
+		right, err := c.power()
+		if err != nil {
+			return nil, err
+		}
+		left = vsetBinary{op: op, left: left, right: right}
+	}
+}
+
+func (c *vsetFilterCompiler) power() (vsetExpr, error) {
+	left, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := c.accept("**"); !ok {
+		return left, nil
+	}
+	right, err := c.power()
+	if err != nil {
+		return nil, err
+	}
+	return vsetBinary{op: "**", left: left, right: right}, nil
+}
+
+func (c *vsetFilterCompiler) unary() (vsetExpr, error) {
+	c.skipSpace()
+	if c.pos >= len(c.s) {
+		return nil, ErrorVsetFilterSyntax
+	}
+	switch b := c.s[c.pos]; {
+	case b == '-':
+		c.pos++
+		e, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+		return vsetUnary{op: "-", expr: e}, nil
+	case b == '(':
+		c.pos++
+		e, err := c.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.accept(")"); !ok {
+			return nil, ErrorVsetFilterSyntax
+		}
+		return e, nil
+	case b == '[':
+		c.pos++
+		var list vsetList
+		if _, ok := c.accept("]"); ok {
+			return list, nil
+		}
+		for {
+			e, err := c.or()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, e)
+			if _, ok := c.accept("]"); ok {
+				return list, nil
+			}
+			if _, ok := c.accept(","); !ok {
+				return nil, ErrorVsetFilterSyntax
+			}
+		}
+	case b == '\'' || b == '"':
+		return c.quoted(b)
+	case b == '.':
+		c.pos++
+		start := c.pos
+		for c.pos < len(c.s) && isVsetNameByte(c.s[c.pos]) {
+			c.pos++
+		}
+		if c.pos == start {
+			return nil, ErrorVsetFilterSyntax
+		}
+		return vsetSelector{name: c.s[start:c.pos]}, nil
+	}
+	for word, value := range map[string]any{"true": true, "false": false, "null": nil} {
+		if _, ok := c.accept(word); ok {
+			return vsetLiteral{value: value}, nil
+		}
+	}
+	start := c.pos
+	for c.pos < len(c.s) && (strings.IndexByte("0123456789.eE", c.s[c.pos]) >= 0 ||
+		c.pos > start && strings.IndexByte("eE", c.s[c.pos-1]) >= 0 && strings.IndexByte("+-", c.s[c.pos]) >= 0) {
+		c.pos++
+	}
+	x, err := strconv.ParseFloat(c.s[start:c.pos], 64)
+	if err != nil {
+		return nil, ErrorVsetFilterSyntax
+	}
+	return vsetLiteral{value: x}, nil
+}
+
+// quoted parses a string quoted with q, in which a backslash escapes the following character
+func (c *vsetFilterCompiler) quoted(q byte) (vsetExpr, error) {
+	var b strings.Builder
+	for c.pos++; c.pos < len(c.s); c.pos++ {
+		switch c.s[c.pos] {
+		case q:
+			c.pos++
+			return vsetLiteral{value: b.String()}, nil
+		case '\\':
+			if c.pos+1 < len(c.s) {
+				c.pos++
+			}
+		}
+		b.WriteByte(c.s[c.pos])
+	}
+	return nil, ErrorVsetFilterSyntax
+}
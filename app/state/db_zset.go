@@ -0,0 +1,632 @@
+package state
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+const (
+	// zsetMaxLevel is the maximum number of levels of a skiplist node, which is plenty for 2^64 elements with zsetP = 1/4
+	zsetMaxLevel = 32
+	// zsetP is the probability that a skiplist node is promoted to the next level
+	zsetP = 0.25
+)
+
+var (
+	ErrorScoreNaN = errors.New("ERR resulting score is not a number (NaN)")
+)
+
+// ZMember is a member of a sorted set together with its score
+type ZMember struct {
+	Member string
+	Score  float64
+}
+
+type zsetLevel struct {
+	forward *zsetNode
+	// span is the number of nodes crossed by following forward, which allows ranks to be computed along the search path
+	span int
+}
+
+type zsetNode struct {
+	ZMember
+	backward *zsetNode
+	level    []zsetLevel
+}
+
+// less reports whether n sorts before the given score and member, ordering by score and then lexicographically by member
+func (n *zsetNode) less(score float64, member string) bool {
+	return n.Score < score || (n.Score == score && n.Member < member)
+}
+
+// DbZSet is a sorted set, stored as a skiplist ordered by score and member alongside a map from members to their scores.
+// The skiplist nodes track the span of each of their forward links, so that ranks can be computed in O(log n).
+type DbZSet struct {
+	dbExpiry
+	header *zsetNode
+	tail   *zsetNode
+	level  int
+	scores *dict[float64]
+}
+
+var _ DbValue = (*DbZSet)(nil)
+
+func NewDbZSet() *DbZSet {
+	return &DbZSet{
+		header: &zsetNode{level: make([]zsetLevel, zsetMaxLevel)},
+		level:  1,
+		scores: newDict[float64](0),
+	}
+}
+
+func (v *DbZSet) Type() string {
+	return "zset"
+}
+
+func (v *DbZSet) Len() int {
+	return v.scores.Len()
+}
+
+func (v *DbZSet) Score(member string) (float64, bool) {
+	score, ok := v.scores.Get(member)
+	return score, ok
+}
+
+func randomZsetLevel() int {
+	level := 1
+	for level < zsetMaxLevel && rand.Float64() < zsetP {
+		level++
+	}
+	return level
+}
+
+func (v *DbZSet) insert(score float64, member string) {
+	var update [zsetMaxLevel]*zsetNode
+	var rank [zsetMaxLevel]int
+	x := v.header
+	for i := v.level - 1; i >= 0; i-- {
+		if i < v.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+	level := randomZsetLevel()
+	if level > v.level {
+		for i := v.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = v.header
+			update[i].level[i].span = v.Len()
+		}
+		v.level = level
+	}
+	n := &zsetNode{ZMember: ZMember{Member: member, Score: score}, level: make([]zsetLevel, level)}
+	for i := 0; i < level; i++ {
+		n.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = n
+		n.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < v.level; i++ {
+		update[i].level[i].span++
+	}
+	if update[0] != v.header {
+		n.backward = update[0]
+	}
+	if n.level[0].forward != nil {
+		n.level[0].forward.backward = n
+	} else {
+		v.tail = n
+	}
+}
+
+func (v *DbZSet) delete(score float64, member string) {
+	var update [zsetMaxLevel]*zsetNode
+	x := v.header
+	for i := v.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+	n := x.level[0].forward
+	for i := 0; i < v.level; i++ {
+		if update[i].level[i].forward == n {
+			update[i].level[i].span += n.level[i].span - 1
+			update[i].level[i].forward = n.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+	if n.level[0].forward != nil {
+		n.level[0].forward.backward = n.backward
+	} else {
+		v.tail = n.backward
+	}
+	for v.level > 1 && v.header.level[v.level-1].forward == nil {
+		v.level--
+	}
+}
+
+// Add sets the score of member, returning true if it is new
+func (v *DbZSet) Add(member string, score float64) bool {
+	current, ok := v.scores.Get(member)
+	if ok {
+		if current == score {
+			return false
+		}
+		v.delete(current, member)
+	}
+	v.insert(score, member)
+	v.scores.Set(member, score)
+	return !ok
+}
+
+// Remove removes member, returning true if it was present
+func (v *DbZSet) Remove(member string) bool {
+	score, ok := v.scores.Get(member)
+	if !ok {
+		return false
+	}
+	v.delete(score, member)
+	v.scores.Delete(member)
+	return true
+}
+
+// seek returns the last node satisfying below, or the header if there is none, along with the number of nodes satisfying it.
+// below must hold for a prefix of the nodes.
+func (v *DbZSet) seek(below func(n *zsetNode) bool) (*zsetNode, int) {
+	x := v.header
+	rank := 0
+	for i := v.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && below(x.level[i].forward) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+	}
+	return x, rank
+}
+
+// Rank returns the 0-based rank of member in ascending order
+func (v *DbZSet) Rank(member string) (int, bool) {
+	score, ok := v.scores.Get(member)
+	if !ok {
+		return 0, false
+	}
+	_, rank := v.seek(func(n *zsetNode) bool { return !(score < n.Score || (score == n.Score && member < n.Member)) })
+	return rank - 1, true
+}
+
+// byRank returns the node of the given 0-based rank, which must be in range
+func (v *DbZSet) byRank(rank int) *zsetNode {
+	x := v.header
+	traversed := 0
+	for i := v.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank+1 {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+	}
+	return x
+}
+
+// ZScoreRange is a range of scores, each end of which may be exclusive
+type ZScoreRange struct {
+	Min, Max     float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+func (r ZScoreRange) aboveMin(score float64) bool {
+	if r.MinExclusive {
+		return score > r.Min
+	}
+	return score >= r.Min
+}
+
+func (r ZScoreRange) belowMax(score float64) bool {
+	if r.MaxExclusive {
+		return score < r.Max
+	}
+	return score <= r.Max
+}
+
+// ZLexBound is an end of a lexicographical range of members, as in "[a", "(a", "-" or "+".
+// Inf is -1 for "-", which precedes every member, and 1 for "+", which follows every member.
+type ZLexBound struct {
+	Value     string
+	Exclusive bool
+	Inf       int
+}
+
+// ZLexRange is a lexicographical range of members, which is only meaningful for sorted sets whose members all have the same score
+type ZLexRange struct {
+	Min, Max ZLexBound
+}
+
+func (r ZLexRange) aboveMin(member string) bool {
+	switch {
+	case r.Min.Inf != 0:
+		return r.Min.Inf < 0
+	case r.Min.Exclusive:
+		return member > r.Min.Value
+	}
+	return member >= r.Min.Value
+}
+
+func (r ZLexRange) belowMax(member string) bool {
+	switch {
+	case r.Max.Inf != 0:
+		return r.Max.Inf > 0
+	case r.Max.Exclusive:
+		return member < r.Max.Value
+	}
+	return member <= r.Max.Value
+}
+
+// ZRangeBy is the kind of range selected by a ZRangeSpec
+type ZRangeBy int
+
+const (
+	ZRangeByRank ZRangeBy = iota
+	ZRangeByScore
+	ZRangeByLex
+)
+
+// ZRangeSpec selects a range of a sorted set as with ZRANGE. Start and Stop are used when ranging by rank, and Score or Lex otherwise.
+// Rev selects the range in descending order, with ranks counted from the highest score. Offset and Count (unlimited if negative) limit the
+// elements returned when ranging by score or lex.
+type ZRangeSpec struct {
+	By          ZRangeBy
+	Start, Stop int64
+	Score       ZScoreRange
+	Lex         ZLexRange
+	Rev         bool
+	Offset      int64
+	Count       int64
+}
+
+// bounds returns the 0-based ascending ranks of the first and last nodes within the score or lex range of spec
+func (v *DbZSet) bounds(spec ZRangeSpec) (int, int) {
+	_, first := v.seek(func(n *zsetNode) bool {
+		if spec.By == ZRangeByLex {
+			return !spec.Lex.aboveMin(n.Member)
+		}
+		return !spec.Score.aboveMin(n.Score)
+	})
+	_, last := v.seek(func(n *zsetNode) bool {
+		if spec.By == ZRangeByLex {
+			return spec.Lex.belowMax(n.Member)
+		}
+		return spec.Score.belowMax(n.Score)
+	})
+	return first, last - 1
+}
+
+// Range returns the members selected by spec
+func (v *DbZSet) Range(spec ZRangeSpec) []ZMember {
+	var first, last int
+	if spec.By == ZRangeByRank {
+		start, stop, ok := normalizeRange(clampInt(spec.Start), clampInt(spec.Stop), v.Len())
+		if !ok {
+			return []ZMember{}
+		}
+		if spec.Rev {
+			start, stop = v.Len()-1-stop, v.Len()-1-start
+		}
+		first, last = start, stop
+	} else {
+		first, last = v.bounds(spec)
+		if spec.Offset < 0 {
+			return []ZMember{}
+		}
+		if spec.Rev {
+			last -= clampInt(spec.Offset)
+			if spec.Count >= 0 && int64(last-first) >= spec.Count {
+				first = last - int(spec.Count) + 1
+			}
+		} else {
+			first += clampInt(spec.Offset)
+			if spec.Count >= 0 && int64(last-first) >= spec.Count {
+				last = first + int(spec.Count) - 1
+			}
+		}
+	}
+	if first > last {
+		return []ZMember{}
+	}
+	res := make([]ZMember, 0, last-first+1)
+	if spec.Rev {
+		for n := v.byRank(last); len(res) < cap(res); n = n.backward {
+			res = append(res, n.ZMember)
+		}
+	} else {
+		for n := v.byRank(first); len(res) < cap(res); n = n.level[0].forward {
+			res = append(res, n.ZMember)
+		}
+	}
+	return res
+}
+
+// Count returns the number of members within the score or lex range of spec
+func (v *DbZSet) Count(spec ZRangeSpec) int {
+	first, last := v.bounds(spec)
+	return max(last-first+1, 0)
+}
+
+// Random returns up to count distinct random members, or exactly -count members that may repeat if count is negative
+func (v *DbZSet) Random(count int64) []ZMember {
+	n := int64(v.Len())
+	if count < 0 {
+		var res []ZMember
+		for ; count < 0; count++ {
+			res = append(res, v.byRank(rand.Intn(int(n))).ZMember)
+		}
+		return res
+	}
+	members := v.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: -1})
+	if count >= n {
+		return members
+	}
+	// partial Fisher-Yates shuffle
+	for i := int64(0); i < count; i++ {
+		j := i + rand.Int63n(n-i)
+		members[i], members[j] = members[j], members[i]
+	}
+	return members[:count]
+}
+
+// unsafeGetZSet returns the sorted set stored at key, or nil if there is none. The caller must hold DbMu.
+func unsafeGetZSet(key string) (*DbZSet, error) {
+	v, ok := unsafeLookup(key, time.Now())
+	if !ok {
+		return nil, nil
+	}
+	z, ok := v.(*DbZSet)
+	if !ok {
+		return nil, ErrorWrongType
+	}
+	return z, nil
+}
+
+// ZaddOptions are the conditions under which ZADD and ZINCRBY update a member.
+// NX only adds new members and XX only updates existing ones, while GT and LT only update a member if its score increases or decreases respectively.
+type ZaddOptions struct {
+	NX, XX, GT, LT bool
+}
+
+// allows reports whether a member with the current score (if it exists) may be set to score under opts
+func (opts ZaddOptions) allows(current float64, exists bool, score float64) bool {
+	if exists {
+		return !opts.NX && (!opts.GT || score > current) && (!opts.LT || score < current)
+	}
+	return !opts.XX
+}
+
+// Sorted set operations
+
+// Zadd sets the scores of members subject to opts, returning the number of members added and the number of existing members whose score changed.
+// It also returns the commands effecting the pops of any clients blocked on key that were served as a result.
+func Zadd(key string, members []ZMember, opts ZaddOptions) (int64, int64, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if z == nil {
+		if opts.XX {
+			return 0, 0, nil, nil
+		}
+		z = NewDbZSet()
+		unsafeSet(key, z)
+	}
+	var added, changed int64
+	for _, m := range members {
+		current, exists := z.Score(m.Member)
+		if !opts.allows(current, exists, m.Score) {
+			continue
+		}
+		if !exists {
+			added++
+		} else if current != m.Score {
+			changed++
+		}
+		z.Add(m.Member, m.Score)
+	}
+	return added, changed, unsafeServeBlockers(key), nil
+}
+
+// Zincrby increments the score of member by incr subject to opts, returning the new score, or false if opts prevented the update.
+// It also returns the commands effecting the pops of any clients blocked on key that were served as a result.
+func Zincrby(key string, member string, incr float64, opts ZaddOptions) (float64, bool, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return 0, false, nil, err
+	}
+	var current float64
+	exists := false
+	if z != nil {
+		current, exists = z.Score(member)
+	}
+	score := current + incr
+	if math.IsNaN(score) {
+		return 0, false, nil, ErrorScoreNaN
+	}
+	if !opts.allows(current, exists, score) {
+		return 0, false, nil, nil
+	}
+	if z == nil {
+		z = NewDbZSet()
+		unsafeSet(key, z)
+	}
+	z.Add(member, score)
+	return score, true, unsafeServeBlockers(key), nil
+}
+
+func Zrem(key string, members []string) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+	var removed int64
+	for _, m := range members {
+		if z.Remove(m) {
+			removed++
+		}
+	}
+	if z.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return removed, nil
+}
+
+// Zremrange removes the members selected by spec, as with ZREMRANGEBYRANK, ZREMRANGEBYSCORE and ZREMRANGEBYLEX
+func Zremrange(key string, spec ZRangeSpec) (int64, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+	members := z.Range(spec)
+	for _, m := range members {
+		z.Remove(m.Member)
+	}
+	if z.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return int64(len(members)), nil
+}
+
+func Zrange(key string, spec ZRangeSpec) ([]ZMember, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if z == nil {
+		return []ZMember{}, nil
+	}
+	return z.Range(spec), nil
+}
+
+// Zrangestore stores the members of the sorted set at src selected by spec at dst, returning the number of members stored.
+// It also returns the commands effecting the pops of any clients blocked on dst that were served as a result.
+func Zrangestore(dst, src string, spec ZRangeSpec) (int64, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(src)
+	if err != nil {
+		return 0, nil, err
+	}
+	res := NewDbZSet()
+	if z != nil {
+		for _, m := range z.Range(spec) {
+			res.Add(m.Member, m.Score)
+		}
+	}
+	return unsafeStoreZSet(dst, res)
+}
+
+// unsafeStoreZSet stores z at dst, replacing any existing value, and serves any clients blocked on dst.
+// It returns the size of z and the commands effecting the pops of the clients served. The caller must hold DbMu.
+func unsafeStoreZSet(dst string, z *DbZSet) (int64, []resp.RESP, error) {
+	n := int64(z.Len())
+	if n == 0 {
+		unsafeDelete(dst, time.Now())
+		return 0, nil, nil
+	}
+	unsafeSet(dst, z)
+	return n, unsafeServeBlockers(dst), nil
+}
+
+// Zcount returns the number of members within the score or lex range of spec, as with ZCOUNT and ZLEXCOUNT
+func Zcount(key string, spec ZRangeSpec) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+	return int64(z.Count(spec)), nil
+}
+
+// Zrank returns the rank of member, counted from the highest score if rev is set, along with its score.
+// It returns ErrorNone if there is no such member.
+func Zrank(key string, member string, rev bool) (int64, float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	if z == nil {
+		return 0, 0, ErrorNone
+	}
+	rank, ok := z.Rank(member)
+	if !ok {
+		return 0, 0, ErrorNone
+	}
+	if rev {
+		rank = z.Len() - 1 - rank
+	}
+	score, _ := z.Score(member)
+	return int64(rank), score, nil
+}
+
+// Zmscore returns the scores of members, with nil for members that do not exist
+func Zmscore(key string, members []string) ([]*float64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*float64, len(members))
+	if z == nil {
+		return res, nil
+	}
+	for i, m := range members {
+		if score, ok := z.Score(m); ok {
+			res[i] = &score
+		}
+	}
+	return res, nil
+}
+
+func Zcard(key string) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return 0, err
+	}
+	return int64(z.Len()), nil
+}
+
+// Zrandmember returns ErrorNone if there is no such sorted set.
+func Zrandmember(key string, count int64) ([]ZMember, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if z == nil {
+		return nil, ErrorNone
+	}
+	return z.Random(count), nil
+}
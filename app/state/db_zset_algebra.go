@@ -0,0 +1,204 @@
+package state
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// ZAggregate is the way in which the scores of a member across multiple sorted sets are combined
+type ZAggregate int
+
+const (
+	ZAggregateSum ZAggregate = iota
+	ZAggregateMin
+	ZAggregateMax
+)
+
+func (agg ZAggregate) combine(a, b float64) float64 {
+	switch agg {
+	case ZAggregateMin:
+		return math.Min(a, b)
+	case ZAggregateMax:
+		return math.Max(a, b)
+	}
+	// the sum of infinities of opposite signs is taken to be 0
+	if sum := a + b; !math.IsNaN(sum) {
+		return sum
+	}
+	return 0
+}
+
+// zsetInput is an input to sorted set algebra, which may also be a plain set, whose members are taken to have a score of 1
+type zsetInput struct {
+	z *DbZSet
+	s *DbSet
+}
+
+func (in zsetInput) Len() int {
+	switch {
+	case in.z != nil:
+		return in.z.Len()
+	case in.s != nil:
+		return in.s.Len()
+	}
+	return 0
+}
+
+func (in zsetInput) Score(member string) (float64, bool) {
+	switch {
+	case in.z != nil:
+		return in.z.Score(member)
+	case in.s != nil:
+		return 1, in.s.Has(member)
+	}
+	return 0, false
+}
+
+func (in zsetInput) Members() []ZMember {
+	switch {
+	case in.z != nil:
+		return in.z.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: -1})
+	case in.s != nil:
+		members := in.s.Members()
+		res := make([]ZMember, len(members))
+		for i, m := range members {
+			res[i] = ZMember{Member: m, Score: 1}
+		}
+		return res
+	}
+	return nil
+}
+
+// unsafeGetZsetInputs returns the sorted sets or sets stored at keys, with missing keys taken to be empty. The caller must hold DbMu.
+func unsafeGetZsetInputs(keys []string) ([]zsetInput, error) {
+	inputs := make([]zsetInput, len(keys))
+	for i, key := range keys {
+		v, ok := unsafeLookup(key, time.Now())
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case *DbZSet:
+			inputs[i].z = v
+		case *DbSet:
+			inputs[i].s = v
+		default:
+			return nil, ErrorWrongType
+		}
+	}
+	return inputs, nil
+}
+
+// weightScore multiplies score by weight, taking the product of an infinity and 0 to be 0
+func weightScore(score, weight float64) float64 {
+	if product := score * weight; !math.IsNaN(product) {
+		return product
+	}
+	return 0
+}
+
+// unsafeZsetAlgebra computes op over the sorted sets at keys, with the scores of each multiplied by the corresponding weight (1 if weights is nil)
+// and combined by agg. The scores of SetOpDiff are those of the first sorted set. The caller must hold DbMu.
+func unsafeZsetAlgebra(keys []string, weights []float64, agg ZAggregate, op SetOp) (*DbZSet, error) {
+	inputs, err := unsafeGetZsetInputs(keys)
+	if err != nil {
+		return nil, err
+	}
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+	res := NewDbZSet()
+	switch op {
+	case SetOpInter:
+		// iterate over the smallest input, checking membership in the rest
+		smallest := 0
+		for i, in := range inputs {
+			if in.Len() < inputs[smallest].Len() {
+				smallest = i
+			}
+		}
+	members:
+		for _, m := range inputs[smallest].Members() {
+			var score float64
+			for i, in := range inputs {
+				s, ok := in.Score(m.Member)
+				if !ok {
+					continue members
+				}
+				if i == 0 {
+					score = weightScore(s, weight(i))
+				} else {
+					score = agg.combine(score, weightScore(s, weight(i)))
+				}
+			}
+			res.Add(m.Member, score)
+		}
+	case SetOpUnion:
+		for i, in := range inputs {
+			for _, m := range in.Members() {
+				score := weightScore(m.Score, weight(i))
+				if current, ok := res.Score(m.Member); ok {
+					score = agg.combine(current, score)
+				}
+				res.Add(m.Member, score)
+			}
+		}
+	case SetOpDiff:
+		for _, m := range inputs[0].Members() {
+			if !slices.ContainsFunc(inputs[1:], func(in zsetInput) bool { _, ok := in.Score(m.Member); return ok }) {
+				res.Add(m.Member, m.Score)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ZsetAlgebra computes op over the sorted sets at keys, as with ZINTER, ZUNION and ZDIFF
+func ZsetAlgebra(keys []string, weights []float64, agg ZAggregate, op SetOp) ([]ZMember, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	res, err := unsafeZsetAlgebra(keys, weights, agg, op)
+	if err != nil {
+		return nil, err
+	}
+	return res.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: -1}), nil
+}
+
+// ZsetAlgebraStore computes op over the sorted sets at keys and stores the result at dst, as with ZINTERSTORE, ZUNIONSTORE and ZDIFFSTORE.
+// It returns the size of the result and the commands effecting the pops of any clients blocked on dst that were served as a result.
+func ZsetAlgebraStore(dst string, keys []string, weights []float64, agg ZAggregate, op SetOp) (int64, []resp.RESP, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	res, err := unsafeZsetAlgebra(keys, weights, agg, op)
+	if err != nil {
+		return 0, nil, err
+	}
+	return unsafeStoreZSet(dst, res)
+}
+
+// Zintercard returns the size of the intersection of the sorted sets at keys, counting no further than limit unless it is 0
+func Zintercard(keys []string, limit int64) (int64, error) {
+	RLockDbMu()
+	defer RUnlockDbMu()
+	inputs, err := unsafeGetZsetInputs(keys)
+	if err != nil {
+		return 0, err
+	}
+	slices.SortFunc(inputs, func(a, b zsetInput) int { return a.Len() - b.Len() })
+	var n int64
+	for _, m := range inputs[0].Members() {
+		if limit != 0 && n >= limit {
+			break
+		}
+		if !slices.ContainsFunc(inputs[1:], func(in zsetInput) bool { _, ok := in.Score(m.Member); return !ok }) {
+			n++
+		}
+	}
+	return n, nil
+}
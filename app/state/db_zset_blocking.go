@@ -0,0 +1,67 @@
+package state
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/app/resp"
+)
+
+// ZPopReply encodes the response to a client that popped members from the sorted set at key.
+// It is supplied by the caller since the encoding of scores depends on the protocol of the client.
+type ZPopReply func(key string, popped []ZMember) resp.RESP
+
+// zsetPopper returns a blockPopper that pops up to count members with the lowest scores, or the highest if max is set, from a sorted set.
+// The pops are propagated as ZPOPMIN or ZPOPMAX.
+func zsetPopper(max bool, count int64, reply ZPopReply) blockPopper {
+	return func(key string) (resp.RESP, []resp.RESP, []string, error) {
+		z, err := unsafeGetZSet(key)
+		if err != nil || z == nil {
+			return nil, nil, nil, err
+		}
+		popped := unsafePopZSet(key, z, max, count)
+		return reply(key, popped), []resp.RESP{zsetPopCommand(key, max, count)}, nil, nil
+	}
+}
+
+func zsetPopCommand(key string, max bool, count int64) resp.RESP {
+	name := "ZPOPMIN"
+	if max {
+		name = "ZPOPMAX"
+	}
+	return resp.EncodeStringSlice([]string{name, key, strconv.FormatInt(count, 10)})
+}
+
+// unsafePopZSet pops up to count members with the lowest scores, or the highest if max is set, from the sorted set z stored at key.
+// The caller must hold DbMu.
+func unsafePopZSet(key string, z *DbZSet, max bool, count int64) []ZMember {
+	if count <= 0 {
+		return []ZMember{}
+	}
+	popped := z.Range(ZRangeSpec{By: ZRangeByRank, Start: 0, Stop: count - 1, Rev: max})
+	for _, m := range popped {
+		z.Remove(m.Member)
+	}
+	if z.Len() == 0 {
+		unsafeDelete(key, time.Now())
+	}
+	return popped
+}
+
+// Zpop pops up to count members with the lowest scores, or the highest if max is set, from the sorted set at key, as with ZPOPMIN and ZPOPMAX.
+func Zpop(key string, max bool, count int64) ([]ZMember, error) {
+	LockDbMu()
+	defer UnlockDbMu()
+	z, err := unsafeGetZSet(key)
+	if err != nil || z == nil {
+		return []ZMember{}, err
+	}
+	return unsafePopZSet(key, z, max, count), nil
+}
+
+// Bzmpop pops up to count members with the lowest scores, or the highest if max is set, from the first non-empty sorted set among keys,
+// as with BZPOPMIN, BZPOPMAX, BZMPOP and ZMPOP. The response to the client is encoded by reply.
+// It returns nil if no sorted set became non-empty in time.
+func Bzmpop(keys []string, max bool, count int64, timeout time.Duration, block bool, reply ZPopReply) (resp.RESP, error) {
+	return blockingOp(keys, zsetPopper(max, count, reply), timeout, block)
+}
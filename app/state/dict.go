@@ -0,0 +1,302 @@
+package state
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand"
+)
+
+// dict is a hash table of string keys with separate chaining, used in place of a Go map wherever a stable cursor is needed to iterate
+// over it, as with SCAN. It doubles in size once it holds as many entries as it has buckets, and halves once it is less than an eighth full.
+// Rather than moving every entry at once, it rehashes incrementally: while it is resizing, both the old and the new table are in use,
+// and each write moves a bucket of the old table over to the new one. Reads never rehash, so that they may run concurrently.
+type dict[V any] struct {
+	seed maphash.Seed
+	// tables[1] is nil unless the entries of tables[0] are being rehashed into it
+	tables [2]*dictTable[V]
+	// rehashIdx is the index of the next bucket of tables[0] to rehash
+	rehashIdx int
+}
+
+type dictTable[V any] struct {
+	// the number of buckets is a power of two, so that the bucket of a hash is given by its low bits
+	buckets []*dictEntry[V]
+	used    int
+}
+
+type dictEntry[V any] struct {
+	key   string
+	value V
+	next  *dictEntry[V]
+}
+
+const (
+	dictMinSize = 4
+	// dictRehashEmptyVisits is the number of empty buckets a rehash step may skip over before giving up, which bounds its cost
+	dictRehashEmptyVisits = 10
+)
+
+// newDict returns an empty dict with room for sizeHint entries
+func newDict[V any](sizeHint int) *dict[V] {
+	return &dict[V]{seed: maphash.MakeSeed(), tables: [2]*dictTable[V]{newDictTable[V](sizeHint)}}
+}
+
+func newDictTable[V any](n int) *dictTable[V] {
+	size := dictMinSize
+	for size < n {
+		size *= 2
+	}
+	return &dictTable[V]{buckets: make([]*dictEntry[V], size)}
+}
+
+func (t *dictTable[V]) mask() uint64 {
+	return uint64(len(t.buckets) - 1)
+}
+
+func (d *dict[V]) hash(key string) uint64 {
+	return maphash.String(d.seed, key)
+}
+
+func (d *dict[V]) isRehashing() bool {
+	return d.tables[1] != nil
+}
+
+func (d *dict[V]) Len() int {
+	n := d.tables[0].used
+	if d.isRehashing() {
+		n += d.tables[1].used
+	}
+	return n
+}
+
+// find returns the entry of key, or nil if there is none. While rehashing, the buckets of tables[0] already rehashed are empty,
+// so both tables can be searched in turn.
+func (d *dict[V]) find(key string) *dictEntry[V] {
+	h := d.hash(key)
+	for _, t := range d.tables {
+		if t == nil {
+			break
+		}
+		for e := t.buckets[h&t.mask()]; e != nil; e = e.next {
+			if e.key == key {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func (d *dict[V]) Get(key string) (V, bool) {
+	if e := d.find(key); e != nil {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Value returns the value of key, or the zero value if there is none
+func (d *dict[V]) Value(key string) V {
+	v, _ := d.Get(key)
+	return v
+}
+
+// Set sets the value of key, returning true if the key is new
+func (d *dict[V]) Set(key string, value V) bool {
+	d.rehashStep()
+	if e := d.find(key); e != nil {
+		e.value = value
+		return false
+	}
+	t := d.tables[0]
+	if d.isRehashing() {
+		t = d.tables[1]
+	}
+	i := d.hash(key) & t.mask()
+	t.buckets[i] = &dictEntry[V]{key: key, value: value, next: t.buckets[i]}
+	t.used++
+	d.resize()
+	return true
+}
+
+// Delete deletes key, returning true if it existed
+func (d *dict[V]) Delete(key string) bool {
+	d.rehashStep()
+	h := d.hash(key)
+	for _, t := range d.tables {
+		if t == nil {
+			break
+		}
+		for p := &t.buckets[h&t.mask()]; *p != nil; p = &(*p).next {
+			if (*p).key == key {
+				*p = (*p).next
+				t.used--
+				d.resize()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clear drops every entry, leaving the dict empty. It takes time proportional to the number of buckets.
+func (d *dict[V]) Clear() {
+	for _, t := range d.tables {
+		if t != nil {
+			clear(t.buckets)
+			t.used = 0
+		}
+	}
+	d.tables[1] = nil
+	d.rehashIdx = 0
+}
+
+// resize starts rehashing into a table twice or half the size if the dict has outgrown its table or shrunk well below it
+func (d *dict[V]) resize() {
+	if d.isRehashing() {
+		return
+	}
+	n, size := d.Len(), len(d.tables[0].buckets)
+	switch {
+	case n >= size:
+		d.tables[1] = newDictTable[V](2 * size)
+	case size > dictMinSize && n < size/8:
+		d.tables[1] = newDictTable[V](n)
+	default:
+		return
+	}
+	d.rehashIdx = 0
+}
+
+// rehashStep moves the next non-empty bucket of tables[0] over to tables[1], finishing the rehash once tables[0] is empty
+func (d *dict[V]) rehashStep() {
+	if !d.isRehashing() {
+		return
+	}
+	src, dst := d.tables[0], d.tables[1]
+	for visits := 0; src.used > 0 && src.buckets[d.rehashIdx] == nil; visits++ {
+		if visits == dictRehashEmptyVisits {
+			return
+		}
+		d.rehashIdx++
+	}
+	if src.used > 0 {
+		for e := src.buckets[d.rehashIdx]; e != nil; {
+			next := e.next
+			i := d.hash(e.key) & dst.mask()
+			e.next = dst.buckets[i]
+			dst.buckets[i] = e
+			src.used--
+			dst.used++
+			e = next
+		}
+		src.buckets[d.rehashIdx] = nil
+		d.rehashIdx++
+	}
+	if src.used == 0 {
+		d.tables = [2]*dictTable[V]{dst, nil}
+		d.rehashIdx = 0
+	}
+}
+
+// Each calls f with each entry until it returns false. f must not modify the dict.
+func (d *dict[V]) Each(f func(key string, value V) bool) {
+	for _, t := range d.tables {
+		if t == nil {
+			break
+		}
+		for _, e := range t.buckets {
+			for ; e != nil; e = e.next {
+				if !f(e.key, e.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clone returns a copy of the dict, or nil if d is nil
+func (d *dict[V]) Clone() *dict[V] {
+	if d == nil {
+		return nil
+	}
+	c := newDict[V](d.Len())
+	d.Each(func(key string, value V) bool {
+		c.Set(key, value)
+		return true
+	})
+	return c
+}
+
+// Keys returns every key
+func (d *dict[V]) Keys() []string {
+	keys := make([]string, 0, d.Len())
+	d.Each(func(key string, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Random returns a random entry, or false if the dict is empty. Entries in shorter chains are somewhat more likely to be returned.
+func (d *dict[V]) Random() (string, V, bool) {
+	var zero V
+	if d.Len() == 0 {
+		return "", zero, false
+	}
+	var chain []*dictEntry[V]
+	for len(chain) == 0 {
+		t := d.tables[0]
+		if d.isRehashing() && rand.Intn(d.Len()) >= t.used {
+			t = d.tables[1]
+		}
+		if t.used == 0 {
+			continue
+		}
+		for e := t.buckets[rand.Intn(len(t.buckets))]; e != nil; e = e.next {
+			chain = append(chain, e)
+		}
+	}
+	e := chain[rand.Intn(len(chain))]
+	return e.key, e.value, true
+}
+
+// Scan calls f with the entries of the bucket at cursor, and returns the cursor of the next bucket, or 0 once every bucket has been visited.
+// A scan starting from cursor 0 visits every entry present for the whole of it at least once, even if the dict is resized in between calls.
+// This is achieved by incrementing the cursor from its high bits down, so that the buckets of a smaller table that have been visited
+// map to buckets of a larger one that have been visited too, and vice versa; some entries may be visited more than once as a result.
+// While rehashing, the bucket of the smaller table is visited along with every bucket of the larger table that it expands to.
+// f must not modify the dict.
+func (d *dict[V]) Scan(cursor uint64, f func(key string, value V)) uint64 {
+	if d.Len() == 0 {
+		return 0
+	}
+	visit := func(t *dictTable[V], i uint64) {
+		for e := t.buckets[i]; e != nil; e = e.next {
+			f(e.key, e.value)
+		}
+	}
+	small, large := d.tables[0], d.tables[1]
+	if large == nil {
+		visit(small, cursor&small.mask())
+		return nextDictCursor(cursor, small.mask())
+	}
+	if len(small.buckets) > len(large.buckets) {
+		small, large = large, small
+	}
+	visit(small, cursor&small.mask())
+	for {
+		visit(large, cursor&large.mask())
+		cursor = nextDictCursor(cursor, large.mask())
+		if cursor&(small.mask()^large.mask()) == 0 {
+			return cursor
+		}
+	}
+}
+
+// nextDictCursor increments the bits of cursor covered by mask in reverse order
+func nextDictCursor(cursor, mask uint64) uint64 {
+	cursor |= ^mask
+	cursor = bits.Reverse64(cursor)
+	cursor++
+	return bits.Reverse64(cursor)
+}
@@ -43,5 +43,5 @@ func initializeDbAsMaster() {
 		return
 	}
 	log.Println("No RDB file specified or file does not exist, initializing empty db state")
-	state.Db = make(map[string]DbValue)
+	UnsafeResetDbWithSizeHint(0)
 }
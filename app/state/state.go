@@ -35,10 +35,15 @@ type State struct {
 	// WARNING: it is an error to directly manipulate DbMu and PropagateMu; use the provided functions instead
 
 	// Database state
-	Db Db `json:"-"`
+	Db *dict[DbValue] `json:"-"`
+	// SortedKeys holds the keys of Db in lexicographic order as members of a sorted set with scores of 0, so that KEYS can find the keys
+	// starting with a literal prefix without walking every key. It is kept in sync by unsafeSet and unsafeDelete.
+	SortedKeys *DbZSet `json:"-"`
 	// While a thread holds the lock, no other thread is expected to mutate the database; and the thread itself may not mutate the database
 	// if it acquired a read lock
 	DbMu sync.RWMutex `json:"-"`
+	// Search indexes by name, which are guarded by DbMu along with the database they index
+	Indexes map[string]*searchIndex `json:"-"`
 
 	// Replication state
 	// While a thread holds the lock, no other thread is expected to mutate the database in a way that would require propagation, or to mutate the replication stream, or to add replicas (replicas may be removed, and mutations to the database may be made if they do not require propagation)
@@ -133,17 +138,18 @@ var (
 	ErrorWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
 )
 
-type Db = map[string]DbValue
-
+// DbValue is a value stored at a key. Every value may expire, through the dbExpiry it embeds.
 type DbValue interface {
 	Type() string
-}
-
-type DefinitelyExpirer interface {
+	ExpiresAt() time.Time
 	IsDefinitelyExpiredAt(t time.Time) bool
+	isExpiredAt(t time.Time) bool
+	setExpiresAt(t time.Time)
 }
 
-type DbNone struct{}
+type DbNone struct {
+	dbExpiry
+}
 
 var _ DbValue = (*DbNone)(nil)
 
@@ -182,19 +188,35 @@ func Initialize() {
 
 // Initialization and replication operations
 
+// UnsafeSet sets key to a string value, replacing any value there as with unsafeSet. The caller must hold DbMu.
 func UnsafeSet(key, value string, expiresAt time.Time) {
-	state.Db[key] = &DbString{string: value, expiresAt: expiresAt}
+	unsafeSet(key, &DbString{dbExpiry: dbExpiry{expiresAt: expiresAt}, string: value})
 }
 
 func UnsafeResetDbWithSizeHint(sizeHint int64) {
-	state.Db = make(map[string]DbValue, sizeHint)
+	state.Db = newDict[DbValue](int(sizeHint))
+	state.SortedKeys = NewDbZSet()
+	state.Indexes = nil
 }
 
 // General operations
 
+// unsafeLookup returns the value stored at key, treating values that have logically expired at now as absent.
+// Such values are left in place for the eviction paths to reclaim. The caller must hold DbMu.
+func unsafeLookup(key string, now time.Time) (DbValue, bool) {
+	v, ok := state.Db.Get(key)
+	if !ok {
+		return nil, false
+	}
+	if v.isExpiredAt(now) {
+		return nil, false
+	}
+	return v, true
+}
+
 func Type(key string) string {
 	RLockDbMu()
-	v, ok := state.Db[key]
+	v, ok := unsafeLookup(key, time.Now())
 	RUnlockDbMu()
 	if !ok {
 		return NoneValue.Type()
@@ -202,17 +224,32 @@ func Type(key string) string {
 	return v.Type()
 }
 
-func Keys() []string {
-	keys := make([]string, 0, len(state.Db)+len(state.Db)/10)
+// Keys returns the keys matching the glob-style pattern. Only the keys starting with the literal prefix of the pattern are looked at,
+// and a pattern that is entirely literal is looked up directly.
+func Keys(pattern string) []string {
 	now := time.Now()
 	RLockDbMu()
 	defer RUnlockDbMu()
-	for k, v := range state.Db {
-		if w, ok := v.(DefinitelyExpirer); ok && w.IsDefinitelyExpiredAt(now) {
+	prefix, literal := utility.GlobPrefix(pattern)
+	if literal {
+		if _, ok := unsafeLookup(prefix, now); ok {
+			return []string{prefix}
+		}
+		return []string{}
+	}
+	keys := []string{}
+	add := func(k string, v DbValue) bool {
+		if v.IsDefinitelyExpiredAt(now) {
 			go TryEvictExpiredKey(k)
-			continue
+		} else if !v.isExpiredAt(now) && utility.GlobMatch(pattern, k) {
+			keys = append(keys, k)
 		}
-		keys = append(keys, k)
+		return true
+	}
+	if prefix == "" {
+		state.Db.Each(add)
+	} else {
+		unsafeEachKeyWithPrefix(prefix, add)
 	}
 	return keys
 }
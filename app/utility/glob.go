@@ -0,0 +1,98 @@
+package utility
+
+// GlobMatch reports whether s matches the glob-style pattern, in which * matches any sequence of bytes, ? matches any byte,
+// [...] matches any byte in a set of bytes and ranges such as a-z, or not in it if it starts with ^, and \ escapes the byte following it.
+// On a mismatch, the last * seen is retried against one more byte of s, which is enough since each * may absorb anything before the next.
+func GlobMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	starPx, starSx := -1, 0
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				starPx, starSx = px, sx
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			case '[':
+				matched, n := globMatchClass(pattern[px+1:], s, sx)
+				if matched {
+					px += 1 + n
+					sx++
+					continue
+				}
+			default:
+				n := 1
+				if c == '\\' && px+1 < len(pattern) {
+					c, n = pattern[px+1], 2
+				}
+				if sx < len(s) && s[sx] == c {
+					px += n
+					sx++
+					continue
+				}
+			}
+		}
+		if starPx < 0 || starSx >= len(s) {
+			return false
+		}
+		starSx++
+		px, sx = starPx+1, starSx
+	}
+	return true
+}
+
+// globMatchClass reports whether s[sx] matches the set of bytes at the start of class, which follows a [,
+// and returns the length of the set including the closing ]
+func globMatchClass(class, s string, sx int) (bool, int) {
+	i := 0
+	negate := i < len(class) && class[i] == '^'
+	if negate {
+		i++
+	}
+	matched := false
+	for i < len(class) && class[i] != ']' {
+		switch {
+		case class[i] == '\\' && i+1 < len(class):
+			matched = matched || sx < len(s) && s[sx] == class[i+1]
+			i += 2
+		case i+2 < len(class) && class[i+1] == '-':
+			lo, hi := min(class[i], class[i+2]), max(class[i], class[i+2])
+			matched = matched || sx < len(s) && s[sx] >= lo && s[sx] <= hi
+			i += 3
+		default:
+			matched = matched || sx < len(s) && s[sx] == class[i]
+			i++
+		}
+	}
+	if i < len(class) {
+		i++
+	}
+	return sx < len(s) && matched != negate, i
+}
+
+// GlobPrefix returns the literal prefix that every string matching pattern starts with, with escapes resolved,
+// and whether the whole pattern is literal, in which case only the prefix itself matches it
+func GlobPrefix(pattern string) (string, bool) {
+	prefix := make([]byte, 0, len(pattern))
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*', '?', '[':
+			return string(prefix), false
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				c = pattern[i]
+			}
+			prefix = append(prefix, c)
+		default:
+			prefix = append(prefix, c)
+		}
+	}
+	return string(prefix), true
+}